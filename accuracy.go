@@ -0,0 +1,225 @@
+package osu
+
+import (
+	"math"
+)
+
+// Hits holds the judgement counts of a score. What each count means depends on the mode:
+// in taiko Countgeki and Countkatu count big notes, in catch Count50 and Countkatu count hit and missed tiny droplets,
+// and in mania Countgeki and Countkatu count MAXes and 200s
+type Hits struct {
+	Count300  int64
+	Count100  int64
+	Count50   int64
+	Countmiss int64
+	Countgeki int64
+	Countkatu int64
+}
+
+// TotalHits returns the number of judgements that count towards accuracy in the given mode
+func (h Hits) TotalHits(m Mode) int64 {
+	switch m {
+	case ModeTaiko:
+		return h.Count300 + h.Count100 + h.Countmiss
+	case ModeCtb:
+		return h.Count300 + h.Count100 + h.Count50 + h.Countkatu + h.Countmiss
+	case ModeMania:
+		return h.Countgeki + h.Count300 + h.Countkatu + h.Count100 + h.Count50 + h.Countmiss
+	}
+	return h.Count300 + h.Count100 + h.Count50 + h.Countmiss
+}
+
+// Accuracy returns the accuracy of the hits in the given mode, from 0 to 1
+func (h Hits) Accuracy(m Mode) float64 {
+	total := float64(h.TotalHits(m))
+	if total == 0 {
+		return 0
+	}
+	switch m {
+	case ModeTaiko:
+		return (float64(h.Count300) + float64(h.Count100)/2) / total
+	case ModeCtb:
+		return float64(h.Count300+h.Count100+h.Count50) / total
+	case ModeMania:
+		return float64(300*(h.Countgeki+h.Count300)+200*h.Countkatu+100*h.Count100+50*h.Count50) / (300 * total)
+	}
+	return float64(300*h.Count300+100*h.Count100+50*h.Count50) / (300 * total)
+}
+
+// Grade returns the rank the hits are given in the given mode, with silver ranks for Hidden and Flashlight (and FadeIn in mania).
+// It never returns RankF, as a failed play can't be told apart from its hits
+func (h Hits) Grade(m Mode, mods Mods) Rank {
+	var r Rank
+	acc := h.Accuracy(m)
+	switch m {
+	case ModeOsu, ModeTaiko:
+		total := float64(h.TotalHits(m))
+		if total == 0 {
+			return RankD
+		}
+		ratio300 := float64(h.Count300) / total
+		ratio50 := float64(h.Count50) / total
+		switch {
+		case acc == 1:
+			r = RankSS
+		case ratio300 > 0.9 && ratio50 < 0.01 && h.Countmiss == 0:
+			r = RankS
+		case ratio300 > 0.8 && h.Countmiss == 0 || ratio300 > 0.9:
+			r = RankA
+		case ratio300 > 0.7 && h.Countmiss == 0 || ratio300 > 0.8:
+			r = RankB
+		case ratio300 > 0.6:
+			r = RankC
+		default:
+			r = RankD
+		}
+	case ModeCtb:
+		r = gradeByAccuracy(acc, 0.98, 0.94, 0.9, 0.85)
+	case ModeMania:
+		r = gradeByAccuracy(acc, 0.95, 0.9, 0.8, 0.7)
+	}
+	silver := mods.HasAny(Hidden | Flashlight)
+	if m == ModeMania {
+		silver = silver || mods.Has(FadeIn)
+	}
+	if silver && r == RankSS {
+		return RankSSH
+	} else if silver && r == RankS {
+		return RankSH
+	}
+	return r
+}
+
+func gradeByAccuracy(acc, s, a, b, c float64) Rank {
+	switch {
+	case acc == 1:
+		return RankSS
+	case acc > s:
+		return RankS
+	case acc > a:
+		return RankA
+	case acc > b:
+		return RankB
+	case acc > c:
+		return RankC
+	}
+	return RankD
+}
+
+// HitsForAccuracy returns plausible hits for a play of a beatmap with the given number of objects (judgements in catch and mania)
+// that reaches accuracy, from 0 to 1, with the given number of misses. Accuracy that can't be reached is clamped
+func HitsForAccuracy(m Mode, accuracy float64, objects, misses int64) Hits {
+	misses = clampInt(misses, 0, objects)
+	remaining := objects - misses
+	accuracy = math.Max(0, math.Min(accuracy, 1))
+	h := Hits{Countmiss: misses}
+	switch m {
+	case ModeTaiko:
+		h.Count100 = clampInt(int64(math.Round(2*((1-accuracy)*float64(objects)-float64(misses)))), 0, remaining)
+		h.Count300 = remaining - h.Count100
+	case ModeCtb:
+		h.Count300 = clampInt(int64(math.Round(accuracy*float64(objects))), 0, remaining)
+		h.Countkatu = remaining - h.Count300
+	default:
+		// each 100 loses 200 points of 300 and each 50 loses 250, beyond what the misses already lost
+		deficit := math.Max(0, (1-accuracy)*float64(objects)*300-float64(misses)*300)
+		h.Count100 = int64(math.Round(deficit / 200))
+		if h.Count100 > remaining {
+			h.Count50 = clampInt(int64(math.Round((deficit-200*float64(remaining))/50)), 0, remaining)
+			h.Count100 = remaining - h.Count50
+		}
+		h.Count300 = remaining - h.Count100 - h.Count50
+	}
+	return h
+}
+
+func clampInt(n, min, max int64) int64 {
+	if n < min {
+		return min
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
+
+// Hits returns the judgement counts of the score
+func (s *Score) Hits() Hits {
+	return Hits{s.Count300, s.Count100, s.Count50, s.Countmiss, s.Countgeki, s.Countkatu}
+}
+
+// Accuracy returns the accuracy of the score in the given mode, from 0 to 1
+func (s *Score) Accuracy(m Mode) float64 {
+	return s.Hits().Accuracy(m)
+}
+
+// Grade calculates the rank the score is given in the given mode
+func (s *Score) Grade(m Mode) Rank {
+	return s.Hits().Grade(m, s.EnabledMods)
+}
+
+// TotalHits returns the number of judgements in the score that count towards accuracy in the given mode
+func (s *Score) TotalHits(m Mode) int64 {
+	return s.Hits().TotalHits(m)
+}
+
+// Hits returns the judgement counts of the score
+func (s *BestScore) Hits() Hits {
+	return Hits{s.Count300, s.Count100, s.Count50, s.Countmiss, s.Countgeki, s.Countkatu}
+}
+
+// Accuracy returns the accuracy of the score in the given mode, from 0 to 1
+func (s *BestScore) Accuracy(m Mode) float64 {
+	return s.Hits().Accuracy(m)
+}
+
+// Grade calculates the rank the score is given in the given mode
+func (s *BestScore) Grade(m Mode) Rank {
+	return s.Hits().Grade(m, s.EnabledMods)
+}
+
+// TotalHits returns the number of judgements in the score that count towards accuracy in the given mode
+func (s *BestScore) TotalHits(m Mode) int64 {
+	return s.Hits().TotalHits(m)
+}
+
+// Hits returns the judgement counts of the score
+func (s *RecentScore) Hits() Hits {
+	return Hits{s.Count300, s.Count100, s.Count50, s.Countmiss, s.Countgeki, s.Countkatu}
+}
+
+// Accuracy returns the accuracy of the score in the given mode, from 0 to 1
+func (s *RecentScore) Accuracy(m Mode) float64 {
+	return s.Hits().Accuracy(m)
+}
+
+// Grade calculates the rank the score is given in the given mode. Failed plays are graded as if they were passed
+func (s *RecentScore) Grade(m Mode) Rank {
+	return s.Hits().Grade(m, s.EnabledMods)
+}
+
+// TotalHits returns the number of judgements in the score that count towards accuracy in the given mode
+func (s *RecentScore) TotalHits(m Mode) int64 {
+	return s.Hits().TotalHits(m)
+}
+
+// Hits returns the judgement counts of the score
+func (s *MatchScore) Hits() Hits {
+	return Hits{s.Count300, s.Count100, s.Count50, s.Countmiss, s.Countgeki, s.Countkatu}
+}
+
+// Accuracy returns the accuracy of the score in the given mode (MatchGame.PlayMode), from 0 to 1
+func (s *MatchScore) Accuracy(m Mode) float64 {
+	return s.Hits().Accuracy(m)
+}
+
+// Grade calculates the rank the score is given in the given mode (MatchGame.PlayMode).
+// gameMods are the mods of the game (MatchGame.Mods), which are combined with the player's own in free mod games
+func (s *MatchScore) Grade(m Mode, gameMods Mods) Rank {
+	return s.Hits().Grade(m, gameMods|s.EnabledMods)
+}
+
+// TotalHits returns the number of judgements in the score that count towards accuracy in the given mode (MatchGame.PlayMode)
+func (s *MatchScore) TotalHits(m Mode) int64 {
+	return s.Hits().TotalHits(m)
+}
@@ -0,0 +1,138 @@
+package osu
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHitsAccuracy(t *testing.T) {
+	tests := []struct {
+		mode  Mode
+		hits  Hits
+		total int64
+		want  float64
+	}{
+		{ModeOsu, Hits{Count300: 100}, 100, 1},
+		{ModeOsu, Hits{Count300: 90, Count100: 10}, 100, 0.93333},
+		{ModeOsu, Hits{Count300: 90, Count100: 5, Count50: 3, Countmiss: 2}, 100, 0.92167},
+		// gekis and katus are already counted as 300s and 100s
+		{ModeOsu, Hits{Count300: 90, Count100: 10, Countgeki: 30, Countkatu: 5}, 100, 0.93333},
+		{ModeTaiko, Hits{Count300: 90, Count100: 10}, 100, 0.95},
+		{ModeTaiko, Hits{Count300: 90, Count100: 8, Countmiss: 2}, 100, 0.94},
+		{ModeCtb, Hits{Count300: 90, Count100: 5, Count50: 3, Countkatu: 2}, 100, 0.98},
+		{ModeCtb, Hits{Count300: 90, Count100: 5, Count50: 3, Countmiss: 2}, 100, 0.98},
+		{ModeMania, Hits{Countgeki: 50, Count300: 40, Countkatu: 10}, 100, 0.96667},
+		{ModeMania, Hits{Countgeki: 50, Count300: 40, Count100: 5, Count50: 3, Countmiss: 2}, 100, 0.92167},
+		{ModeOsu, Hits{}, 0, 0},
+		{ModeMania, Hits{}, 0, 0},
+	}
+	for _, tt := range tests {
+		if total := tt.hits.TotalHits(tt.mode); total != tt.total {
+			t.Errorf("%v %+v: got %d total hits, want %d", tt.mode, tt.hits, total, tt.total)
+		}
+		if acc := tt.hits.Accuracy(tt.mode); math.Abs(acc-tt.want) > 0.00001 {
+			t.Errorf("%v %+v: got accuracy %.5f, want %.5f", tt.mode, tt.hits, acc, tt.want)
+		}
+	}
+}
+
+func TestHitsGrade(t *testing.T) {
+	tests := []struct {
+		mode Mode
+		hits Hits
+		mods Mods
+		want Rank
+	}{
+		{ModeOsu, Hits{Count300: 100}, 0, RankSS},
+		{ModeOsu, Hits{Count300: 100}, Mods(Hidden), RankSSH},
+		{ModeOsu, Hits{Count300: 100}, Mods(Flashlight), RankSSH},
+		{ModeOsu, Hits{Count300: 100}, Mods(FadeIn), RankSS},
+		{ModeOsu, Hits{Count300: 91, Count100: 9}, 0, RankS},
+		{ModeOsu, Hits{Count300: 91, Count100: 9}, Mods(Hidden | HardRock), RankSH},
+		// S needs strictly more than 90% 300s, under 1% 50s and no misses
+		{ModeOsu, Hits{Count300: 90, Count100: 10}, 0, RankA},
+		{ModeOsu, Hits{Count300: 91, Count100: 8, Count50: 1}, 0, RankA},
+		{ModeOsu, Hits{Count300: 99, Countmiss: 1}, 0, RankA},
+		{ModeOsu, Hits{Count300: 99, Countmiss: 1}, Mods(Hidden), RankA},
+		{ModeOsu, Hits{Count300: 81, Count100: 19}, 0, RankA},
+		{ModeOsu, Hits{Count300: 85, Count100: 14, Countmiss: 1}, 0, RankB},
+		{ModeOsu, Hits{Count300: 71, Count100: 29}, 0, RankB},
+		{ModeOsu, Hits{Count300: 75, Count100: 24, Countmiss: 1}, 0, RankC},
+		{ModeOsu, Hits{Count300: 61, Count100: 39}, 0, RankC},
+		{ModeOsu, Hits{Count300: 60, Count100: 40}, 0, RankD},
+		{ModeOsu, Hits{}, 0, RankD},
+		{ModeTaiko, Hits{Count300: 100}, Mods(Hidden), RankSSH},
+		{ModeTaiko, Hits{Count300: 91, Count100: 9}, 0, RankS},
+		{ModeTaiko, Hits{Count300: 90, Count100: 10}, 0, RankA},
+		{ModeCtb, Hits{Count300: 100}, 0, RankSS},
+		{ModeCtb, Hits{Count300: 99, Countkatu: 1}, Mods(Flashlight), RankSH},
+		{ModeCtb, Hits{Count300: 98, Countmiss: 2}, 0, RankA},
+		{ModeCtb, Hits{Count300: 94, Countmiss: 6}, 0, RankB},
+		{ModeCtb, Hits{Count300: 90, Countmiss: 10}, 0, RankC},
+		{ModeCtb, Hits{Count300: 85, Countmiss: 15}, 0, RankD},
+		{ModeMania, Hits{Countgeki: 60, Count300: 40}, 0, RankSS},
+		{ModeMania, Hits{Countgeki: 60, Count300: 40}, Mods(FadeIn), RankSSH},
+		{ModeMania, Hits{Countgeki: 50, Count300: 40, Countkatu: 10}, 0, RankS},
+		{ModeMania, Hits{Countgeki: 50, Count300: 40, Countkatu: 10}, Mods(Hidden), RankSH},
+		// exactly 95% is an A
+		{ModeMania, Hits{Count300: 185, Count100: 15}, 0, RankA},
+		{ModeMania, Hits{Count300: 85, Count100: 15}, 0, RankB},
+		{ModeMania, Hits{Count300: 70, Count100: 30}, 0, RankC},
+		{ModeMania, Hits{Count300: 55, Count100: 45}, 0, RankD},
+	}
+	for _, tt := range tests {
+		if got := tt.hits.Grade(tt.mode, tt.mods); got != tt.want {
+			t.Errorf("%v %+v %s: got %v, want %v", tt.mode, tt.hits, tt.mods.Acronyms(), got, tt.want)
+		}
+	}
+}
+
+func TestHitsForAccuracy(t *testing.T) {
+	tests := []struct {
+		mode     Mode
+		accuracy float64
+		objects  int64
+		misses   int64
+		want     Hits
+	}{
+		{ModeOsu, 1, 1000, 0, Hits{Count300: 1000}},
+		{ModeOsu, 0.99, 1000, 2, Hits{Count300: 986, Count100: 12, Countmiss: 2}},
+		{ModeOsu, 1, 1000, 2000, Hits{Countmiss: 1000}},
+		// can't go below all 50s
+		{ModeOsu, 0, 1000, 0, Hits{Count50: 1000}},
+		{ModeTaiko, 0.99, 1000, 2, Hits{Count300: 982, Count100: 16, Countmiss: 2}},
+		{ModeCtb, 0.99, 1000, 2, Hits{Count300: 990, Countkatu: 8, Countmiss: 2}},
+		{ModeMania, 0.99, 1000, 2, Hits{Count300: 986, Count100: 12, Countmiss: 2}},
+	}
+	for _, tt := range tests {
+		got := HitsForAccuracy(tt.mode, tt.accuracy, tt.objects, tt.misses)
+		if got != tt.want {
+			t.Errorf("%v %v/%d/%d: got %+v, want %+v", tt.mode, tt.accuracy, tt.objects, tt.misses, got, tt.want)
+		}
+	}
+	for _, m := range []Mode{ModeOsu, ModeTaiko, ModeCtb, ModeMania} {
+		for _, acc := range []float64{0.99, 0.98, 0.95, 0.9, 0.8} {
+			h := HitsForAccuracy(m, acc, 1000, 3)
+			if h.TotalHits(m) != 1000 {
+				t.Errorf("%v %v: got %d total hits", m, acc, h.TotalHits(m))
+			}
+			if got := h.Accuracy(m); math.Abs(got-acc) > 0.001 {
+				t.Errorf("%v %v: got %+v with accuracy %v", m, acc, h, got)
+			}
+		}
+	}
+}
+
+func TestScoreGrade(t *testing.T) {
+	s := Score{Count300: 91, Count100: 9, EnabledMods: Mods(Hidden)}
+	if g := s.Grade(ModeOsu); g != RankSH {
+		t.Errorf("Score.Grade: got %v, want %v", g, RankSH)
+	}
+	ms := MatchScore{Count300: 100}
+	if g := ms.Grade(ModeOsu, Mods(Flashlight)); g != RankSSH {
+		t.Errorf("MatchScore.Grade: got %v, want %v", g, RankSSH)
+	}
+	if g := ms.Grade(ModeOsu, 0); g != RankSS {
+		t.Errorf("MatchScore.Grade: got %v, want %v", g, RankSS)
+	}
+}
@@ -0,0 +1,294 @@
+// Package beatmap reads and writes osu! beatmap (.osu) files
+package beatmap
+
+import (
+	"github.com/pixelrazor/osu"
+)
+
+// Beatmap holds the contents of a .osu file
+type Beatmap struct {
+	// Version is the file format version, from the "osu file format vN" header
+	Version      int
+	General      General
+	Editor       Editor
+	Metadata     Metadata
+	Difficulty   Difficulty
+	Events       Events
+	TimingPoints []TimingPoint
+	Colours      Colours
+	HitObjects   []HitObject
+
+	// doc is set by Parse
+	doc *document
+}
+
+// General holds the [General] section
+type General struct {
+	AudioFilename            string
+	AudioLeadIn              int
+	AudioHash                string
+	PreviewTime              int
+	Countdown                int
+	SampleSet                string
+	StackLeniency            float64
+	Mode                     osu.Mode
+	LetterboxInBreaks        bool
+	StoryFireInFront         bool
+	UseSkinSprites           bool
+	AlwaysShowPlayfield      bool
+	OverlayPosition          string
+	SkinPreference           string
+	EpilepsyWarning          bool
+	CountdownOffset          int
+	SpecialStyle             bool
+	WidescreenStoryboard     bool
+	SamplesMatchPlaybackRate bool
+}
+
+// Editor holds the [Editor] section
+type Editor struct {
+	Bookmarks       []int
+	DistanceSpacing float64
+	BeatDivisor     int
+	GridSize        int
+	TimelineZoom    float64
+}
+
+// Metadata holds the [Metadata] section
+type Metadata struct {
+	Title         string
+	TitleUnicode  string
+	Artist        string
+	ArtistUnicode string
+	Creator       string
+	Version       string
+	Source        string
+	Tags          []string
+	BeatmapID     osu.BeatmapID
+	BeatmapSetID  osu.BeatmapsetID
+}
+
+// Difficulty holds the [Difficulty] section
+type Difficulty struct {
+	HPDrainRate       float64
+	CircleSize        float64
+	OverallDifficulty float64
+	// ApproachRate is missing from old versions, where it is equal to OverallDifficulty
+	ApproachRate     float64
+	SliderMultiplier float64
+	SliderTickRate   float64
+}
+
+// Events holds the [Events] section
+type Events struct {
+	Background *Background
+	Videos     []Video
+	Breaks     []Break
+	// Storyboard holds the remaining lines of the section, which describe the storyboard
+	Storyboard []string
+}
+
+// Background is the background image of a beatmap
+type Background struct {
+	Filename string
+	// XOffset and YOffset are in osu! pixels from the centre of the screen
+	XOffset int
+	YOffset int
+}
+
+// Video is a background video of a beatmap
+type Video struct {
+	// StartTime is in milliseconds
+	StartTime int
+	Filename  string
+	XOffset   int
+	YOffset   int
+}
+
+// Break is a period without hit objects
+type Break struct {
+	// StartTime and EndTime are in milliseconds
+	StartTime int
+	EndTime   int
+}
+
+// SampleSet is a set of hitsound samples
+type SampleSet int
+
+// All sample sets. SampleSetDefault uses the sample set of the active timing point
+const (
+	SampleSetDefault SampleSet = iota
+	SampleSetNormal
+	SampleSetSoft
+	SampleSetDrum
+)
+
+// Effect is a bitwise combination of the effects of a timing point
+type Effect int
+
+// All timing point effects
+const (
+	EffectKiai             Effect = 1
+	EffectOmitFirstBarLine Effect = 8
+)
+
+// TimingPoint is a line of the [TimingPoints] section
+type TimingPoint struct {
+	// Time is in milliseconds
+	Time float64
+	// BeatLength is the length of a beat in milliseconds for uninherited points,
+	// or a negative inverse slider velocity multiplier as a percentage for inherited points
+	BeatLength  float64
+	Meter       int
+	SampleSet   SampleSet
+	SampleIndex int
+	Volume      int
+	Uninherited bool
+	Effects     Effect
+}
+
+// BPM returns the tempo set by an uninherited timing point
+func (tp TimingPoint) BPM() float64 {
+	return 60000 / tp.BeatLength
+}
+
+// SliderVelocity returns the slider velocity multiplier set by an inherited timing point, or 1 for uninherited points
+func (tp TimingPoint) SliderVelocity() float64 {
+	if tp.Uninherited || tp.BeatLength >= 0 {
+		return 1
+	}
+	return -100 / tp.BeatLength
+}
+
+// Kiai reports whether kiai time is enabled by the timing point
+func (tp TimingPoint) Kiai() bool {
+	return tp.Effects&EffectKiai != 0
+}
+
+// Colour is an RGB colour
+type Colour struct {
+	R, G, B uint8
+}
+
+// Colours holds the [Colours] section
+type Colours struct {
+	// Combo holds the combo colours in the order they are listed
+	Combo               []Colour
+	SliderTrackOverride *Colour
+	SliderBorder        *Colour
+}
+
+// HitObjectType is the bitwise type of a hit object
+type HitObjectType int
+
+// All hit object type bits
+const (
+	TypeCircle   HitObjectType = 1
+	TypeSlider   HitObjectType = 2
+	TypeNewCombo HitObjectType = 4
+	TypeSpinner  HitObjectType = 8
+	// TypeComboSkip holds the number of combo colours to skip at a new combo
+	TypeComboSkip HitObjectType = 16 | 32 | 64
+	TypeHold      HitObjectType = 128
+)
+
+// HitSound is a bitwise combination of the additional sounds played on a hit
+type HitSound int
+
+// All hitsounds. HitSoundNormal is played on every hit, even when not set
+const (
+	HitSoundNormal  HitSound = 1
+	HitSoundWhistle HitSound = 2
+	HitSoundFinish  HitSound = 4
+	HitSoundClap    HitSound = 8
+)
+
+// HitSample holds the sample settings of a hit object
+type HitSample struct {
+	NormalSet   SampleSet
+	AdditionSet SampleSet
+	Index       int
+	Volume      int
+	Filename    string
+}
+
+// Point is a position on the playfield in osu! pixels
+type Point struct {
+	X, Y int
+}
+
+// CurveType is the kind of curve a slider follows
+type CurveType byte
+
+// All curve types
+const (
+	CurveBezier      CurveType = 'B'
+	CurveCatmull     CurveType = 'C'
+	CurveLinear      CurveType = 'L'
+	CurvePerfect     CurveType = 'P'
+	CurveUnspecified CurveType = 0
+)
+
+// Slider holds the parameters specific to sliders
+type Slider struct {
+	CurveType CurveType
+	// CurvePoints holds the control points of the curve, excluding the slider's starting position
+	CurvePoints []Point
+	// Slides is the number of times the slider is travelled, 1 meaning no repeats
+	Slides int
+	// Length is the visual length in osu! pixels
+	Length float64
+	// EdgeSounds and EdgeSets hold the hitsounds of each of the slider's Slides+1 edges
+	EdgeSounds []HitSound
+	EdgeSets   []EdgeSet
+}
+
+// EdgeSet holds the sample sets of a slider edge
+type EdgeSet struct {
+	NormalSet   SampleSet
+	AdditionSet SampleSet
+}
+
+// HitObject is a line of the [HitObjects] section
+type HitObject struct {
+	X, Y int
+	// Time is in milliseconds
+	Time     int
+	Type     HitObjectType
+	HitSound HitSound
+	// Slider is set for sliders
+	Slider *Slider
+	// EndTime is set for spinners and mania hold notes
+	EndTime int
+	Sample  HitSample
+}
+
+// IsCircle reports whether the object is a hit circle
+func (h *HitObject) IsCircle() bool {
+	return h.Type&TypeCircle != 0
+}
+
+// IsSlider reports whether the object is a slider
+func (h *HitObject) IsSlider() bool {
+	return h.Type&TypeSlider != 0
+}
+
+// IsSpinner reports whether the object is a spinner
+func (h *HitObject) IsSpinner() bool {
+	return h.Type&TypeSpinner != 0
+}
+
+// IsHold reports whether the object is a mania hold note
+func (h *HitObject) IsHold() bool {
+	return h.Type&TypeHold != 0
+}
+
+// NewCombo reports whether the object starts a new combo
+func (h *HitObject) NewCombo() bool {
+	return h.Type&TypeNewCombo != 0
+}
+
+// ComboSkip returns how many combo colours are skipped when the object starts a new combo
+func (h *HitObject) ComboSkip() int {
+	return int(h.Type&TypeComboSkip) >> 4
+}
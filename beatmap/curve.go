@@ -0,0 +1,318 @@
+package beatmap
+
+import "math"
+
+// Vector is a position on the playfield in osu! pixels with fractional coordinates
+type Vector struct {
+	X, Y float64
+}
+
+func (v Vector) add(o Vector) Vector {
+	return Vector{v.X + o.X, v.Y + o.Y}
+}
+
+func (v Vector) sub(o Vector) Vector {
+	return Vector{v.X - o.X, v.Y - o.Y}
+}
+
+func (v Vector) scale(f float64) Vector {
+	return Vector{v.X * f, v.Y * f}
+}
+
+func (v Vector) dot(o Vector) float64 {
+	return v.X*o.X + v.Y*o.Y
+}
+
+func (v Vector) lengthSquared() float64 {
+	return v.dot(v)
+}
+
+func (v Vector) length() float64 {
+	return math.Sqrt(v.lengthSquared())
+}
+
+func (p Point) vector() Vector {
+	return Vector{float64(p.X), float64(p.Y)}
+}
+
+// Tolerances and detail of the curve approximations, matching osu!
+const (
+	bezierTolerance      = 0.25
+	circularArcTolerance = 0.1
+	catmullDetail        = 50
+)
+
+// SliderPath is the path a slider follows, approximated by line segments
+type SliderPath struct {
+	points []Vector
+	// lengths holds the distance along the path to each point
+	lengths []float64
+}
+
+// Path computes the path of a slider, or returns nil for other objects. Like osu!, the path is cut short or its
+// last segment extended so that it is as long as the slider's length, unless the length is unset
+func (h *HitObject) Path() *SliderPath {
+	if h.Slider == nil {
+		return nil
+	}
+	control := make([]Vector, 0, len(h.Slider.CurvePoints)+1)
+	control = append(control, Point{h.X, h.Y}.vector())
+	for _, p := range h.Slider.CurvePoints {
+		control = append(control, p.vector())
+	}
+	var points []Vector
+	for _, segment := range segments(h.Slider.CurveType, control) {
+		sub := approximate(h.Slider.CurveType, segment)
+		// segments share their joining point
+		if len(points) > 0 && len(sub) > 0 && points[len(points)-1] == sub[0] {
+			sub = sub[1:]
+		}
+		points = append(points, sub...)
+	}
+	p := &SliderPath{points: points}
+	p.measure(h.Slider.Length)
+	return p
+}
+
+// segments splits control points into the curves they describe. Bezier sliders start a new curve at every repeated
+// point, the red anchors of the editor
+func segments(curve CurveType, control []Vector) [][]Vector {
+	if curve != CurveBezier && curve != CurveUnspecified {
+		return [][]Vector{control}
+	}
+	var segs [][]Vector
+	start := 0
+	for i := 1; i < len(control); i++ {
+		if control[i] == control[i-1] {
+			segs = append(segs, control[start:i])
+			start = i
+		}
+	}
+	return append(segs, control[start:])
+}
+
+// approximate turns a curve into line segments
+func approximate(curve CurveType, control []Vector) []Vector {
+	switch curve {
+	case CurveLinear:
+		return append([]Vector(nil), control...)
+	case CurveCatmull:
+		return catmull(control)
+	case CurvePerfect:
+		// perfect curves need exactly three points, osu! draws any other number as a bezier curve.
+		// Three points in a line have no circle through them and are drawn as a line
+		if len(control) == 3 {
+			if arc := circularArc(control); arc != nil {
+				return arc
+			}
+			return append([]Vector(nil), control...)
+		}
+	}
+	return bezier(control)
+}
+
+// bezier approximates a bezier curve by subdividing it until each part is flat enough
+func bezier(control []Vector) []Vector {
+	n := len(control)
+	if n == 0 {
+		return nil
+	}
+	var out []Vector
+	stack := [][]Vector{append([]Vector(nil), control...)}
+	for len(stack) > 0 {
+		parent := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if bezierFlatEnough(parent) {
+			out = append(out, bezierApproximate(parent)...)
+			continue
+		}
+		left, right := bezierSubdivide(parent)
+		stack = append(stack, right, left)
+	}
+	return append(out, control[n-1])
+}
+
+func bezierFlatEnough(control []Vector) bool {
+	for i := 1; i < len(control)-1; i++ {
+		if control[i-1].sub(control[i].scale(2)).add(control[i+1]).lengthSquared() > bezierTolerance*bezierTolerance*4 {
+			return false
+		}
+	}
+	return true
+}
+
+// bezierSubdivide splits a bezier curve in half with de Casteljau's algorithm
+func bezierSubdivide(control []Vector) (left, right []Vector) {
+	n := len(control)
+	mid := append([]Vector(nil), control...)
+	left, right = make([]Vector, n), make([]Vector, n)
+	for i := 0; i < n; i++ {
+		left[i] = mid[0]
+		right[n-i-1] = mid[n-i-1]
+		for j := 0; j < n-i-1; j++ {
+			mid[j] = mid[j].add(mid[j+1]).scale(0.5)
+		}
+	}
+	return left, right
+}
+
+// bezierApproximate returns the points of a flat enough bezier curve, leaving out its last point
+func bezierApproximate(control []Vector) []Vector {
+	n := len(control)
+	left, right := bezierSubdivide(control)
+	left = append(left, right[1:]...)
+	out := []Vector{control[0]}
+	for i := 1; i < n-1; i++ {
+		index := 2 * i
+		out = append(out, left[index-1].add(left[index].scale(2)).add(left[index+1]).scale(0.25))
+	}
+	return out
+}
+
+// catmull approximates a Catmull-Rom spline through the control points
+func catmull(control []Vector) []Vector {
+	n := len(control)
+	var out []Vector
+	for i := 0; i < n-1; i++ {
+		v1 := control[i]
+		if i > 0 {
+			v1 = control[i-1]
+		}
+		v2 := control[i]
+		v3 := v2.scale(2).sub(v1)
+		if i < n-1 {
+			v3 = control[i+1]
+		}
+		v4 := v3.scale(2).sub(v2)
+		if i < n-2 {
+			v4 = control[i+2]
+		}
+		for c := 0; c < catmullDetail; c++ {
+			out = append(out, catmullPoint(v1, v2, v3, v4, float64(c)/catmullDetail), catmullPoint(v1, v2, v3, v4, float64(c+1)/catmullDetail))
+		}
+	}
+	return out
+}
+
+func catmullPoint(v1, v2, v3, v4 Vector, t float64) Vector {
+	t2 := t * t
+	t3 := t * t2
+	at := func(a, b, c, d float64) float64 {
+		return 0.5 * (2*b + (-a+c)*t + (2*a-5*b+4*c-d)*t2 + (-a+3*b-3*c+d)*t3)
+	}
+	return Vector{at(v1.X, v2.X, v3.X, v4.X), at(v1.Y, v2.Y, v3.Y, v4.Y)}
+}
+
+// circularArc approximates the arc from the first to the last of three points through the second,
+// returning nil when the points are (almost) in a line
+func circularArc(control []Vector) []Vector {
+	a, b, c := control[0], control[1], control[2]
+	if math.Abs((b.Y-a.Y)*(c.X-a.X)-(b.X-a.X)*(c.Y-a.Y)) <= 1e-3 {
+		return nil
+	}
+	d := 2 * (a.X*(b.Y-c.Y) + b.X*(c.Y-a.Y) + c.X*(a.Y-b.Y))
+	aSq, bSq, cSq := a.lengthSquared(), b.lengthSquared(), c.lengthSquared()
+	centre := Vector{
+		(aSq*(b.Y-c.Y) + bSq*(c.Y-a.Y) + cSq*(a.Y-b.Y)) / d,
+		(aSq*(c.X-b.X) + bSq*(a.X-c.X) + cSq*(b.X-a.X)) / d,
+	}
+	dA, dC := a.sub(centre), c.sub(centre)
+	radius := dA.length()
+	thetaStart := math.Atan2(dA.Y, dA.X)
+	thetaEnd := math.Atan2(dC.Y, dC.X)
+	for thetaEnd < thetaStart {
+		thetaEnd += 2 * math.Pi
+	}
+	direction := 1.0
+	thetaRange := thetaEnd - thetaStart
+	// draw the arc on the side of AC that B is on
+	if (Vector{c.Y - a.Y, a.X - c.X}).dot(b.sub(a)) < 0 {
+		direction = -1
+		thetaRange = 2*math.Pi - thetaRange
+	}
+	count := 2
+	if 2*radius > circularArcTolerance {
+		count = max(2, int(math.Ceil(thetaRange/(2*math.Acos(1-circularArcTolerance/radius)))))
+	}
+	out := make([]Vector, count)
+	for i := range out {
+		theta := thetaStart + direction*float64(i)/float64(count-1)*thetaRange
+		out[i] = centre.add(Vector{math.Cos(theta), math.Sin(theta)}.scale(radius))
+	}
+	return out
+}
+
+// measure computes the distance to each point, then fits the path to expected when it is set
+func (p *SliderPath) measure(expected float64) {
+	p.lengths = make([]float64, 0, len(p.points))
+	total := 0.0
+	for i := range p.points {
+		if i > 0 {
+			total += p.points[i].sub(p.points[i-1]).length()
+		}
+		p.lengths = append(p.lengths, total)
+	}
+	if expected <= 0 || len(p.points) < 2 || total == expected {
+		return
+	}
+	// osu! doesn't extend paths whose last two points are the same
+	last := len(p.points) - 1
+	if p.points[last] == p.points[last-1] && expected > total {
+		return
+	}
+	p.lengths = p.lengths[:last]
+	if total > expected {
+		for len(p.lengths) > 0 && p.lengths[len(p.lengths)-1] >= expected {
+			p.lengths = p.lengths[:len(p.lengths)-1]
+			p.points = p.points[:last]
+			last--
+		}
+	}
+	if last <= 0 {
+		p.points = p.points[:1]
+		p.lengths = []float64{0}
+		return
+	}
+	dir := p.points[last].sub(p.points[last-1])
+	if l := dir.length(); l > 0 {
+		dir = dir.scale(1 / l)
+	}
+	p.points[last] = p.points[last-1].add(dir.scale(expected - p.lengths[len(p.lengths)-1]))
+	p.lengths = append(p.lengths, expected)
+}
+
+// Points returns the points of the path
+func (p *SliderPath) Points() []Vector {
+	return p.points
+}
+
+// Length returns the length of the path in osu! pixels
+func (p *SliderPath) Length() float64 {
+	if len(p.lengths) == 0 {
+		return 0
+	}
+	return p.lengths[len(p.lengths)-1]
+}
+
+// PositionAt returns the position at progress along the path, from 0 at the start to 1 at the end
+func (p *SliderPath) PositionAt(progress float64) Vector {
+	if len(p.points) == 0 {
+		return Vector{}
+	}
+	d := math.Max(0, math.Min(progress, 1)) * p.Length()
+	i := 0
+	for i < len(p.lengths) && p.lengths[i] < d {
+		i++
+	}
+	if i == 0 {
+		return p.points[0]
+	}
+	if i >= len(p.points) {
+		return p.points[len(p.points)-1]
+	}
+	segment := p.lengths[i] - p.lengths[i-1]
+	if segment == 0 {
+		return p.points[i-1]
+	}
+	return p.points[i-1].add(p.points[i].sub(p.points[i-1]).scale((d - p.lengths[i-1]) / segment))
+}
@@ -0,0 +1,28 @@
+package beatmap
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPathLength(t *testing.T) {
+	tests := []struct {
+		name   string
+		curve  CurveType
+		points []Point
+		want   float64
+	}{
+		{"linear", CurveLinear, []Point{{100, 0}}, 100},
+		// three points in a line aren't a circle, osu! follows them as a line going back on itself
+		{"perfect collinear", CurvePerfect, []Point{{200, 0}, {100, 0}}, 300},
+		{"perfect semicircle", CurvePerfect, []Point{{100, 100}, {200, 0}}, 100 * math.Pi},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := HitObject{Type: TypeSlider, Slider: &Slider{CurveType: tt.curve, CurvePoints: tt.points}}
+			if got := h.Path().Length(); math.Abs(got-tt.want) > 0.5 {
+				t.Errorf("got length %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,349 @@
+package beatmap
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// sectionOrder is the order sections are written in when they are missing from the parsed file
+var sectionOrder = []string{"General", "Editor", "Metadata", "Difficulty", "Events", "TimingPoints", "Colours", "HitObjects"}
+
+// sectionLists holds the lists written as the lines of each section
+var sectionLists = map[string][]string{
+	"Events":       {"Background", "Videos", "Breaks", "Storyboard"},
+	"TimingPoints": {"TimingPoints"},
+	"Colours":      {"Combo"},
+	"HitObjects":   {"HitObjects"},
+}
+
+type lineKind int
+
+const (
+	// lineRaw is a line the beatmap doesn't model, such as a comment or unknown key, which is written back as is
+	lineRaw lineKind = iota
+	// lineKey is a known key of a key-value section
+	lineKey
+	// lineItem is an element of one of sectionLists
+	lineItem
+)
+
+// docLine is a line of a parsed file
+type docLine struct {
+	// raw is the line as read, without its '\n'
+	raw     string
+	section string
+	kind    lineKind
+	// key is the key of a lineKey or the list of a lineItem
+	key   string
+	index int
+	// canon is the value of the line as it was parsed, formatted the way Encode writes it
+	canon string
+}
+
+// document holds what Parse read, so that Encode can write unchanged values byte for byte
+type document struct {
+	lines []docLine
+	crlf  bool
+	// canon holds the formatted value of every known key after parsing, by "Section.Key"
+	canon map[string]string
+	// counts holds the length of every list after parsing
+	counts map[string]int
+}
+
+// snapshot records the values of the parsed beatmap
+func (doc *document) snapshot(b *Beatmap) {
+	lists := make(map[string][]string)
+	for _, section := range sectionOrder {
+		for _, f := range b.fields(section) {
+			doc.canon[section+"."+f.key] = f.format()
+		}
+		for _, name := range sectionLists[section] {
+			lists[name] = b.list(name)
+			doc.counts[name] = len(lists[name])
+		}
+	}
+	for i := range doc.lines {
+		dl := &doc.lines[i]
+		switch dl.kind {
+		case lineKey:
+			dl.canon = doc.canon[dl.section+"."+dl.key]
+		case lineItem:
+			dl.canon = lists[dl.key][dl.index]
+		}
+	}
+}
+
+// listLen returns the length of a list without formatting it
+func (b *Beatmap) listLen(name string) int {
+	switch name {
+	case "Combo":
+		return len(b.Colours.Combo)
+	case "Background":
+		if b.Events.Background != nil {
+			return 1
+		}
+	case "Videos":
+		return len(b.Events.Videos)
+	case "Breaks":
+		return len(b.Events.Breaks)
+	case "Storyboard":
+		return len(b.Events.Storyboard)
+	case "TimingPoints":
+		return len(b.TimingPoints)
+	case "HitObjects":
+		return len(b.HitObjects)
+	}
+	return 0
+}
+
+// list returns the lines of a list as they are written
+func (b *Beatmap) list(name string) []string {
+	lines := make([]string, b.listLen(name))
+	for i := range lines {
+		switch name {
+		case "Combo":
+			lines[i] = fmt.Sprintf("Combo%d : %v", i+1, b.Colours.Combo[i])
+		case "Background":
+			bg := b.Events.Background
+			lines[i] = fmt.Sprintf(`0,0,"%s",%d,%d`, bg.Filename, bg.XOffset, bg.YOffset)
+		case "Videos":
+			v := b.Events.Videos[i]
+			lines[i] = fmt.Sprintf(`Video,%d,"%s"`, v.StartTime, v.Filename)
+			if v.XOffset != 0 || v.YOffset != 0 {
+				lines[i] += fmt.Sprintf(",%d,%d", v.XOffset, v.YOffset)
+			}
+		case "Breaks":
+			br := b.Events.Breaks[i]
+			lines[i] = fmt.Sprintf("2,%d,%d", br.StartTime, br.EndTime)
+		case "Storyboard":
+			lines[i] = b.Events.Storyboard[i]
+		case "TimingPoints":
+			lines[i] = b.TimingPoints[i].String()
+		case "HitObjects":
+			lines[i] = b.HitObjects[i].String()
+		}
+	}
+	return lines
+}
+
+// separator returns what is written between the keys and values of a section
+func separator(section string) string {
+	switch section {
+	case "General", "Editor":
+		return ": "
+	case "Colours":
+		return " : "
+	}
+	return ":"
+}
+
+func isContent(raw string) bool {
+	s := strings.TrimSpace(raw)
+	return s != "" && !strings.HasPrefix(s, "//")
+}
+
+// WriteFile writes the beatmap to a .osu file at path
+func (b *Beatmap) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := b.Encode(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Encode writes the beatmap as a .osu file. For a parsed beatmap, every line whose value hasn't changed is written exactly as it was read,
+// along with comments, unknown sections and keys, and the original line endings.
+// Changed lines are rewritten in place, removed list elements are dropped, and new keys and elements are added to the end of their section
+func (b *Beatmap) Encode(w io.Writer) error {
+	doc := b.doc
+	if doc == nil {
+		doc = &document{}
+	}
+	eol := ""
+	if doc.crlf {
+		eol = "\r"
+	}
+	lists := make(map[string][]string)
+	for _, names := range sectionLists {
+		for _, name := range names {
+			lists[name] = b.list(name)
+		}
+	}
+	present := make(map[string]bool)
+	seen := make(map[string]bool)
+	last := make(map[string]int)
+	for i, dl := range doc.lines {
+		if dl.kind == lineKey {
+			present[dl.section+"."+dl.key] = true
+		}
+		if dl.section != "" && isContent(dl.raw) {
+			seen[dl.section] = true
+			last[dl.section] = i
+		}
+	}
+	// elements added to a list go after its last parsed element, or at the end of the section
+	lastItem := make(map[string]int)
+	for i, dl := range doc.lines {
+		if dl.kind == lineItem {
+			lastItem[dl.key] = i
+		}
+	}
+	addedItems := func(name string) []string {
+		var lines []string
+		if items := lists[name]; doc.counts[name] < len(items) {
+			for _, item := range items[doc.counts[name]:] {
+				lines = append(lines, item+eol)
+			}
+		}
+		return lines
+	}
+	// added returns the lines of a section that weren't in the parsed file
+	added := func(section string) []string {
+		var lines []string
+		for _, f := range b.fields(section) {
+			k := section + "." + f.key
+			if present[k] {
+				continue
+			}
+			v := f.format()
+			if canon, ok := doc.canon[k]; ok && v == canon || !ok && v == "" {
+				continue
+			}
+			lines = append(lines, f.key+separator(section)+v+eol)
+		}
+		for _, name := range sectionLists[section] {
+			if _, ok := lastItem[name]; !ok {
+				lines = append(lines, addedItems(name)...)
+			}
+		}
+		return lines
+	}
+
+	var out []string
+	if len(doc.lines) == 0 {
+		version := b.Version
+		if version == 0 {
+			version = 14
+		}
+		out = append(out, "osu file format v"+strconv.Itoa(version)+eol)
+	}
+	for i, dl := range doc.lines {
+		switch dl.kind {
+		case lineRaw:
+			out = append(out, dl.raw)
+		case lineKey:
+			f, _ := findField(b.fields(dl.section), dl.key)
+			switch v := f.format(); {
+			case v == dl.canon:
+				out = append(out, dl.raw)
+			case v != "":
+				out = append(out, dl.key+separator(dl.section)+v+eol)
+			}
+		case lineItem:
+			if items := lists[dl.key]; dl.index < len(items) {
+				if items[dl.index] == dl.canon {
+					out = append(out, dl.raw)
+				} else {
+					out = append(out, items[dl.index]+eol)
+				}
+			}
+		}
+		if dl.kind == lineItem && lastItem[dl.key] == i {
+			out = append(out, addedItems(dl.key)...)
+		}
+		if dl.section != "" && last[dl.section] == i {
+			out = append(out, added(dl.section)...)
+		}
+	}
+
+	// sections missing from the file go before the final newline
+	var tail []string
+	if n := len(out); len(doc.lines) == 0 || out[n-1] == "" {
+		if len(doc.lines) > 0 {
+			out = out[:n-1]
+		}
+		tail = []string{""}
+	}
+	for _, section := range sectionOrder {
+		if seen[section] {
+			continue
+		}
+		if lines := added(section); len(lines) > 0 {
+			out = append(out, eol, "["+section+"]"+eol)
+			out = append(out, lines...)
+		}
+	}
+	out = append(out, tail...)
+	_, err := io.WriteString(w, strings.Join(out, "\n"))
+	return err
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// String returns the colour as "R,G,B"
+func (c Colour) String() string {
+	return fmt.Sprintf("%d,%d,%d", c.R, c.G, c.B)
+}
+
+// String returns the timing point as a line of the [TimingPoints] section
+func (tp TimingPoint) String() string {
+	uninherited := 0
+	if tp.Uninherited {
+		uninherited = 1
+	}
+	return fmt.Sprintf("%s,%s,%d,%d,%d,%d,%d,%d", formatFloat(tp.Time), formatFloat(tp.BeatLength),
+		tp.Meter, tp.SampleSet, tp.SampleIndex, tp.Volume, uninherited, tp.Effects)
+}
+
+// String returns the sample settings as they are written at the end of a hit object
+func (hs HitSample) String() string {
+	return fmt.Sprintf("%d:%d:%d:%d:%s", hs.NormalSet, hs.AdditionSet, hs.Index, hs.Volume, hs.Filename)
+}
+
+// String returns the hit object as a line of the [HitObjects] section
+func (h *HitObject) String() string {
+	s := fmt.Sprintf("%d,%d,%d,%d,%d", h.X, h.Y, h.Time, h.Type, h.HitSound)
+	switch {
+	case h.IsSlider() && h.Slider != nil:
+		sl := h.Slider
+		curve := make([]string, 0, len(sl.CurvePoints)+1)
+		if sl.CurveType != CurveUnspecified {
+			curve = append(curve, string(rune(sl.CurveType)))
+		}
+		for _, p := range sl.CurvePoints {
+			curve = append(curve, fmt.Sprintf("%d:%d", p.X, p.Y))
+		}
+		s += "," + strings.Join(curve, "|") + "," + strconv.Itoa(sl.Slides) + "," + formatFloat(sl.Length)
+		if len(sl.EdgeSounds) == 0 && len(sl.EdgeSets) == 0 && h.Sample == (HitSample{}) {
+			return s
+		}
+		// the hit sample can only be written after the edges, so missing edges are written as defaults
+		n := max(sl.Slides+1, len(sl.EdgeSounds), len(sl.EdgeSets))
+		sounds := make([]string, n)
+		sets := make([]string, n)
+		for i := range sounds {
+			sounds[i], sets[i] = "0", "0:0"
+			if i < len(sl.EdgeSounds) {
+				sounds[i] = strconv.Itoa(int(sl.EdgeSounds[i]))
+			}
+			if i < len(sl.EdgeSets) {
+				sets[i] = fmt.Sprintf("%d:%d", sl.EdgeSets[i].NormalSet, sl.EdgeSets[i].AdditionSet)
+			}
+		}
+		return s + "," + strings.Join(sounds, "|") + "," + strings.Join(sets, "|") + "," + h.Sample.String()
+	case h.IsSpinner():
+		return s + "," + strconv.Itoa(h.EndTime) + "," + h.Sample.String()
+	case h.IsHold():
+		return s + "," + strconv.Itoa(h.EndTime) + ":" + h.Sample.String()
+	}
+	return s + "," + h.Sample.String()
+}
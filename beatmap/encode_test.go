@@ -0,0 +1,112 @@
+package beatmap
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fixtures are sample beatmaps of different format versions: v3 with LF line endings,
+// v7 with CRLF and v14 with a BOM and CRLF
+var fixtures = []string{"v3.osu", "v7.osu", "v14.osu"}
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	for _, name := range fixtures {
+		t.Run(name, func(t *testing.T) {
+			data := readFixture(t, name)
+			b, err := Parse(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := b.Encode(&buf); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), data) {
+				t.Errorf("encoded file differs from the original:\n got %q\nwant %q", buf.Bytes(), data)
+			}
+		})
+	}
+}
+
+func TestEncodeEdited(t *testing.T) {
+	for _, name := range fixtures {
+		t.Run(name, func(t *testing.T) {
+			b, err := Parse(bytes.NewReader(readFixture(t, name)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			b.Metadata.Title = "Edited"
+			b.Difficulty.CircleSize = 7.5
+			b.General.EpilepsyWarning = true
+			b.HitObjects[0].X = 1
+			b.HitObjects = b.HitObjects[:len(b.HitObjects)-1]
+			b.TimingPoints = append(b.TimingPoints, TimingPoint{Time: 99999, BeatLength: -50, Meter: 4, SampleSet: SampleSetSoft, Volume: 70})
+			b.Colours.Combo = append(b.Colours.Combo, Colour{1, 2, 3})
+			b.Events.Breaks = append(b.Events.Breaks, Break{StartTime: 100, EndTime: 200})
+
+			var buf bytes.Buffer
+			if err := b.Encode(&buf); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Parse(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Metadata.Title != "Edited" || got.Difficulty.CircleSize != 7.5 || !got.General.EpilepsyWarning {
+				t.Errorf("edited settings not kept: %q, %v, %v", got.Metadata.Title, got.Difficulty.CircleSize, got.General.EpilepsyWarning)
+			}
+			if len(got.HitObjects) != len(b.HitObjects) {
+				t.Fatalf("got %d hit objects, want %d", len(got.HitObjects), len(b.HitObjects))
+			}
+			for i := range b.HitObjects {
+				if got.HitObjects[i].String() != b.HitObjects[i].String() {
+					t.Errorf("hit object %d: got %q, want %q", i, got.HitObjects[i].String(), b.HitObjects[i].String())
+				}
+			}
+			if len(got.TimingPoints) != len(b.TimingPoints) || got.TimingPoints[len(got.TimingPoints)-1] != b.TimingPoints[len(b.TimingPoints)-1] {
+				t.Errorf("got timing points %v, want %v", got.TimingPoints, b.TimingPoints)
+			}
+			if len(got.Colours.Combo) != len(b.Colours.Combo) || got.Colours.Combo[len(got.Colours.Combo)-1] != (Colour{1, 2, 3}) {
+				t.Errorf("got combo colours %v, want %v", got.Colours.Combo, b.Colours.Combo)
+			}
+			if len(got.Events.Breaks) != len(b.Events.Breaks) || got.Events.Breaks[len(got.Events.Breaks)-1] != (Break{100, 200}) {
+				t.Errorf("got breaks %v, want %v", got.Events.Breaks, b.Events.Breaks)
+			}
+		})
+	}
+}
+
+func TestEncodeNew(t *testing.T) {
+	b := New()
+	b.Metadata.Title = "New"
+	b.HitObjects = append(b.HitObjects, HitObject{X: 1, Y: 2, Time: 3, Type: TypeCircle})
+	var first bytes.Buffer
+	if err := b.Encode(&first); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Parse(bytes.NewReader(first.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Metadata.Title != "New" || len(got.HitObjects) != 1 {
+		t.Fatalf("got title %q and %d hit objects", got.Metadata.Title, len(got.HitObjects))
+	}
+	var second bytes.Buffer
+	if err := got.Encode(&second); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		t.Errorf("re-encoding changed the file:\n got %q\nwant %q", second.Bytes(), first.Bytes())
+	}
+}
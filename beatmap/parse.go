@@ -0,0 +1,656 @@
+package beatmap
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pixelrazor/osu"
+)
+
+// ParseError reports a malformed line of a .osu file
+type ParseError struct {
+	// Line is the 1-based line number
+	Line    int
+	Section string
+	Text    string
+	Err     error
+}
+
+func (e *ParseError) Error() string {
+	if e.Section == "" {
+		return fmt.Sprintf("beatmap: line %d: %v: %q", e.Line, e.Err, e.Text)
+	}
+	return fmt.Sprintf("beatmap: line %d [%s]: %v: %q", e.Line, e.Section, e.Err, e.Text)
+}
+
+// Unwrap returns the underlying error
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+var headerRegex = regexp.MustCompile(`^osu file format v([0-9]+)`)
+
+// New returns an empty beatmap with the default settings of the latest file format version
+func New() *Beatmap {
+	return &Beatmap{
+		Version: 14,
+		General: General{
+			Countdown:       1,
+			SampleSet:       "Normal",
+			StackLeniency:   0.7,
+			PreviewTime:     -1,
+			OverlayPosition: "NoChange",
+		},
+		Editor: Editor{
+			DistanceSpacing: 1,
+			BeatDivisor:     4,
+			GridSize:        4,
+			TimelineZoom:    1,
+		},
+		Difficulty: Difficulty{
+			HPDrainRate:       5,
+			CircleSize:        5,
+			OverallDifficulty: 5,
+			ApproachRate:      5,
+			SliderMultiplier:  1.4,
+			SliderTickRate:    1,
+		},
+	}
+}
+
+// ParseFile reads the .osu file at path
+func ParseFile(path string) (*Beatmap, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads a .osu file. The lines of the file are kept so that Encode can write back everything the beatmap doesn't model
+func Parse(r io.Reader) (*Beatmap, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	b := New()
+	doc := &document{canon: make(map[string]string), counts: make(map[string]int)}
+	section := ""
+	header := false
+	sawAR := false
+	// a trailing newline leaves an empty last line, which joining the lines restores
+	lines := strings.Split(string(data), "\n")
+	for i, raw := range lines {
+		lineNo := i + 1
+		line := strings.TrimRight(raw, " \t\r")
+		if i == 0 {
+			line = strings.TrimPrefix(line, "\ufeff")
+			doc.crlf = strings.HasSuffix(raw, "\r")
+		}
+		dl := docLine{raw: raw, section: section}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "//") {
+			doc.lines = append(doc.lines, dl)
+			continue
+		}
+		fail := func(err error) error {
+			return &ParseError{Line: lineNo, Section: section, Text: line, Err: err}
+		}
+		if !header {
+			m := headerRegex.FindStringSubmatch(strings.TrimSpace(line))
+			if m == nil {
+				return nil, fail(errors.New("missing file format header"))
+			}
+			b.Version, _ = strconv.Atoi(m[1])
+			header = true
+			doc.lines = append(doc.lines, dl)
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = line[1 : len(line)-1]
+			dl.section = section
+			doc.lines = append(doc.lines, dl)
+			continue
+		}
+		var err error
+		switch section {
+		case "General", "Editor", "Metadata", "Difficulty", "Colours":
+			err = parseKeyValue(line, func(key, value string) error {
+				if section == "Colours" && strings.HasPrefix(key, "Combo") {
+					c, err := parseColour(value)
+					if err != nil {
+						return err
+					}
+					dl.kind, dl.key, dl.index = lineItem, "Combo", len(b.Colours.Combo)
+					b.Colours.Combo = append(b.Colours.Combo, c)
+					return nil
+				}
+				f, ok := findField(b.fields(section), key)
+				if !ok {
+					return nil
+				}
+				sawAR = sawAR || key == "ApproachRate"
+				dl.kind, dl.key = lineKey, key
+				return f.set(value)
+			})
+		case "Events":
+			var list string
+			list, err = b.Events.parse(line)
+			dl.kind, dl.key, dl.index = lineItem, list, b.listLen(list)-1
+		case "TimingPoints":
+			var tp TimingPoint
+			tp, err = parseTimingPoint(line)
+			dl.kind, dl.key, dl.index = lineItem, "TimingPoints", len(b.TimingPoints)
+			b.TimingPoints = append(b.TimingPoints, tp)
+		case "HitObjects":
+			var h HitObject
+			h, err = parseHitObject(line)
+			dl.kind, dl.key, dl.index = lineItem, "HitObjects", len(b.HitObjects)
+			b.HitObjects = append(b.HitObjects, h)
+		}
+		if err != nil {
+			return nil, fail(err)
+		}
+		doc.lines = append(doc.lines, dl)
+	}
+	if !header {
+		return nil, &ParseError{Line: len(lines), Err: errors.New("missing file format header")}
+	}
+	if !sawAR {
+		b.Difficulty.ApproachRate = b.Difficulty.OverallDifficulty
+	}
+	doc.snapshot(b)
+	b.doc = doc
+	return b, nil
+}
+
+// parseKeyValue splits a "Key: Value" line and passes it to set
+func parseKeyValue(line string, set func(key, value string) error) error {
+	i := strings.IndexByte(line, ':')
+	if i < 0 {
+		return errors.New("expected key: value")
+	}
+	return set(strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:]))
+}
+
+// field binds a key of a key-value section to the struct field holding its value
+type field struct {
+	key string
+	ptr interface{}
+}
+
+func findField(fields []field, key string) (field, bool) {
+	for _, f := range fields {
+		if f.key == key {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// fields returns the known keys of a key-value section, in the order they are written
+func (b *Beatmap) fields(section string) []field {
+	switch section {
+	case "General":
+		g := &b.General
+		return []field{
+			{"AudioFilename", &g.AudioFilename},
+			{"AudioLeadIn", &g.AudioLeadIn},
+			{"AudioHash", &g.AudioHash},
+			{"PreviewTime", &g.PreviewTime},
+			{"Countdown", &g.Countdown},
+			{"SampleSet", &g.SampleSet},
+			{"StackLeniency", &g.StackLeniency},
+			{"Mode", &g.Mode},
+			{"LetterboxInBreaks", &g.LetterboxInBreaks},
+			{"StoryFireInFront", &g.StoryFireInFront},
+			{"UseSkinSprites", &g.UseSkinSprites},
+			{"AlwaysShowPlayfield", &g.AlwaysShowPlayfield},
+			{"OverlayPosition", &g.OverlayPosition},
+			{"SkinPreference", &g.SkinPreference},
+			{"EpilepsyWarning", &g.EpilepsyWarning},
+			{"CountdownOffset", &g.CountdownOffset},
+			{"SpecialStyle", &g.SpecialStyle},
+			{"WidescreenStoryboard", &g.WidescreenStoryboard},
+			{"SamplesMatchPlaybackRate", &g.SamplesMatchPlaybackRate},
+		}
+	case "Editor":
+		e := &b.Editor
+		return []field{
+			{"Bookmarks", &e.Bookmarks},
+			{"DistanceSpacing", &e.DistanceSpacing},
+			{"BeatDivisor", &e.BeatDivisor},
+			{"GridSize", &e.GridSize},
+			{"TimelineZoom", &e.TimelineZoom},
+		}
+	case "Metadata":
+		m := &b.Metadata
+		return []field{
+			{"Title", &m.Title},
+			{"TitleUnicode", &m.TitleUnicode},
+			{"Artist", &m.Artist},
+			{"ArtistUnicode", &m.ArtistUnicode},
+			{"Creator", &m.Creator},
+			{"Version", &m.Version},
+			{"Source", &m.Source},
+			{"Tags", &m.Tags},
+			{"BeatmapID", &m.BeatmapID},
+			{"BeatmapSetID", &m.BeatmapSetID},
+		}
+	case "Difficulty":
+		d := &b.Difficulty
+		return []field{
+			{"HPDrainRate", &d.HPDrainRate},
+			{"CircleSize", &d.CircleSize},
+			{"OverallDifficulty", &d.OverallDifficulty},
+			{"ApproachRate", &d.ApproachRate},
+			{"SliderMultiplier", &d.SliderMultiplier},
+			{"SliderTickRate", &d.SliderTickRate},
+		}
+	case "Colours":
+		c := &b.Colours
+		return []field{
+			{"SliderTrackOverride", &c.SliderTrackOverride},
+			{"SliderBorder", &c.SliderBorder},
+		}
+	}
+	return nil
+}
+
+func (f field) set(value string) (err error) {
+	switch p := f.ptr.(type) {
+	case *string:
+		*p = value
+	case *int:
+		*p, err = parseInt(value)
+	case *float64:
+		*p, err = parseFloat(value)
+	case *bool:
+		*p, err = parseBool(value)
+	case *osu.Mode:
+		var m int
+		m, err = parseInt(value)
+		*p = osu.Mode(m)
+	case *osu.BeatmapID:
+		err = p.UnmarshalText([]byte(value))
+	case *osu.BeatmapsetID:
+		err = p.UnmarshalText([]byte(value))
+	case *[]string:
+		*p = strings.Fields(value)
+	case *[]int:
+		*p = nil
+		for _, s := range strings.Split(value, ",") {
+			if s = strings.TrimSpace(s); s == "" {
+				continue
+			}
+			n, err := parseInt(s)
+			if err != nil {
+				return err
+			}
+			*p = append(*p, n)
+		}
+	case **Colour:
+		var c Colour
+		c, err = parseColour(value)
+		*p = &c
+	}
+	return err
+}
+
+// format returns the value of the field as it is written, or "" for unset colours
+func (f field) format() string {
+	switch p := f.ptr.(type) {
+	case *string:
+		return *p
+	case *int:
+		return strconv.Itoa(*p)
+	case *float64:
+		return formatFloat(*p)
+	case *bool:
+		if *p {
+			return "1"
+		}
+		return "0"
+	case *osu.Mode:
+		return strconv.Itoa(int(*p))
+	case *osu.BeatmapID:
+		return p.String()
+	case *osu.BeatmapsetID:
+		return p.String()
+	case *[]string:
+		return strings.Join(*p, " ")
+	case *[]int:
+		s := make([]string, len(*p))
+		for i, n := range *p {
+			s[i] = strconv.Itoa(n)
+		}
+		return strings.Join(s, ",")
+	case **Colour:
+		if *p == nil {
+			return ""
+		}
+		return (*p).String()
+	}
+	return ""
+}
+
+func parseColour(s string) (Colour, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) < 3 {
+		return Colour{}, errors.New("colour needs 3 components")
+	}
+	var rgb [3]uint8
+	for i := range rgb {
+		n, err := strconv.ParseUint(strings.TrimSpace(parts[i]), 10, 8)
+		if err != nil {
+			return Colour{}, err
+		}
+		rgb[i] = uint8(n)
+	}
+	return Colour{rgb[0], rgb[1], rgb[2]}, nil
+}
+
+// parse adds an event line to the events, returning the name of the list it was added to
+func (e *Events) parse(line string) (string, error) {
+	fields := splitEvent(line)
+	switch fields[0] {
+	case "0", "Background":
+		if len(fields) < 3 {
+			return "", errors.New("background needs a filename")
+		}
+		bg := &Background{Filename: unquote(fields[2])}
+		var err error
+		if len(fields) > 4 {
+			if bg.XOffset, err = parseInt(fields[3]); err != nil {
+				return "", err
+			}
+			if bg.YOffset, err = parseInt(fields[4]); err != nil {
+				return "", err
+			}
+		}
+		e.Background = bg
+		return "Background", nil
+	case "1", "Video":
+		if len(fields) < 3 {
+			return "", errors.New("video needs a start time and filename")
+		}
+		start, err := parseInt(fields[1])
+		if err != nil {
+			return "", err
+		}
+		v := Video{StartTime: start, Filename: unquote(fields[2])}
+		if len(fields) > 4 {
+			if v.XOffset, err = parseInt(fields[3]); err != nil {
+				return "", err
+			}
+			if v.YOffset, err = parseInt(fields[4]); err != nil {
+				return "", err
+			}
+		}
+		e.Videos = append(e.Videos, v)
+		return "Videos", nil
+	case "2", "Break":
+		if len(fields) < 3 {
+			return "", errors.New("break needs a start and end time")
+		}
+		start, err := parseInt(fields[1])
+		if err != nil {
+			return "", err
+		}
+		end, err := parseInt(fields[2])
+		if err != nil {
+			return "", err
+		}
+		e.Breaks = append(e.Breaks, Break{start, end})
+		return "Breaks", nil
+	}
+	e.Storyboard = append(e.Storyboard, line)
+	return "Storyboard", nil
+}
+
+// splitEvent splits an event line on commas outside of quotes
+func splitEvent(line string) []string {
+	var fields []string
+	quoted := false
+	start := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				fields = append(fields, line[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(fields, line[start:])
+}
+
+func unquote(s string) string {
+	return strings.Trim(s, `"`)
+}
+
+func parseTimingPoint(line string) (TimingPoint, error) {
+	fields := strings.Split(line, ",")
+	if len(fields) < 2 {
+		return TimingPoint{}, errors.New("timing point needs a time and beat length")
+	}
+	tp := TimingPoint{Meter: 4, Volume: 100}
+	var err error
+	if tp.Time, err = parseFloat(fields[0]); err != nil {
+		return tp, err
+	}
+	if tp.BeatLength, err = parseFloat(fields[1]); err != nil {
+		return tp, err
+	}
+	tp.Uninherited = tp.BeatLength >= 0
+	ints := []*int{&tp.Meter, (*int)(&tp.SampleSet), &tp.SampleIndex, &tp.Volume}
+	for i, p := range ints {
+		if len(fields) <= i+2 {
+			break
+		}
+		if *p, err = parseInt(fields[i+2]); err != nil {
+			return tp, err
+		}
+	}
+	if len(fields) > 6 {
+		if tp.Uninherited, err = parseBool(fields[6]); err != nil {
+			return tp, err
+		}
+	}
+	if len(fields) > 7 {
+		var effects int
+		if effects, err = parseInt(fields[7]); err != nil {
+			return tp, err
+		}
+		tp.Effects = Effect(effects)
+	}
+	return tp, nil
+}
+
+func parseHitObject(line string) (HitObject, error) {
+	var h HitObject
+	fields := strings.Split(line, ",")
+	if len(fields) < 4 {
+		return h, errors.New("hit object needs a position, time and type")
+	}
+	var err error
+	if h.X, err = parseInt(fields[0]); err != nil {
+		return h, err
+	}
+	if h.Y, err = parseInt(fields[1]); err != nil {
+		return h, err
+	}
+	if h.Time, err = parseInt(fields[2]); err != nil {
+		return h, err
+	}
+	var n int
+	if n, err = parseInt(fields[3]); err != nil {
+		return h, err
+	}
+	h.Type = HitObjectType(n)
+	if len(fields) > 4 {
+		if n, err = parseInt(fields[4]); err != nil {
+			return h, err
+		}
+		h.HitSound = HitSound(n)
+	}
+	var rest []string
+	if len(fields) > 5 {
+		rest = fields[5:]
+	}
+	switch {
+	case h.IsSlider():
+		if len(rest) < 2 {
+			return h, errors.New("slider needs a curve and slide count")
+		}
+		h.Slider, err = parseSlider(rest)
+		if err != nil {
+			return h, err
+		}
+		if len(rest) > 5 {
+			h.Sample, err = parseHitSample(rest[5])
+		}
+	case h.IsSpinner():
+		if len(rest) < 1 {
+			return h, errors.New("spinner needs an end time")
+		}
+		if h.EndTime, err = parseInt(rest[0]); err != nil {
+			return h, err
+		}
+		if len(rest) > 1 {
+			h.Sample, err = parseHitSample(rest[1])
+		}
+	case h.IsHold():
+		if len(rest) < 1 {
+			return h, errors.New("hold note needs an end time")
+		}
+		parts := strings.SplitN(rest[0], ":", 2)
+		if h.EndTime, err = parseInt(parts[0]); err != nil {
+			return h, err
+		}
+		if len(parts) > 1 {
+			h.Sample, err = parseHitSample(parts[1])
+		}
+	default:
+		if len(rest) > 0 {
+			h.Sample, err = parseHitSample(rest[0])
+		}
+	}
+	return h, err
+}
+
+func parseSlider(fields []string) (*Slider, error) {
+	s := &Slider{Slides: 1}
+	points := strings.Split(fields[0], "|")
+	if len(points[0]) == 1 && (points[0][0] < '0' || points[0][0] > '9') {
+		s.CurveType = CurveType(points[0][0])
+		points = points[1:]
+	}
+	for _, p := range points {
+		xy := strings.Split(p, ":")
+		if len(xy) != 2 {
+			return nil, errors.New("invalid curve point " + strconv.Quote(p))
+		}
+		x, err := parseInt(xy[0])
+		if err != nil {
+			return nil, err
+		}
+		y, err := parseInt(xy[1])
+		if err != nil {
+			return nil, err
+		}
+		s.CurvePoints = append(s.CurvePoints, Point{x, y})
+	}
+	var err error
+	if s.Slides, err = parseInt(fields[1]); err != nil {
+		return nil, err
+	}
+	if s.Slides > maxSliderSpans {
+		return nil, errors.New("slider has more than " + strconv.Itoa(maxSliderSpans) + " slides")
+	}
+	if len(fields) > 2 {
+		if s.Length, err = parseFloat(fields[2]); err != nil {
+			return nil, err
+		}
+	}
+	if len(fields) > 3 && fields[3] != "" {
+		for _, e := range strings.Split(fields[3], "|") {
+			n, err := parseInt(e)
+			if err != nil {
+				return nil, err
+			}
+			s.EdgeSounds = append(s.EdgeSounds, HitSound(n))
+		}
+	}
+	if len(fields) > 4 && fields[4] != "" {
+		for _, e := range strings.Split(fields[4], "|") {
+			sets := strings.Split(e, ":")
+			if len(sets) != 2 {
+				return nil, errors.New("invalid edge set " + strconv.Quote(e))
+			}
+			normal, err := parseInt(sets[0])
+			if err != nil {
+				return nil, err
+			}
+			addition, err := parseInt(sets[1])
+			if err != nil {
+				return nil, err
+			}
+			s.EdgeSets = append(s.EdgeSets, EdgeSet{SampleSet(normal), SampleSet(addition)})
+		}
+	}
+	return s, nil
+}
+
+func parseHitSample(s string) (HitSample, error) {
+	var hs HitSample
+	if s == "" {
+		return hs, nil
+	}
+	parts := strings.SplitN(s, ":", 5)
+	ints := []*int{(*int)(&hs.NormalSet), (*int)(&hs.AdditionSet), &hs.Index, &hs.Volume}
+	for i, p := range ints {
+		if i >= len(parts) {
+			break
+		}
+		var err error
+		if *p, err = parseInt(parts[i]); err != nil {
+			return hs, err
+		}
+	}
+	if len(parts) > 4 {
+		hs.Filename = parts[4]
+	}
+	return hs, nil
+}
+
+// parseInt parses an integer, truncating values written with a fractional part as some old beatmaps have
+func parseInt(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	n, err := strconv.Atoi(s)
+	if err == nil {
+		return n, nil
+	}
+	f, ferr := strconv.ParseFloat(s, 64)
+	if ferr != nil {
+		return 0, err
+	}
+	return int(f), nil
+}
+
+func parseFloat(s string) (float64, error) {
+	return strconv.ParseFloat(strings.TrimSpace(s), 64)
+}
+
+func parseBool(s string) (bool, error) {
+	n, err := parseInt(s)
+	return n != 0, err
+}
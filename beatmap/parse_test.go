@@ -0,0 +1,141 @@
+package beatmap
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseSections(t *testing.T) {
+	b, err := Parse(bytes.NewReader(readFixture(t, "v14.osu")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Version != 14 || b.General.AudioFilename != "audio.mp3" || b.General.SampleSet != "Soft" || !b.General.WidescreenStoryboard {
+		t.Errorf("got general %+v in version %d", b.General, b.Version)
+	}
+	if !reflect.DeepEqual(b.Editor.Bookmarks, []int{1000, 2000, 3000}) || b.Editor.BeatDivisor != 4 {
+		t.Errorf("got editor %+v", b.Editor)
+	}
+	if b.Metadata.TitleUnicode != "テスト" || b.Metadata.BeatmapID != 123456 || len(b.Metadata.Tags) != 3 {
+		t.Errorf("got metadata %+v", b.Metadata)
+	}
+	if b.Difficulty.ApproachRate != 9 || b.Difficulty.SliderMultiplier != 1.8 {
+		t.Errorf("got difficulty %+v", b.Difficulty)
+	}
+	if b.Events.Background == nil || b.Events.Background.Filename != "bg.jpg" {
+		t.Errorf("got background %+v", b.Events.Background)
+	}
+	if len(b.Events.Videos) != 1 || b.Events.Videos[0] != (Video{StartTime: -200, Filename: "video.mp4"}) {
+		t.Errorf("got videos %+v", b.Events.Videos)
+	}
+	if len(b.Events.Breaks) != 1 || b.Events.Breaks[0] != (Break{10000, 12000}) || len(b.Events.Storyboard) != 3 {
+		t.Errorf("got breaks %+v and %d storyboard lines", b.Events.Breaks, len(b.Events.Storyboard))
+	}
+	if len(b.TimingPoints) != 3 || !b.TimingPoints[0].Uninherited || b.TimingPoints[1].Uninherited || b.TimingPoints[1].Effects != EffectKiai {
+		t.Errorf("got timing points %+v", b.TimingPoints)
+	}
+	if len(b.Colours.Combo) != 2 || b.Colours.Combo[0] != (Colour{255, 128, 64}) {
+		t.Errorf("got colours %+v", b.Colours)
+	}
+	if len(b.HitObjects) != 5 {
+		t.Fatalf("got %d hit objects", len(b.HitObjects))
+	}
+	if s := b.HitObjects[1].Slider; s == nil || s.CurveType != CurveBezier || s.Slides != 2 || len(s.EdgeSounds) != 3 || len(s.EdgeSets) != 3 {
+		t.Errorf("got slider %+v", s)
+	}
+	if h := b.HitObjects[3]; !h.IsSpinner() || h.EndTime != 6000 {
+		t.Errorf("got spinner %+v", h)
+	}
+	if h := b.HitObjects[4]; !h.IsHold() || h.EndTime != 7500 {
+		t.Errorf("got hold note %+v", h)
+	}
+}
+
+func TestParseHitObject(t *testing.T) {
+	tests := []struct {
+		line string
+		want HitObject
+		err  bool
+	}{
+		// the hitsound and hit sample are optional
+		{line: "0,0,0,0", want: HitObject{}},
+		{line: "256,192,500,1", want: HitObject{X: 256, Y: 192, Time: 500, Type: TypeCircle}},
+		{line: "256,192,500,5,2", want: HitObject{X: 256, Y: 192, Time: 500, Type: TypeCircle | TypeNewCombo, HitSound: HitSoundWhistle}},
+		{line: "256,192,500,1,0,1:2:3:40:hit.wav", want: HitObject{X: 256, Y: 192, Time: 500, Type: TypeCircle,
+			Sample: HitSample{SampleSetNormal, SampleSetSoft, 3, 40, "hit.wav"}}},
+		{line: "256,192,500.7,1,0", want: HitObject{X: 256, Y: 192, Time: 500, Type: TypeCircle}},
+		{line: "256,192,4000,12,0,6000", want: HitObject{X: 256, Y: 192, Time: 4000, Type: TypeSpinner | TypeNewCombo, EndTime: 6000}},
+		{line: "64,192,7000,128,0,7500", want: HitObject{X: 64, Y: 192, Time: 7000, Type: TypeHold, EndTime: 7500}},
+		{line: "0,0,0,2,0,L|10:0,1", want: HitObject{Type: TypeSlider, Slider: &Slider{CurveType: CurveLinear, CurvePoints: []Point{{10, 0}}, Slides: 1}}},
+		{line: "0,0,0,2,0,L|10:0,9000", want: HitObject{Type: TypeSlider, Slider: &Slider{CurveType: CurveLinear, CurvePoints: []Point{{10, 0}}, Slides: 9000}}},
+		{line: "", err: true},
+		{line: "0,0,0", err: true},
+		{line: "x,0,0,1", err: true},
+		{line: "0,0,0,x", err: true},
+		{line: "0,0,0,1,x", err: true},
+		{line: "0,0,0,1,0,x:0", err: true},
+		// sliders, spinners and hold notes need more fields than circles
+		{line: "0,0,0,2", err: true},
+		{line: "0,0,0,2,0", err: true},
+		{line: "0,0,0,2,0,B|1:1", err: true},
+		{line: "0,0,0,2,0,B|1,1", err: true},
+		{line: "0,0,0,2,0,B|1:1,x", err: true},
+		// osu!lazer rejects sliders with more than 9000 slides
+		{line: "0,0,0,2,0,B|1:1,9001", err: true},
+		{line: "0,0,0,2,0,B|1:1,1,x", err: true},
+		{line: "0,0,0,2,0,B|1:1,1,100,x", err: true},
+		{line: "0,0,0,2,0,B|1:1,1,100,0|0,1", err: true},
+		{line: "0,0,0,8", err: true},
+		{line: "0,0,0,8,0", err: true},
+		{line: "0,0,0,8,0,x", err: true},
+		{line: "0,0,0,128", err: true},
+		{line: "0,0,0,128,0,x:0:0:0:0:", err: true},
+	}
+	for _, tt := range tests {
+		got, err := parseHitObject(tt.line)
+		switch {
+		case tt.err && err == nil:
+			t.Errorf("%q: got %+v, want an error", tt.line, got)
+		case !tt.err && err != nil:
+			t.Errorf("%q: %v", tt.line, err)
+		case !tt.err && !reflect.DeepEqual(got, tt.want):
+			t.Errorf("%q: got %+v, want %+v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		file    string
+		line    int
+		section string
+	}{
+		{"", 1, ""},
+		{"[General]\nMode: 0\n", 1, ""},
+		{"osu file format v14\n\n[General]\nMode: x\n", 4, "General"},
+		{"osu file format v14\n[Difficulty]\nCircleSize:4\nApproachRate:fast\n", 4, "Difficulty"},
+		{"osu file format v14\n[Events]\n2,100\n", 3, "Events"},
+		{"osu file format v14\n[TimingPoints]\n100\n", 3, "TimingPoints"},
+		{"osu file format v14\n[Colours]\nCombo1 : 300,0,0\n", 3, "Colours"},
+		{"osu file format v14\r\n[HitObjects]\r\n0,0,0,1\r\n// comment\r\n0,0,0\r\n", 5, "HitObjects"},
+		{"osu file format v14\n[HitObjects]\n0,0,0,2,0\n", 3, "HitObjects"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(strings.NewReader(tt.file))
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("%q: got %v, want a ParseError", tt.file, err)
+			continue
+		}
+		if pe.Line != tt.line || pe.Section != tt.section {
+			t.Errorf("%q: got line %d section %q, want %d %q", tt.file, pe.Line, pe.Section, tt.line, tt.section)
+		}
+		if !strings.HasPrefix(pe.Error(), "beatmap: line "+strconv.Itoa(tt.line)) {
+			t.Errorf("%q: got message %q", tt.file, pe.Error())
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package beatmap
+
+import "math"
+
+const (
+	// baseScoringDistance is the distance in osu! pixels a slider travels in a beat when the slider multiplier and velocity are 1
+	baseScoringDistance = 100
+	// legacyLastTickOffset is how long before its end osu! checks that a slider is held
+	legacyLastTickOffset = 36
+	// maxSliderLength is the longest path osu! places ticks on
+	maxSliderLength = 100000
+	// maxSliderSpans is the most slides osu!lazer accepts for a slider
+	maxSliderSpans = 9000
+	// maxSliderTicks bounds the ticks of a slider, shared between its spans, so a tiny tick distance can't exhaust memory
+	maxSliderTicks = 100000
+)
+
+// TimingAt returns the beat length of the uninherited timing point and the slider velocity multiplier in effect at time t.
+// Uninherited timing points reset the slider velocity to 1, and before the first timing point the first beat length applies
+func (b *Beatmap) TimingAt(t float64) (beatLength, sliderVelocity float64) {
+	beatLength, sliderVelocity = 1000, 1
+	found := false
+	for _, tp := range b.TimingPoints {
+		if tp.Time > t && found {
+			break
+		}
+		if tp.Uninherited {
+			beatLength, sliderVelocity = tp.BeatLength, 1
+			found = true
+		} else if tp.Time <= t {
+			sliderVelocity = tp.SliderVelocity()
+		}
+	}
+	if math.IsNaN(sliderVelocity) {
+		sliderVelocity = 1
+	}
+	return beatLength, math.Max(0.1, math.Min(sliderVelocity, 10))
+}
+
+// SliderEventType is the kind of a point of a slider that is judged
+type SliderEventType int
+
+// All slider event types. SliderLastTick is the point shortly before the end where osu! checks that the slider is held
+const (
+	SliderHead SliderEventType = iota
+	SliderTick
+	SliderRepeat
+	SliderLastTick
+	SliderTail
+)
+
+// SliderEvent is a point of a slider that is judged
+type SliderEvent struct {
+	Type SliderEventType
+	// Time is in milliseconds
+	Time float64
+	// Span is the 0-based slide the event is on
+	Span int
+	// Progress is the position of the event along the path, from 0 at the start to 1 at the end
+	Progress float64
+	Position Vector
+}
+
+// SliderTiming holds the path and timing of a slider
+type SliderTiming struct {
+	Path *SliderPath
+	// Velocity is in osu! pixels per millisecond
+	Velocity float64
+	// TickDistance is the distance between ticks in osu! pixels, 0 when the slider has no ticks
+	TickDistance float64
+	// SpanDuration, Duration and EndTime are in milliseconds
+	SpanDuration float64
+	Duration     float64
+	EndTime      float64
+	EndPosition  Vector
+	// Events holds the head, ticks, repeats, last tick and tail of the slider in order of time
+	Events []SliderEvent
+}
+
+// SliderTiming computes the path, end and ticks of a slider from the timing points in effect at its start,
+// or returns nil for other objects. Like osu!lazer, no more than 9000 slides are played
+func (b *Beatmap) SliderTiming(h *HitObject) *SliderTiming {
+	path := h.Path()
+	if path == nil {
+		return nil
+	}
+	beatLength, sv := b.TimingAt(float64(h.Time))
+	scoringDistance := baseScoringDistance * b.Difficulty.SliderMultiplier * sv
+	st := &SliderTiming{Path: path, Velocity: scoringDistance / beatLength}
+	if b.Difficulty.SliderTickRate > 0 {
+		st.TickDistance = scoringDistance / b.Difficulty.SliderTickRate
+		// before version 8 ticks didn't take the slider velocity into account
+		if b.Version < 8 {
+			st.TickDistance /= sv
+		}
+	}
+	spans := min(max(h.Slider.Slides, 1), maxSliderSpans)
+	if st.Velocity > 0 {
+		st.SpanDuration = path.Length() / st.Velocity
+	}
+	st.Duration = float64(spans) * st.SpanDuration
+	start := float64(h.Time)
+	st.EndTime = start + st.Duration
+	st.EndPosition = path.PositionAt(float64(spans % 2))
+	st.Events = st.events(start, spans)
+	return st
+}
+
+func (st *SliderTiming) events(start float64, spans int) []SliderEvent {
+	event := func(typ SliderEventType, time float64, span int, progress float64) SliderEvent {
+		return SliderEvent{Type: typ, Time: time, Span: span, Progress: progress, Position: st.Path.PositionAt(progress)}
+	}
+	events := []SliderEvent{event(SliderHead, start, 0, 0)}
+	length := math.Min(maxSliderLength, st.Path.Length())
+	tickDistance := math.Max(0, math.Min(st.TickDistance, length))
+	// ticks too close to the end of a span are left out
+	minDistanceFromEnd := st.Velocity * 10
+	maxTicks := max(maxSliderTicks/spans, 1)
+	for span := 0; span < spans; span++ {
+		spanStart := start + float64(span)*st.SpanDuration
+		reversed := span%2 == 1
+		var ticks []SliderEvent
+		for d := tickDistance; tickDistance > 0 && d <= length && len(ticks) < maxTicks; d += tickDistance {
+			if d >= length-minDistanceFromEnd {
+				break
+			}
+			progress := d / length
+			timeProgress := progress
+			if reversed {
+				timeProgress = 1 - progress
+			}
+			ticks = append(ticks, event(SliderTick, spanStart+timeProgress*st.SpanDuration, span, progress))
+		}
+		if reversed {
+			for i, j := 0, len(ticks)-1; i < j; i, j = i+1, j-1 {
+				ticks[i], ticks[j] = ticks[j], ticks[i]
+			}
+		}
+		events = append(events, ticks...)
+		if span < spans-1 {
+			events = append(events, event(SliderRepeat, spanStart+st.SpanDuration, span, float64((span+1)%2)))
+		}
+	}
+	finalSpanStart := start + float64(spans-1)*st.SpanDuration
+	lastTick := math.Max(start+st.Duration/2, finalSpanStart+st.SpanDuration-legacyLastTickOffset)
+	progress := 0.0
+	if st.SpanDuration > 0 {
+		progress = (lastTick - finalSpanStart) / st.SpanDuration
+	}
+	if spans%2 == 0 {
+		progress = 1 - progress
+	}
+	events = append(events, event(SliderLastTick, lastTick, spans-1, progress))
+	return append(events, event(SliderTail, st.EndTime, spans-1, float64(spans%2)))
+}
@@ -0,0 +1,69 @@
+package beatmap
+
+import (
+	"math"
+	"testing"
+)
+
+func testSlider(time, slides int, length float64) *HitObject {
+	return &HitObject{Time: time, Type: TypeSlider, Slider: &Slider{CurveType: CurveLinear, CurvePoints: []Point{{300, 0}}, Length: length, Slides: slides}}
+}
+
+func TestSliderTiming(t *testing.T) {
+	b := New()
+	b.TimingPoints = []TimingPoint{{Time: 0, BeatLength: 500, Uninherited: true}, {Time: 5000, BeatLength: -50}}
+	st := b.SliderTiming(testSlider(1000, 2, 280))
+	if math.Abs(st.EndTime-3000) > 1e-6 || math.Abs(st.SpanDuration-1000) > 1e-6 || st.TickDistance != 140 || st.EndPosition != (Vector{}) {
+		t.Fatalf("got %+v", st)
+	}
+	want := []SliderEvent{
+		{Type: SliderHead, Time: 1000},
+		{Type: SliderTick, Time: 1500, Progress: 0.5, Position: Vector{140, 0}},
+		{Type: SliderRepeat, Time: 2000, Progress: 1, Position: Vector{280, 0}},
+		{Type: SliderTick, Time: 2500, Span: 1, Progress: 0.5, Position: Vector{140, 0}},
+		{Type: SliderLastTick, Time: 2964, Span: 1, Progress: 0.036, Position: Vector{10.08, 0}},
+		{Type: SliderTail, Time: 3000, Span: 1, Position: Vector{}},
+	}
+	if len(st.Events) != len(want) {
+		t.Fatalf("got events %+v", st.Events)
+	}
+	for i, e := range st.Events {
+		w := want[i]
+		if e.Type != w.Type || math.Abs(e.Time-w.Time) > 1e-6 || e.Span != w.Span || math.Abs(e.Progress-w.Progress) > 1e-9 ||
+			math.Abs(e.Position.X-w.Position.X) > 0.01 || math.Abs(e.Position.Y-w.Position.Y) > 0.01 {
+			t.Errorf("event %d: got %+v, want %+v", i, e, w)
+		}
+	}
+
+	// twice the velocity halves the duration and leaves no room for a tick
+	if st := b.SliderTiming(testSlider(6000, 2, 280)); math.Abs(st.EndTime-7000) > 1e-6 || len(st.Events) != 4 {
+		t.Errorf("got %+v", st)
+	}
+	if st := b.SliderTiming(&HitObject{Type: TypeCircle}); st != nil {
+		t.Errorf("got %+v for a circle", st)
+	}
+}
+
+func TestSliderTimingLimits(t *testing.T) {
+	b := New()
+	b.TimingPoints = []TimingPoint{{Time: 0, BeatLength: 500, Uninherited: true}}
+
+	// slides past what osu!lazer accepts aren't played
+	st := b.SliderTiming(testSlider(0, 1000000000, 280))
+	if math.Abs(st.EndTime-maxSliderSpans*1000) > 1e-3 || len(st.Events) != 2*maxSliderSpans+2 {
+		t.Errorf("got end time %v and %d events", st.EndTime, len(st.Events))
+	}
+
+	// a tiny tick distance places no more than maxSliderTicks ticks over all spans
+	b.Difficulty.SliderTickRate = 1e6
+	st = b.SliderTiming(testSlider(0, 2, 280))
+	ticks := 0
+	for _, e := range st.Events {
+		if e.Type == SliderTick {
+			ticks++
+		}
+	}
+	if ticks != maxSliderTicks || st.Events[len(st.Events)-1].Type != SliderTail {
+		t.Errorf("got %d ticks", ticks)
+	}
+}
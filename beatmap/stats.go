@@ -0,0 +1,159 @@
+package beatmap
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/pixelrazor/osu"
+)
+
+// taikoVelocityMultiplier is how much faster osu!taiko scrolls sliders converted to drum rolls
+const taikoVelocityMultiplier = 1.4
+
+// Counts returns the number of circles, sliders and spinners, counting mania hold notes as sliders like the API does
+func (b *Beatmap) Counts() (circles, sliders, spinners int) {
+	for i := range b.HitObjects {
+		h := &b.HitObjects[i]
+		switch {
+		case h.IsCircle():
+			circles++
+		case h.IsSlider(), h.IsHold():
+			sliders++
+		case h.IsSpinner():
+			spinners++
+		}
+	}
+	return circles, sliders, spinners
+}
+
+// EndTime returns when an object ends in milliseconds, computing the end of sliders from the timing points
+func (b *Beatmap) EndTime(h *HitObject) float64 {
+	switch {
+	case h.IsSlider() && h.Slider != nil:
+		return b.SliderTiming(h).EndTime
+	case h.IsSpinner(), h.IsHold():
+		return float64(h.EndTime)
+	}
+	return float64(h.Time)
+}
+
+// TotalLength returns the time from the start of the first object to the end of the last, the API's total length
+func (b *Beatmap) TotalLength() time.Duration {
+	start, end, ok := b.playRange()
+	if !ok {
+		return 0
+	}
+	return toDuration(end - start)
+}
+
+// DrainLength returns the total length without breaks, the API's hit length
+func (b *Beatmap) DrainLength() time.Duration {
+	start, end, ok := b.playRange()
+	if !ok {
+		return 0
+	}
+	drain := end - start
+	for _, br := range b.Events.Breaks {
+		drain -= math.Max(0, math.Min(float64(br.EndTime), end)-math.Max(float64(br.StartTime), start))
+	}
+	return toDuration(math.Max(0, drain))
+}
+
+func toDuration(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+// playRange returns the start of the first object and the end of the last
+func (b *Beatmap) playRange() (start, end float64, ok bool) {
+	if len(b.HitObjects) == 0 {
+		return 0, 0, false
+	}
+	start, end = math.Inf(1), math.Inf(-1)
+	for i := range b.HitObjects {
+		h := &b.HitObjects[i]
+		start = math.Min(start, float64(h.Time))
+		end = math.Max(end, b.EndTime(h))
+	}
+	return start, end, true
+}
+
+// MaxCombo returns the highest combo reachable in a mode, which may be a mode the beatmap is converted to:
+//   - osu!: circles and spinners give 1, sliders give their head, ticks, repeats and tail
+//   - taiko: only hits give combo; drum rolls that osu! converts into hits count those hits
+//   - catch: fruits and droplets give combo, tiny droplets and bananas don't
+//   - mania: notes give 1 and hold notes 1 for their head and 1 for every 100ms they're held
+//
+// Only osu!standard beatmaps can be converted, and conversion to mania isn't supported since it depends on
+// generating the notes the way osu! does
+func (b *Beatmap) MaxCombo(mode osu.Mode) (int, error) {
+	converted := mode != b.General.Mode
+	if converted && (b.General.Mode != osu.ModeOsu || mode == osu.ModeMania) {
+		return 0, errors.New("beatmap: can't convert beatmap from " + b.General.Mode.String() + " to " + mode.String())
+	}
+	combo := 0
+	for i := range b.HitObjects {
+		h := &b.HitObjects[i]
+		switch mode {
+		case osu.ModeOsu:
+			switch {
+			case h.IsSlider() && h.Slider != nil:
+				// the last tick is judged in place of the tail
+				combo += len(b.SliderTiming(h).Events) - 1
+			case h.IsCircle(), h.IsSpinner():
+				combo++
+			}
+		case osu.ModeTaiko:
+			switch {
+			case h.IsSlider() && h.Slider != nil && converted:
+				combo += b.taikoHits(h)
+			case h.IsCircle():
+				combo++
+			}
+		case osu.ModeCtb:
+			switch {
+			case h.IsSlider() && h.Slider != nil:
+				combo += len(b.SliderTiming(h).Events) - 1
+			case h.IsCircle():
+				combo++
+			}
+		case osu.ModeMania:
+			switch {
+			case h.IsHold():
+				combo += 1 + int(float64(h.EndTime-h.Time)/100)
+			case h.IsCircle():
+				combo++
+			}
+		}
+	}
+	return combo, nil
+}
+
+// taikoHits returns how many hits a slider converted to osu!taiko becomes: short sliders are split into hits spaced by
+// ticks, others become drum rolls, which don't give combo
+func (b *Beatmap) taikoHits(h *HitObject) int {
+	beatLength, sv := b.TimingAt(float64(h.Time))
+	d := b.Difficulty
+	if d.SliderTickRate <= 0 || d.SliderMultiplier <= 0 {
+		return 0
+	}
+	spans := max(h.Slider.Slides, 1)
+	distance := h.Slider.Length * float64(spans) * taikoVelocityMultiplier
+	scaledBeatLength := beatLength / sv
+	taikoVelocity := baseScoringDistance * d.SliderMultiplier
+	taikoDuration := float64(int(distance / taikoVelocity * scaledBeatLength))
+	osuVelocity := taikoVelocity * 1000 / scaledBeatLength
+	// osu! only uses the beat length adjusted by slider velocity to space hits for beatmaps older than version 8
+	if b.Version >= 8 {
+		scaledBeatLength = beatLength
+	}
+	tickSpacing := math.Min(scaledBeatLength/d.SliderTickRate, taikoDuration/float64(spans))
+	if tickSpacing <= 0 || distance/osuVelocity*1000 >= 2*scaledBeatLength {
+		return 0
+	}
+	hits := 0
+	for t := float64(h.Time); t <= float64(h.Time)+taikoDuration+tickSpacing/8; t += tickSpacing {
+		hits++
+	}
+	return hits
+}
@@ -0,0 +1,128 @@
+package beatmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pixelrazor/osu"
+)
+
+func TestStats(t *testing.T) {
+	tests := []struct {
+		name                       string
+		combo                      int
+		circles, sliders, spinners int
+	}{
+		// sliders give their head, ticks, repeats and tail
+		{"v3.osu", 6, 1, 1, 1},
+		{"v7.osu", 12, 3, 2, 1},
+		{"v14.osu", 7, 1, 3, 1},
+		// drum rolls and dendens don't give combo
+		{"taiko.osu", 4, 4, 1, 1},
+		// droplets give combo, bananas don't
+		{"catch.osu", 8, 3, 1, 1},
+		// hold notes of 450ms, 1000ms and 99ms give 5, 11 and 1
+		{"mania.osu", 20, 3, 3, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := Parse(bytes.NewReader(readFixture(t, tt.name)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			combo, err := b.MaxCombo(b.General.Mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if combo != tt.combo {
+				t.Errorf("got max combo %d, want %d", combo, tt.combo)
+			}
+			circles, sliders, spinners := b.Counts()
+			if circles != tt.circles || sliders != tt.sliders || spinners != tt.spinners {
+				t.Errorf("got counts %d, %d, %d, want %d, %d, %d", circles, sliders, spinners, tt.circles, tt.sliders, tt.spinners)
+			}
+		})
+	}
+}
+
+func TestMaxComboConverted(t *testing.T) {
+	b, err := Parse(bytes.NewReader(readFixture(t, "v7.osu")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for mode, want := range map[osu.Mode]int{osu.ModeTaiko: 6, osu.ModeCtb: 11} {
+		if combo, err := b.MaxCombo(mode); err != nil || combo != want {
+			t.Errorf("%v: got max combo %d, %v, want %d", mode, combo, err, want)
+		}
+	}
+	if _, err := b.MaxCombo(osu.ModeMania); err == nil {
+		t.Error("no error converting to mania")
+	}
+}
+
+func TestStatsSliderWithoutPath(t *testing.T) {
+	b := New()
+	b.HitObjects = []HitObject{{Time: 1000, Type: TypeCircle}, {Time: 2000, Type: TypeSlider}}
+	if end := b.EndTime(&b.HitObjects[1]); end != 2000 {
+		t.Errorf("got end time %v, want 2000", end)
+	}
+	if length := b.TotalLength(); length.Milliseconds() != 1000 {
+		t.Errorf("got total length %v, want 1s", length)
+	}
+	for _, mode := range []osu.Mode{osu.ModeOsu, osu.ModeTaiko, osu.ModeCtb} {
+		if combo, err := b.MaxCombo(mode); err != nil || combo != 1 {
+			t.Errorf("%v: got max combo %d, %v, want 1", mode, combo, err)
+		}
+	}
+}
+
+// TestStatsAPI checks the stats against the values osu! reports for ranked maps. Each map is a .osu file in
+// testdata/api next to a .json file of the same name holding the get_beatmaps response for it, with a=1 and
+// m set for converted modes. Lengths are compared in whole seconds as the API rounds them
+func TestStatsAPI(t *testing.T) {
+	responses, err := filepath.Glob(filepath.Join("testdata", "api", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(responses) == 0 {
+		t.Skip("no ranked maps in testdata/api")
+	}
+	for _, path := range responses {
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var maps []osu.Beatmap
+			if err := json.Unmarshal(data, &maps); err != nil {
+				t.Fatal(err)
+			}
+			if len(maps) != 1 {
+				t.Fatalf("got %d beatmaps in the response, want 1", len(maps))
+			}
+			want := maps[0]
+			b, err := Parse(bytes.NewReader(readFixture(t, filepath.Join("api", name+".osu"))))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if combo, err := b.MaxCombo(want.Mode); err != nil || int64(combo) != want.MaxCombo {
+				t.Errorf("got max combo %d, %v, want %d", combo, err, want.MaxCombo)
+			}
+			circles, sliders, spinners := b.Counts()
+			if int64(circles) != want.CountNormal || int64(sliders) != want.CountSlider || int64(spinners) != want.CountSpinner {
+				t.Errorf("got counts %d, %d, %d, want %d, %d, %d", circles, sliders, spinners, want.CountNormal, want.CountSlider, want.CountSpinner)
+			}
+			if got := b.TotalLength().Seconds(); got-float64(want.TotalLength) > 1 || float64(want.TotalLength)-got > 1 {
+				t.Errorf("got total length %vs, want %ds", got, want.TotalLength)
+			}
+			if got := b.DrainLength().Seconds(); got-float64(want.HitLength) > 1 || float64(want.HitLength)-got > 1 {
+				t.Errorf("got drain length %vs, want %ds", got, want.HitLength)
+			}
+		})
+	}
+}
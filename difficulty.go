@@ -0,0 +1,161 @@
+package osu
+
+import (
+	"math"
+)
+
+// HitWindows holds how far from an object's time, in milliseconds either way, each judgement can be given.
+// Judgements a mode does not have are 0
+type HitWindows struct {
+	// Perfect is the window for a MAX (rainbow 300) in mania
+	Perfect float64
+	// Great is the window for a 300
+	Great float64
+	// Good is the window for a 200 in mania
+	Good float64
+	// Ok is the window for a 100
+	Ok float64
+	// Meh is the window for a 50
+	Meh float64
+	// Miss is the window in which pressing a key counts as a miss rather than being ignored
+	Miss float64
+}
+
+// Difficulty holds the difficulty settings of a beatmap as they play with a set of mods.
+// AR and OD are the values a beatmap without mods would need to play the same at normal speed,
+// so they can be above 10 with DoubleTime and below 0 with HalfTime
+type Difficulty struct {
+	Mode Mode
+	// Rate is the playback speed, e.g. 1.5 for DoubleTime
+	Rate float64
+	AR   float64
+	OD   float64
+	CS   float64
+	HP   float64
+	// Preempt is how long in milliseconds an object is visible before it should be hit
+	Preempt    float64
+	HitWindows HitWindows
+	Bpm        float64
+	// HitLength and TotalLength are in seconds
+	HitLength   int
+	TotalLength int
+}
+
+// Difficulty returns the beatmap's difficulty settings with the mods applied
+func (b *Beatmap) Difficulty(mods Mods) Difficulty {
+	return b.DifficultyWithRate(mods, 0)
+}
+
+// DifficultyWithRate returns the beatmap's difficulty settings with the mods applied, played at a custom rate
+// as lazer's rate adjusting mods allow. A rate of 0 uses the rate implied by mods
+func (b *Beatmap) DifficultyWithRate(mods Mods, rate float64) Difficulty {
+	d := AdjustDifficulty(b.Mode, b.DiffApproach, b.DiffOverall, b.DiffSize, b.DiffDrain, mods, rate)
+	d.Bpm = b.Bpm * d.Rate
+	d.HitLength = int(math.Round(float64(b.HitLength) / d.Rate))
+	d.TotalLength = int(math.Round(float64(b.TotalLength) / d.Rate))
+	return d
+}
+
+// Rate returns the playback speed the mods imply: 1.5 with DoubleTime or Nightcore, 0.75 with HalfTime and 1 otherwise
+func (mods Mods) Rate() float64 {
+	switch {
+	case mods.HasAny(DoubleTime | Nightcore):
+		return 1.5
+	case mods.Has(HalfTime):
+		return 0.75
+	}
+	return 1
+}
+
+// AdjustDifficulty applies mods and a playback rate to the difficulty settings of a beatmap in the given mode.
+// A rate of 0 uses the rate implied by mods
+func AdjustDifficulty(m Mode, ar, od, cs, hp float64, mods Mods, rate float64) Difficulty {
+	if rate <= 0 {
+		rate = mods.Rate()
+	}
+	d := Difficulty{Mode: m, Rate: rate, AR: ar, OD: od, CS: cs, HP: hp}
+
+	// CS is the key count in mania and unused in taiko, and mania scales its hit windows instead of OD
+	switch {
+	case mods.Has(HardRock):
+		if m != ModeMania && m != ModeTaiko {
+			d.CS = math.Min(d.CS*1.3, 10)
+		}
+		d.AR = math.Min(d.AR*1.4, 10)
+		d.HP = math.Min(d.HP*1.4, 10)
+		if m != ModeMania {
+			d.OD = math.Min(d.OD*1.4, 10)
+		}
+	case mods.Has(Easy):
+		if m != ModeMania && m != ModeTaiko {
+			d.CS *= 0.5
+		}
+		d.AR *= 0.5
+		d.HP *= 0.5
+		if m != ModeMania {
+			d.OD *= 0.5
+		}
+	}
+
+	d.Preempt = arToMs(d.AR) / rate
+	d.AR = msToAR(d.Preempt)
+
+	switch m {
+	case ModeOsu:
+		d.HitWindows = HitWindows{
+			Great: difficultyRange(d.OD, 80, 50, 20) / rate,
+			Ok:    difficultyRange(d.OD, 140, 100, 60) / rate,
+			Meh:   difficultyRange(d.OD, 200, 150, 100) / rate,
+			Miss:  400 / rate,
+		}
+		d.OD = (80 - d.HitWindows.Great) / 6
+	case ModeTaiko:
+		d.HitWindows = HitWindows{
+			Great: difficultyRange(d.OD, 50, 35, 20) / rate,
+			Ok:    difficultyRange(d.OD, 120, 80, 50) / rate,
+			Miss:  difficultyRange(d.OD, 135, 95, 70) / rate,
+		}
+		d.OD = (50 - d.HitWindows.Great) / 3
+	case ModeMania:
+		// mania hit windows are measured in real time, so they don't change with rate
+		windows := 1.0
+		if mods.Has(HardRock) {
+			windows = 1 / 1.4
+		} else if mods.Has(Easy) {
+			windows = 1.4
+		}
+		d.HitWindows = HitWindows{
+			Perfect: 16 * windows,
+			Great:   (64 - 3*d.OD) * windows,
+			Good:    (97 - 3*d.OD) * windows,
+			Ok:      (127 - 3*d.OD) * windows,
+			Meh:     (151 - 3*d.OD) * windows,
+			Miss:    (188 - 3*d.OD) * windows,
+		}
+	}
+	return d
+}
+
+// difficultyRange maps a difficulty setting from 0-10 onto min-max, passing through mid at 5
+func difficultyRange(diff, min, mid, max float64) float64 {
+	switch {
+	case diff > 5:
+		return mid + (max-mid)*(diff-5)/5
+	case diff < 5:
+		return mid - (mid-min)*(5-diff)/5
+	}
+	return mid
+}
+
+// arToMs returns the preempt time for an approach rate
+func arToMs(ar float64) float64 {
+	return difficultyRange(ar, 1800, 1200, 450)
+}
+
+// msToAR returns the approach rate that has the given preempt time
+func msToAR(ms float64) float64 {
+	if ms > 1200 {
+		return (1800 - ms) / 120
+	}
+	return 5 + (1200-ms)/150
+}
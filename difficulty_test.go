@@ -0,0 +1,110 @@
+package osu
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 0.001
+}
+
+func TestAdjustDifficulty(t *testing.T) {
+	tests := []struct {
+		name               string
+		mode               Mode
+		ar, od, cs, hp     float64
+		mods               Mods
+		rate               float64
+		wantAR, wantOD     float64
+		wantCS, wantHP     float64
+		wantRate           float64
+		wantPreempt        float64
+		wantGreat, wantMeh float64
+	}{
+		{"nomod", ModeOsu, 9, 8, 4, 6, 0, 0, 9, 8, 4, 6, 1, 600, 32, 120},
+		{"DT", ModeOsu, 9, 8, 4, 6, Mods(DoubleTime), 0, 10.3333, 9.7778, 4, 6, 1.5, 400, 21.3333, 80},
+		{"NC", ModeOsu, 9, 8, 4, 6, Mods(Nightcore | DoubleTime), 0, 10.3333, 9.7778, 4, 6, 1.5, 400, 21.3333, 80},
+		{"HT", ModeOsu, 9, 8, 4, 6, Mods(HalfTime), 0, 7.6667, 6.2222, 4, 6, 0.75, 800, 42.6667, 160},
+		{"HR caps at 10", ModeOsu, 9, 8, 4, 6, Mods(HardRock), 0, 10, 10, 5.2, 8.4, 1, 450, 20, 100},
+		{"HR CS cap", ModeOsu, 5, 5, 8, 5, Mods(HardRock), 0, 7, 7, 10, 7, 1, 900, 38, 130},
+		{"HRDT", ModeOsu, 9, 8, 4, 6, Mods(HardRock | DoubleTime), 0, 11, 11.1111, 5.2, 8.4, 1.5, 300, 13.3333, 66.6667},
+		{"EZ", ModeOsu, 9, 8, 4, 6, Mods(Easy), 0, 4.5, 4, 2, 3, 1, 1260, 56, 160},
+		{"custom rate", ModeOsu, 9, 8, 4, 6, 0, 1.2, 9.6667, 8.8889, 4, 6, 1.2, 500, 26.6667, 100},
+		{"taiko", ModeTaiko, 5, 5, 5, 5, 0, 0, 5, 5, 5, 5, 1, 1200, 35, 0},
+		{"taiko DT", ModeTaiko, 5, 5, 5, 5, Mods(DoubleTime), 0, 7.6667, 8.8889, 5, 5, 1.5, 800, 23.3333, 0},
+		{"taiko HR keeps CS", ModeTaiko, 5, 5, 5, 5, Mods(HardRock), 0, 7, 7, 5, 7, 1, 900, 29, 0},
+		{"mania", ModeMania, 5, 8, 7, 5, 0, 0, 5, 8, 7, 5, 1, 1200, 40, 127},
+		{"mania DT keeps windows", ModeMania, 5, 8, 7, 5, Mods(DoubleTime), 0, 7.6667, 8, 7, 5, 1.5, 800, 40, 127},
+		{"mania HR", ModeMania, 5, 8, 7, 5, Mods(HardRock), 0, 7, 8, 7, 7, 1, 900, 28.5714, 90.7143},
+		{"mania EZ", ModeMania, 5, 8, 7, 5, Mods(Easy), 0, 2.5, 8, 7, 2.5, 1, 1500, 56, 177.8},
+	}
+	for _, tt := range tests {
+		d := AdjustDifficulty(tt.mode, tt.ar, tt.od, tt.cs, tt.hp, tt.mods, tt.rate)
+		if !near(d.AR, tt.wantAR) || !near(d.OD, tt.wantOD) || !near(d.CS, tt.wantCS) || !near(d.HP, tt.wantHP) {
+			t.Errorf("%s: got AR%.4f OD%.4f CS%.4f HP%.4f, want AR%.4f OD%.4f CS%.4f HP%.4f",
+				tt.name, d.AR, d.OD, d.CS, d.HP, tt.wantAR, tt.wantOD, tt.wantCS, tt.wantHP)
+		}
+		if d.Rate != tt.wantRate || !near(d.Preempt, tt.wantPreempt) {
+			t.Errorf("%s: got rate %v preempt %.4f, want %v %.4f", tt.name, d.Rate, d.Preempt, tt.wantRate, tt.wantPreempt)
+		}
+		if !near(d.HitWindows.Great, tt.wantGreat) || !near(d.HitWindows.Meh, tt.wantMeh) {
+			t.Errorf("%s: got great %.4f meh %.4f, want %.4f %.4f", tt.name, d.HitWindows.Great, d.HitWindows.Meh, tt.wantGreat, tt.wantMeh)
+		}
+	}
+}
+
+func TestHitWindows(t *testing.T) {
+	tests := []struct {
+		mode Mode
+		od   float64
+		mods Mods
+		want HitWindows
+	}{
+		{ModeOsu, 5, 0, HitWindows{Great: 50, Ok: 100, Meh: 150, Miss: 400}},
+		{ModeOsu, 10, 0, HitWindows{Great: 20, Ok: 60, Meh: 100, Miss: 400}},
+		{ModeOsu, 0, 0, HitWindows{Great: 80, Ok: 140, Meh: 200, Miss: 400}},
+		{ModeOsu, 5, Mods(DoubleTime), HitWindows{Great: 33.3333, Ok: 66.6667, Meh: 100, Miss: 266.6667}},
+		{ModeTaiko, 5, 0, HitWindows{Great: 35, Ok: 80, Miss: 95}},
+		{ModeTaiko, 10, 0, HitWindows{Great: 20, Ok: 50, Miss: 70}},
+		{ModeTaiko, 0, 0, HitWindows{Great: 50, Ok: 120, Miss: 135}},
+		{ModeMania, 8, 0, HitWindows{Perfect: 16, Great: 40, Good: 73, Ok: 103, Meh: 127, Miss: 164}},
+		{ModeMania, 0, Mods(HalfTime), HitWindows{Perfect: 16, Great: 64, Good: 97, Ok: 127, Meh: 151, Miss: 188}},
+		{ModeMania, 8, Mods(Easy), HitWindows{Perfect: 22.4, Great: 56, Good: 102.2, Ok: 144.2, Meh: 177.8, Miss: 229.6}},
+	}
+	for _, tt := range tests {
+		got := AdjustDifficulty(tt.mode, 5, tt.od, 5, 5, tt.mods, 0).HitWindows
+		if !near(got.Perfect, tt.want.Perfect) || !near(got.Great, tt.want.Great) || !near(got.Good, tt.want.Good) ||
+			!near(got.Ok, tt.want.Ok) || !near(got.Meh, tt.want.Meh) || !near(got.Miss, tt.want.Miss) {
+			t.Errorf("%v OD%v %s: got %+v, want %+v", tt.mode, tt.od, tt.mods.Acronyms(), got, tt.want)
+		}
+	}
+}
+
+func TestBeatmapDifficulty(t *testing.T) {
+	b := Beatmap{Mode: ModeOsu, Bpm: 180, HitLength: 90, TotalLength: 100, DiffApproach: 9, DiffOverall: 8, DiffSize: 4, DiffDrain: 6}
+	tests := []struct {
+		mods       Mods
+		rate       float64
+		bpm        float64
+		hit, total int
+		wantAR     float64
+	}{
+		{0, 0, 180, 90, 100, 9},
+		{Mods(DoubleTime), 0, 270, 60, 67, 10.3333},
+		{Mods(HalfTime), 0, 135, 120, 133, 7.6667},
+		{0, 1.2, 216, 75, 83, 9.6667},
+		// an explicit rate overrides the one the mods imply
+		{Mods(DoubleTime), 1.2, 216, 75, 83, 9.6667},
+	}
+	for _, tt := range tests {
+		d := b.DifficultyWithRate(tt.mods, tt.rate)
+		if !near(d.Bpm, tt.bpm) || d.HitLength != tt.hit || d.TotalLength != tt.total || !near(d.AR, tt.wantAR) {
+			t.Errorf("%s x%v: got bpm %v lengths %d/%d AR%.4f, want %v %d/%d AR%.4f",
+				tt.mods.Acronyms(), tt.rate, d.Bpm, d.HitLength, d.TotalLength, d.AR, tt.bpm, tt.hit, tt.total, tt.wantAR)
+		}
+	}
+	if d := b.Difficulty(Mods(DoubleTime)); d.Rate != 1.5 || d.HitLength != 60 {
+		t.Errorf("Difficulty(DT): got rate %v hit length %d", d.Rate, d.HitLength)
+	}
+}
@@ -1,10 +1,12 @@
 package osu
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"net/url"
 	"regexp"
 	"time"
@@ -30,9 +32,9 @@ func BeatmapsLimit(limit int) BeatmapOption {
 }
 
 // BeatmapsWithMode specifies which mode to confine results to
-func BeatmapsWithMode(mode mode) BeatmapOption {
+func BeatmapsWithMode(m Mode) BeatmapOption {
 	return func(s string) string {
-		return s + fmt.Sprintf("&m=%d", mode)
+		return s + fmt.Sprintf("&m=%d", m)
 	}
 }
 
@@ -44,26 +46,31 @@ func BeatmapsWithHash(ID string) BeatmapOption {
 }
 
 // BeatmapsWithID confines the search to beatmaps with a specific BeatmapID
-func BeatmapsWithID(ID string) BeatmapOption {
+func BeatmapsWithID(ID BeatmapID) BeatmapOption {
 	return func(s string) string {
-		return s + fmt.Sprintf("&b=%v", url.QueryEscape(ID))
+		return s + fmt.Sprintf("&b=%d", ID)
 	}
 }
 
 // BeatmapsWithSetID confines the search to beatmaps with a specific BeatmapsetID
-func BeatmapsWithSetID(ID string) BeatmapOption {
+func BeatmapsWithSetID(ID BeatmapsetID) BeatmapOption {
 	return func(s string) string {
-		return s + fmt.Sprintf("&s=%v", url.QueryEscape(ID))
+		return s + fmt.Sprintf("&s=%d", ID)
 	}
 }
 
 // BeatmapsByCreator confines the search to beatmaps created by a specific user
-func BeatmapsByCreator(ID string, IDType usernameType) BeatmapOption {
+func BeatmapsByCreator(ID string, IDType UsernameType) BeatmapOption {
 	return func(s string) string {
 		return s + fmt.Sprintf("&u=%v&type=%v", url.QueryEscape(ID), url.QueryEscape(string(IDType)))
 	}
 }
 
+// BeatmapsByCreatorID confines the search to beatmaps created by the user with the given ID
+func BeatmapsByCreatorID(ID UserID) BeatmapOption {
+	return BeatmapsByCreator(ID.String(), UsernameTypeID)
+}
+
 // BeatmapsSince confines the search to beatmaps ranked or loved since date
 func BeatmapsSince(date time.Time) BeatmapOption {
 	return func(s string) string {
@@ -77,33 +84,45 @@ func (client *Client) Beatmaps(opts ...BeatmapOption) ([]*Beatmap, error) {
 	for _, opt := range opts {
 		query = opt(query)
 	}
-	resp, err := client.c.Get(query)
+	maps, err := client.beatmaps(context.Background(), query)
 	if err != nil {
 		return nil, errors.New("osu.Client.Beatmaps: " + err.Error())
 	}
+	return maps, nil
+}
+
+func (client *Client) beatmaps(ctx context.Context, query string) ([]*Beatmap, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.c.Do(req)
+	if err != nil {
+		return nil, err
+	}
 	defer resp.Body.Close()
 	if resp.StatusCode != 200 {
-		return nil, errors.New("osu.Client.Beatmaps: " + resp.Status)
+		return nil, errors.New(resp.Status)
 	}
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, errors.New("osu.Client.Beatmaps: " + err.Error())
+		return nil, err
 	}
 	err = errorCheck(body)
 	if err != nil {
-		return nil, errors.New("osu.Client.Beatmaps: " + err.Error())
+		return nil, err
 	}
-	var regx = regexp.MustCompile(`(date"[[:space:]]*:[[:space:]]*"[0-9]{4}-[0-9]{2}-[0-9]{2}) ([0-9]{2}:[0-9]{2}:[0-9]{2})"`)
+	var regx = regexp.MustCompile(`((?:date|update)"[[:space:]]*:[[:space:]]*"[0-9]{4}-[0-9]{2}-[0-9]{2}) ([0-9]{2}:[0-9]{2}:[0-9]{2})"`)
 	body = regx.ReplaceAll(body, []byte(`${1}T${2}-00:00"`))
 	maps := make([]*Beatmap, 0)
-	err = json.Unmarshal(body, &maps)
+	err = client.decode(body, &maps)
 	return maps, err
 }
 
 // UserMode specifies which mode to show info for in the User struct (default is Osu)
-func UserMode(mode mode) UserOption {
+func UserMode(m Mode) UserOption {
 	return func(s string) string {
-		return s + fmt.Sprintf("&m=%d", mode)
+		return s + fmt.Sprintf("&m=%d", m)
 	}
 }
 
@@ -120,7 +139,7 @@ func UserEventsSince(days int) UserOption {
 }
 
 // User fetches information for a specific user
-func (client *Client) User(ID string, IDType usernameType, opts ...UserOption) (*User, error) {
+func (client *Client) User(ID string, IDType UsernameType, opts ...UserOption) (*User, error) {
 	query := apiURL + "get_user?k=" + client.key
 	query = BeatmapsByCreator(ID, IDType)(query)
 	for _, opt := range opts {
@@ -145,7 +164,7 @@ func (client *Client) User(ID string, IDType usernameType, opts ...UserOption) (
 	var regx = regexp.MustCompile(`(date"[[:space:]]*:[[:space:]]*"[0-9]{4}-[0-9]{2}-[0-9]{2}) ([0-9]{2}:[0-9]{2}:[0-9]{2})"`)
 	body = regx.ReplaceAll(body, []byte(`${1}T${2}-00:00"`))
 	user := make([]*User, 0)
-	err = json.Unmarshal(body, &user)
+	err = client.decode(body, &user)
 	if len(user) == 1 {
 		return user[0], err
 	}
@@ -153,24 +172,29 @@ func (client *Client) User(ID string, IDType usernameType, opts ...UserOption) (
 }
 
 // ScoresWithMode confines results to those with the specified mode
-func ScoresWithMode(m mode) ScoresOption {
+func ScoresWithMode(m Mode) ScoresOption {
 	return func(s string) string {
 		return s + fmt.Sprintf("&m=%d", m)
 	}
 }
 
 // ScoresByUser confines results to just scores from the specific user
-func ScoresByUser(ID string, IDType usernameType) ScoresOption {
+func ScoresByUser(ID string, IDType UsernameType) ScoresOption {
 	return func(s string) string {
 		return s + fmt.Sprintf("&u=%v&type=%v", url.QueryEscape(ID), url.QueryEscape(string(IDType)))
 	}
 }
 
+// ScoresByUserID confines results to just scores from the user with the given ID
+func ScoresByUserID(ID UserID) ScoresOption {
+	return ScoresByUser(ID.String(), UsernameTypeID)
+}
+
 // ScoresWithMods confines results to those that have the given mods
 func ScoresWithMods(mods ...Mod) ScoresOption {
-	m := 0
+	var m Mods
 	for _, v := range mods {
-		m |= int(v)
+		m = m.With(v)
 	}
 	return func(s string) string {
 		return s + fmt.Sprintf("&mods=%d", m)
@@ -190,7 +214,7 @@ func ScoresLimit(limit int) ScoresOption {
 }
 
 // Scores fetches a list of Scores for a specific beatmap
-func (client *Client) Scores(ID string, opts ...ScoresOption) ([]*Score, error) {
+func (client *Client) Scores(ID BeatmapID, opts ...ScoresOption) ([]*Score, error) {
 	query := apiURL + "get_scores?k=" + client.key
 	query = BeatmapsWithID(ID)(query)
 	for _, opt := range opts {
@@ -215,7 +239,7 @@ func (client *Client) Scores(ID string, opts ...ScoresOption) ([]*Score, error)
 	var regx = regexp.MustCompile(`(date"[[:space:]]*:[[:space:]]*"[0-9]{4}-[0-9]{2}-[0-9]{2}) ([0-9]{2}:[0-9]{2}:[0-9]{2})"`)
 	body = regx.ReplaceAll(body, []byte(`${1}T${2}-00:00"`))
 	scores := make([]*Score, 0)
-	err = json.Unmarshal(body, &scores)
+	err = client.decode(body, &scores)
 	return scores, err
 }
 
@@ -232,14 +256,14 @@ func UserBestLimit(limit int) UserBestOption {
 }
 
 // UserBestWithMode confines results to those with the specified mode
-func UserBestWithMode(m mode) UserBestOption {
+func UserBestWithMode(m Mode) UserBestOption {
 	return func(s string) string {
 		return s + fmt.Sprintf("&m=%d", m)
 	}
 }
 
 // UserBest returns a list of the top scores for the specified user
-func (client *Client) UserBest(ID string, IDType usernameType, opts ...UserBestOption) ([]*BestScore, error) {
+func (client *Client) UserBest(ID string, IDType UsernameType, opts ...UserBestOption) ([]*BestScore, error) {
 	query := apiURL + "get_user_best?k=" + client.key
 	query = BeatmapsByCreator(ID, IDType)(query)
 	for _, opt := range opts {
@@ -264,7 +288,7 @@ func (client *Client) UserBest(ID string, IDType usernameType, opts ...UserBestO
 	var regx = regexp.MustCompile(`(date"[[:space:]]*:[[:space:]]*"[0-9]{4}-[0-9]{2}-[0-9]{2}) ([0-9]{2}:[0-9]{2}:[0-9]{2})"`)
 	body = regx.ReplaceAll(body, []byte(`${1}T${2}-00:00"`))
 	scores := make([]*BestScore, 0)
-	err = json.Unmarshal(body, &scores)
+	err = client.decode(body, &scores)
 	return scores, err
 }
 
@@ -281,14 +305,14 @@ func UserRecentLimit(limit int) UserRecentOption {
 }
 
 // UserRecentWithMode confines results to those with the specified mode
-func UserRecentWithMode(m mode) UserRecentOption {
+func UserRecentWithMode(m Mode) UserRecentOption {
 	return func(s string) string {
 		return s + fmt.Sprintf("&m=%d", m)
 	}
 }
 
 // UserRecent returns a list of the top scores for the specified user
-func (client *Client) UserRecent(ID string, IDType usernameType, opts ...UserRecentOption) ([]*RecentScore, error) {
+func (client *Client) UserRecent(ID string, IDType UsernameType, opts ...UserRecentOption) ([]*RecentScore, error) {
 	query := apiURL + "get_user_recent?k=" + client.key
 	query = BeatmapsByCreator(ID, IDType)(query)
 	for _, opt := range opts {
@@ -313,15 +337,15 @@ func (client *Client) UserRecent(ID string, IDType usernameType, opts ...UserRec
 	var regx = regexp.MustCompile(`(date"[[:space:]]*:[[:space:]]*"[0-9]{4}-[0-9]{2}-[0-9]{2}) ([0-9]{2}:[0-9]{2}:[0-9]{2})"`)
 	body = regx.ReplaceAll(body, []byte(`${1}T${2}-00:00"`))
 	scores := make([]*RecentScore, 0)
-	err = json.Unmarshal(body, &scores)
+	err = client.decode(body, &scores)
 	return scores, err
 }
 
 // ReplayWithMods confines the result to a replay with the given mods
 func ReplayWithMods(mods ...Mod) ReplayOption {
-	m := 0
+	var m Mods
 	for _, v := range mods {
-		m |= int(v)
+		m = m.With(v)
 	}
 	return func(s string) string {
 		return s + fmt.Sprintf("&mods=%d", m)
@@ -329,11 +353,11 @@ func ReplayWithMods(mods ...Mod) ReplayOption {
 }
 
 // Replay returns the data for the given beatmap, played by the specified user in the specified mode
-func (client *Client) Replay(mode mode, beatmapID string, userID string, opts ...ReplayOption) (*Replay, error) {
+func (client *Client) Replay(m Mode, beatmapID BeatmapID, userID UserID, opts ...ReplayOption) (*Replay, error) {
 	query := apiURL + "get_replay?k=" + client.key
-	query = BeatmapsWithMode(mode)(query)
+	query = BeatmapsWithMode(m)(query)
 	query = BeatmapsWithID(beatmapID)(query)
-	query += "&u=" + userID
+	query += fmt.Sprintf("&u=%d", userID)
 	for _, opt := range opts {
 		query = opt(query)
 	}
@@ -355,14 +379,65 @@ func (client *Client) Replay(mode mode, beatmapID string, userID string, opts ..
 	}
 	var regx = regexp.MustCompile(`(date"[[:space:]]*:[[:space:]]*"[0-9]{4}-[0-9]{2}-[0-9]{2}) ([0-9]{2}:[0-9]{2}:[0-9]{2})"`)
 	body = regx.ReplaceAll(body, []byte(`${1}T${2}-00:00"`))
-	var replay Replay
-	err = json.Unmarshal(body, &replay)
-	return &replay, err
+	// decoded through the client so that strictness applies to the response's fields
+	var raw replayJSON
+	if err := client.decode(body, &raw); err != nil {
+		return nil, errors.New("osu.Client.Replay: " + err.Error())
+	}
+	replay := new(Replay)
+	if err := replay.setContent(raw.Content); err != nil {
+		return nil, errors.New("osu.Client.Replay: " + err.Error())
+	}
+	if client.strict && len(replay.Skipped) > 0 {
+		return nil, errors.New("osu.Client.Replay: " + replay.Skipped[0].Error())
+	}
+	return replay, nil
+}
+
+// ReplayFile fetches a replay along with its score and beatmap, and builds a complete replay that can be played in osu!.
+// Without mods, the user's best score on the beatmap is used
+func (client *Client) ReplayFile(m Mode, beatmapID BeatmapID, userID UserID, mods ...Mod) (*ReplayFile, error) {
+	var replayOpts []ReplayOption
+	scoreOpts := []ScoresOption{ScoresWithMode(m), ScoresByUserID(userID)}
+	if len(mods) > 0 {
+		replayOpts = append(replayOpts, ReplayWithMods(mods...))
+		scoreOpts = append(scoreOpts, ScoresWithMods(mods...))
+	}
+	replay, err := client.Replay(m, beatmapID, userID, replayOpts...)
+	if err != nil {
+		return nil, errors.New("osu.Client.ReplayFile: " + err.Error())
+	}
+	scores, err := client.Scores(beatmapID, scoreOpts...)
+	if err != nil {
+		return nil, errors.New("osu.Client.ReplayFile: " + err.Error())
+	}
+	if len(scores) == 0 {
+		return nil, errors.New("osu.Client.ReplayFile: score not found")
+	}
+	beatmaps, err := client.Beatmaps(BeatmapsWithID(beatmapID), BeatmapsWithMode(m), BeatmapsIncludeConverted())
+	if err != nil {
+		return nil, errors.New("osu.Client.ReplayFile: " + err.Error())
+	}
+	if len(beatmaps) == 0 {
+		return nil, errors.New("osu.Client.ReplayFile: beatmap not found")
+	}
+	rf := NewReplayFile(replay, scores[0], beatmaps[0], "")
+	rf.Mode = m
+	return rf, nil
+}
+
+// SaveReplay fetches a replay with ReplayFile and writes it to an .osr file at path
+func (client *Client) SaveReplay(path string, m Mode, beatmapID BeatmapID, userID UserID, mods ...Mod) error {
+	rf, err := client.ReplayFile(m, beatmapID, userID, mods...)
+	if err != nil {
+		return err
+	}
+	return rf.WriteFile(path)
 }
 
 // Match fetches a multiplayer match with the given ID
-func (client *Client) Match(matchID string) (*Match, error) {
-	query := apiURL + "get_match?k=" + client.key + "&mp=" + matchID
+func (client *Client) Match(matchID MatchID) (*Match, error) {
+	query := apiURL + "get_match?k=" + client.key + fmt.Sprintf("&mp=%d", matchID)
 	resp, err := client.c.Get(query)
 	if err != nil {
 		return nil, errors.New("osu.Client.Match: " + err.Error())
@@ -381,14 +456,19 @@ func (client *Client) Match(matchID string) (*Match, error) {
 	}
 	var regx = regexp.MustCompile(`(time"[[:space:]]*:[[:space:]]*"[0-9]{4}-[0-9]{2}-[0-9]{2}) ([0-9]{2}:[0-9]{2}:[0-9]{2})"`)
 	body = regx.ReplaceAll(body, []byte(`${1}T${2}-00:00"`))
-	match := make([]*Match, 0)
-	fmt.Println(string(body))
-	err = json.Unmarshal(body, &match)
-	if err != nil {
+	// the API sends "match":0 for matches that don't exist
+	var found struct {
+		Match json.RawMessage `json:"match"`
+	}
+	if err := json.Unmarshal(body, &found); err != nil {
+		return nil, errors.New("osu.Client.Match: " + err.Error())
+	}
+	if len(found.Match) == 0 || string(found.Match) == "0" {
 		return nil, errors.New("osu.Client.Match: No matches found")
 	}
-	if len(match) == 1 {
-		return match[0], err
+	var match Match
+	if err := client.decode(body, &match); err != nil {
+		return nil, errors.New("osu.Client.Match: " + err.Error())
 	}
-	return nil, errors.New("osu.Client.Match: No matches found")
+	return &match, nil
 }
@@ -0,0 +1,233 @@
+package osu
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// the responses are the examples of the API documentation, with the fields added to it since
+const (
+	beatmapsResponse = `[{"approved":"1","submit_date":"2013-05-15 11:32:26","approved_date":"2013-07-06 08:54:46","last_update":"2013-07-06 08:51:22",
+		"artist":"Luxion","artist_unicode":null,"beatmap_id":"252002","beatmapset_id":"93398","bpm":"196","creator":"RikiH_","creator_id":"686209",
+		"difficultyrating":"5.744717597961426","diff_aim":"2.7706098556518555","diff_speed":"2.9062750339508057","diff_size":"4","diff_overall":"8",
+		"diff_approach":"9","diff_drain":"7","hit_length":"114","source":"BMS","genre_id":"2","language_id":"5","title":"High-Priestess",
+		"title_unicode":null,"total_length":"146","version":"Overkill","file_md5":"c8f08438204abfcdd1a748ebfae67421","mode":"0",
+		"tags":"kloyd flower roxas","favourite_count":"140","rating":"9.44779","playcount":"94637","passcount":"10599","count_normal":"388",
+		"count_slider":"222","count_spinner":"3","max_combo":"899","storyboard":"0","video":"1","download_unavailable":"0","audio_unavailable":"0","packs":"S34,T36"}]`
+	userResponse = `[{"user_id":"1","username":"User name","join_date":"2008-10-09 06:30:59","count300":"1337","count100":"123","count50":"69",
+		"playcount":"42","ranked_score":"666666","total_score":"999999998","pp_rank":"2442","level":"50.5050","pp_raw":"3113","accuracy":"98.1234",
+		"count_rank_ss":"54","count_rank_ssh":"54","count_rank_s":"81","count_rank_sh":"81","count_rank_a":"862","country":"DE",
+		"total_seconds_played":"12345","pp_country_rank":"1337","events":[{"display_html":"<b>achieved rank #1<\/b>","beatmap_id":"222342",
+		"beatmapset_id":"54851","date":"2013-07-07 22:34:04","epicfactor":"1"}]}]`
+	scoresResponse = `[{"score_id":"7654321","score":"1234567","username":"User name","count300":"300","count100":"50","count50":"10","countmiss":"1",
+		"maxcombo":"321","countkatu":"10","countgeki":"50","perfect":"0","enabled_mods":"76","user_id":"1","date":"2013-06-22 09:11:16","rank":"SH",
+		"pp":"1.3019","replay_available":"1"}]`
+	bestResponse = `[{"beatmap_id":"123","score_id":"7654321","score":"1234567","maxcombo":"421","count50":"10","count100":"50","count300":"300",
+		"countmiss":"1","countkatu":"10","countgeki":"50","perfect":"1","enabled_mods":"76","user_id":"1","date":"2013-06-22 09:11:16","rank":"SH",
+		"pp":"1.3019","replay_available":"0"}]`
+	recentResponse = `[{"beatmap_id":"123","score":"1234567","maxcombo":"421","count50":"10","count100":"50","count300":"300","countmiss":"1",
+		"countkatu":"10","countgeki":"50","perfect":"0","enabled_mods":"76","user_id":"1","date":"2013-06-22 09:11:16","rank":"F"}]`
+	matchResponse = `{"match":{"match_id":"1936471","name":"Marcin vs. Tim","start_time":"2015-04-24 17:34:00","end_time":null},
+		"games":[{"game_id":"12345","start_time":"2015-04-24 17:40:00","end_time":"2015-04-24 17:43:00","beatmap_id":"252002","play_mode":"0",
+		"match_type":"0","scoring_type":"3","team_type":"2","mods":"8","scores":[{"slot":"0","team":"1","user_id":"1","score":"1000000",
+		"maxcombo":"899","rank":"0","count50":"0","count100":"2","count300":"611","countmiss":"0","countgeki":"100","countkatu":"2",
+		"perfect":"1","pass":"1","enabled_mods":"16"}]}]}`
+)
+
+// strictClient returns a strict client whose requests are answered with body
+func strictClient(t *testing.T, body string) *Client {
+	client := NewClient("key")
+	client.SetStrict(true)
+	client.c.Transport = serve(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("k") != "key" {
+			t.Errorf("got query %q", r.URL.RawQuery)
+		}
+		w.Write([]byte(body))
+	})
+	return client
+}
+
+func TestClientBeatmaps(t *testing.T) {
+	maps, err := strictClient(t, beatmapsResponse).Beatmaps()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := maps[0]
+	if b.BeatmapID != 252002 || b.BeatmapsetID != 93398 || b.CreatorID != 686209 || b.Approved != StatusRanked || b.Mode != ModeOsu {
+		t.Errorf("got IDs %d, %d, %d, status %v and mode %v", b.BeatmapID, b.BeatmapsetID, b.CreatorID, b.Approved, b.Mode)
+	}
+	if !b.ApprovedDate.Equal(time.Date(2013, 7, 6, 8, 54, 46, 0, time.UTC)) || !b.LastUpdate.Equal(time.Date(2013, 7, 6, 8, 51, 22, 0, time.UTC)) {
+		t.Errorf("got dates %v and %v", b.ApprovedDate, b.LastUpdate)
+	}
+	if b.MaxCombo != 899 || b.CountNormal != 388 || b.CountSlider != 222 || b.CountSpinner != 3 || b.HitLength != 114 || b.TotalLength != 146 {
+		t.Errorf("got combo %d, counts %d, %d, %d and lengths %d, %d", b.MaxCombo, b.CountNormal, b.CountSlider, b.CountSpinner, b.HitLength, b.TotalLength)
+	}
+	if b.Storyboard || !b.Video || b.DownloadUnavailable || b.AudioUnavailable {
+		t.Errorf("got flags %v, %v, %v, %v", b.Storyboard, b.Video, b.DownloadUnavailable, b.AudioUnavailable)
+	}
+	if len(b.PackList()) != 2 || b.PackList()[1] != "T36" {
+		t.Errorf("got packs %q", b.PackList())
+	}
+}
+
+func TestClientUser(t *testing.T) {
+	u, err := strictClient(t, userResponse).User("1", UsernameTypeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.UserID != 1 || u.TotalHits() != 1529 || u.TotalRanks() != 1132 || len(u.Events) != 1 || u.Events[0].BeatmapID != 222342 || u.Events[0].Epicfactor != 1 {
+		t.Errorf("got %+v", u)
+	}
+}
+
+func TestClientScores(t *testing.T) {
+	scores, err := strictClient(t, scoresResponse).Scores(252002)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := scores[0]
+	if s.ScoreID != 7654321 || s.EnabledMods != Mods(TouchDevice|Hidden|DoubleTime) || s.Rank != RankSH || s.Perfect || !s.ReplayAvailable {
+		t.Errorf("got %+v", s)
+	}
+	best, err := strictClient(t, bestResponse).UserBest("1", UsernameTypeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if best[0].BeatmapID != 123 || !best[0].Perfect || best[0].ReplayAvailable || best[0].Pp != 1.3019 {
+		t.Errorf("got %+v", best[0])
+	}
+	recent, err := strictClient(t, recentResponse).UserRecent("1", UsernameTypeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recent[0].Rank != RankF || recent[0].Maxcombo != 421 {
+		t.Errorf("got %+v", recent[0])
+	}
+}
+
+func TestClientMatch(t *testing.T) {
+	m, err := strictClient(t, matchResponse).Match(1936471)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Match.MatchID != 1936471 || m.Match.EndTime != nil || len(m.Games) != 1 || len(m.Games[0].Scores) != 1 {
+		t.Fatalf("got %+v", m)
+	}
+	g, s := m.Games[0], m.Games[0].Scores[0]
+	if g.Mods != Mods(Hidden) || g.TeamType != TeamTypeTeamVs || s.EnabledMods != Mods(HardRock) || !s.Pass || !s.Perfect || s.Rank != RankNone {
+		t.Errorf("got game %+v and score %+v", g, s)
+	}
+	if _, err := strictClient(t, `{"match":0,"games":[]}`).Match(1); err == nil {
+		t.Error("no error for a missing match")
+	}
+}
+
+func TestClientStrict(t *testing.T) {
+	body := `[{"beatmap_id":"1","new_field":"1"}]`
+	if _, err := strictClient(t, body).Beatmaps(); err == nil {
+		t.Error("strict client accepted an unknown field")
+	}
+	client := strictClient(t, body)
+	client.SetStrict(false)
+	if maps, err := client.Beatmaps(); err != nil || maps[0].BeatmapID != 1 {
+		t.Errorf("got %v, %v", maps, err)
+	}
+}
+
+// replayResponse returns a get_replay response holding the frames in data, after extra fields
+func replayResponse(t *testing.T, data, extra string) string {
+	t.Helper()
+	compressed, err := compressLZMA([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return `{` + extra + `"content":"` + base64.StdEncoding.EncodeToString(compressed) + `","encoding":"base64"}`
+}
+
+func TestClientReplay(t *testing.T) {
+	frames := "16|1|2|5,bad,16|3|4|0,"
+	client := strictClient(t, replayResponse(t, frames, ""))
+	_, err := client.Replay(ModeOsu, 1, 1)
+	if err == nil || err.Error() != `osu.Client.Replay: osu: replay frame 1: expected 4 values: "bad"` {
+		t.Errorf("strict client: got %v", err)
+	}
+	client.SetStrict(false)
+	replay, err := client.Replay(ModeOsu, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replay.Content) != 2 || replay.Content[1].Time != 32*time.Millisecond || len(replay.Skipped) != 1 {
+		t.Errorf("got %d frames and skipped %v", len(replay.Content), replay.Skipped)
+	}
+
+	body := replayResponse(t, "16|1|2|5,", `"new_field":"1",`)
+	if _, err := strictClient(t, body).Replay(ModeOsu, 1, 1); err == nil {
+		t.Error("strict client accepted an unknown field")
+	}
+	client = strictClient(t, body)
+	client.SetStrict(false)
+	if replay, err := client.Replay(ModeOsu, 1, 1); err != nil || len(replay.Content) != 1 {
+		t.Errorf("got %v, %v", replay, err)
+	}
+}
+
+func TestReplayJSON(t *testing.T) {
+	var replay Replay
+	if err := json.Unmarshal([]byte(replayResponse(t, "16|1|2|5,bad,", "")), &replay); err != nil {
+		t.Fatal(err)
+	}
+	if len(replay.Content) != 1 || len(replay.Skipped) != 1 {
+		t.Errorf("got %d frames and skipped %v", len(replay.Content), replay.Skipped)
+	}
+	// ReplayContent on its own is strict
+	data, err := json.Marshal(replay.Content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var content ReplayContent
+	if err := json.Unmarshal(data, &content); err != nil || len(content) != 1 || *content[0] != *replay.Content[0] {
+		t.Errorf("got %v, %v", content, err)
+	}
+	compressed, err := compressLZMA([]byte("16|1|2|5,bad,"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`"`+base64.StdEncoding.EncodeToString(compressed)+`"`), &content); err == nil {
+		t.Error("no error for a malformed frame")
+	}
+}
+
+func TestClientError(t *testing.T) {
+	if _, err := strictClient(t, `{"error":"Please provide a valid API key."}`).Scores(1); err == nil || err.Error() != "osu.Client.Scores: Please provide a valid API key." {
+		t.Errorf("got error %v", err)
+	}
+}
+
+func TestFlag(t *testing.T) {
+	tests := []struct {
+		json string
+		want Flag
+		err  bool
+	}{
+		{`"1"`, true, false},
+		{`"0"`, false, false},
+		{`true`, true, false},
+		{`false`, false, false},
+		{`""`, false, false},
+		{`null`, false, false},
+		{`"2"`, false, true},
+		{`"yes"`, false, true},
+	}
+	for _, tt := range tests {
+		var got struct {
+			F Flag `json:"f"`
+		}
+		err := json.Unmarshal([]byte(`{"f":`+tt.json+`}`), &got)
+		if (err != nil) != tt.err || got.F != tt.want {
+			t.Errorf("%s: got %v, %v, want %v", tt.json, got.F, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package osu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// CommentsOn confines the listing to comments on a specific beatmapset, news post or build
+func CommentsOn(kind CommentableType, ID int64) CommentsOption {
+	return func(s string) string {
+		return s + fmt.Sprintf("&commentable_type=%v&commentable_id=%d", url.QueryEscape(string(kind)), ID)
+	}
+}
+
+// CommentsWithParent confines the listing to replies of the given comment
+func CommentsWithParent(ID int64) CommentsOption {
+	return func(s string) string {
+		return s + fmt.Sprintf("&parent_id=%d", ID)
+	}
+}
+
+// CommentsSort specifies the order comments are listed in (default is CommentSortNew)
+func CommentsSort(sort CommentSort) CommentsOption {
+	return func(s string) string {
+		return s + fmt.Sprintf("&sort=%v", url.QueryEscape(string(sort)))
+	}
+}
+
+// CommentsAfter continues a listing from the cursor returned with a previous page
+func CommentsAfter(cursor CommentCursor) CommentsOption {
+	return func(s string) string {
+		for k, v := range cursor {
+			var str string
+			if json.Unmarshal(v, &str) != nil {
+				str = string(v)
+			}
+			s += fmt.Sprintf("&%v=%v", url.QueryEscape("cursor["+k+"]"), url.QueryEscape(str))
+		}
+		return s
+	}
+}
+
+// Comments fetches a page of comments
+func (client *ClientV2) Comments(opts ...CommentsOption) (*CommentBundle, error) {
+	query := apiV2URL + "comments?"
+	for _, opt := range opts {
+		query = opt(query)
+	}
+	var bundle CommentBundle
+	err := client.do(context.Background(), http.MethodGet, query, nil, &bundle)
+	if err != nil {
+		return nil, errors.New("osu.ClientV2.Comments: " + err.Error())
+	}
+	return &bundle, nil
+}
+
+// Comment fetches a comment along with a page of its replies
+func (client *ClientV2) Comment(ID int64) (*CommentBundle, error) {
+	query := apiV2URL + fmt.Sprintf("comments/%d", ID)
+	var bundle CommentBundle
+	err := client.do(context.Background(), http.MethodGet, query, nil, &bundle)
+	if err != nil {
+		return nil, errors.New("osu.ClientV2.Comment: " + err.Error())
+	}
+	return &bundle, nil
+}
+
+// ForumTopicSort specifies the order posts are listed in (default is TopicSortOldest)
+func ForumTopicSort(sort TopicSort) ForumTopicOption {
+	return func(s string) string {
+		return s + fmt.Sprintf("&sort=%v", url.QueryEscape(string(sort)))
+	}
+}
+
+// ForumTopicLimit specifies how many posts to return (default 20, max 50)
+func ForumTopicLimit(limit int) ForumTopicOption {
+	if limit < 1 {
+		limit = 1
+	} else if limit > 50 {
+		limit = 50
+	}
+	return func(s string) string {
+		return s + fmt.Sprintf("&limit=%d", limit)
+	}
+}
+
+// ForumTopicStart lists posts starting from the given post ID
+func ForumTopicStart(postID int64) ForumTopicOption {
+	return func(s string) string {
+		return s + fmt.Sprintf("&start=%d", postID)
+	}
+}
+
+// ForumTopicEnd lists posts ending at the given post ID
+func ForumTopicEnd(postID int64) ForumTopicOption {
+	return func(s string) string {
+		return s + fmt.Sprintf("&end=%d", postID)
+	}
+}
+
+// ForumTopicAfter continues a listing from the cursor returned with a previous page
+func ForumTopicAfter(cursor string) ForumTopicOption {
+	return func(s string) string {
+		return s + fmt.Sprintf("&cursor_string=%v", url.QueryEscape(cursor))
+	}
+}
+
+// ForumTopic fetches a forum topic along with a page of its posts
+func (client *ClientV2) ForumTopic(topicID int64, opts ...ForumTopicOption) (*ForumTopicPosts, error) {
+	query := apiV2URL + fmt.Sprintf("forums/topics/%d?", topicID)
+	for _, opt := range opts {
+		query = opt(query)
+	}
+	var topic ForumTopicPosts
+	err := client.do(context.Background(), http.MethodGet, query, nil, &topic)
+	if err != nil {
+		return nil, errors.New("osu.ClientV2.ForumTopic: " + err.Error())
+	}
+	return &topic, nil
+}
+
+// CreateForumTopic opens a new topic in the given forum. Requires the forum.write scope
+func (client *ClientV2) CreateForumTopic(forumID int64, title, body string) (*NewForumTopic, error) {
+	payload := map[string]interface{}{
+		"forum_id": forumID,
+		"title":    title,
+		"body":     body,
+	}
+	var topic NewForumTopic
+	err := client.do(context.Background(), http.MethodPost, apiV2URL+"forums/topics", payload, &topic)
+	if err != nil {
+		return nil, errors.New("osu.ClientV2.CreateForumTopic: " + err.Error())
+	}
+	return &topic, nil
+}
+
+// ReplyForumTopic adds a post to the given topic. Requires the forum.write scope
+func (client *ClientV2) ReplyForumTopic(topicID int64, body string) (*ForumPost, error) {
+	payload := map[string]interface{}{
+		"body": body,
+	}
+	var post ForumPost
+	err := client.do(context.Background(), http.MethodPost, apiV2URL+fmt.Sprintf("forums/topics/%d/reply", topicID), payload, &post)
+	if err != nil {
+		return nil, errors.New("osu.ClientV2.ReplyForumTopic: " + err.Error())
+	}
+	return &post, nil
+}
+
+// EditForumTopic changes the title of the given topic. Requires the forum.write scope
+func (client *ClientV2) EditForumTopic(topicID int64, title string) (*ForumTopic, error) {
+	payload := map[string]interface{}{
+		"forum_topic": map[string]interface{}{
+			"topic_title": title,
+		},
+	}
+	var topic ForumTopic
+	err := client.do(context.Background(), http.MethodPut, apiV2URL+fmt.Sprintf("forums/topics/%d", topicID), payload, &topic)
+	if err != nil {
+		return nil, errors.New("osu.ClientV2.EditForumTopic: " + err.Error())
+	}
+	return &topic, nil
+}
+
+// EditForumPost replaces the content of the given post. Requires the forum.write scope
+func (client *ClientV2) EditForumPost(postID int64, body string) (*ForumPost, error) {
+	payload := map[string]interface{}{
+		"body": body,
+	}
+	var post ForumPost
+	err := client.do(context.Background(), http.MethodPut, apiV2URL+fmt.Sprintf("forums/posts/%d", postID), payload, &post)
+	if err != nil {
+		return nil, errors.New("osu.ClientV2.EditForumPost: " + err.Error())
+	}
+	return &post, nil
+}
+
+// do sends an authenticated request, encoding payload as the JSON body if given, and decodes the response into v
+func (client *ClientV2) do(ctx context.Context, method, query string, payload interface{}, v interface{}) error {
+	query = strings.Replace(query, "?&", "?", 1)
+	query = strings.TrimSuffix(query, "?")
+	var reqBody io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, query, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+client.token)
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := client.c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if err = errorCheck(body); err != nil {
+			return err
+		}
+		return errors.New(resp.Status)
+	}
+	return json.Unmarshal(body, v)
+}
@@ -0,0 +1,126 @@
+package osu
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestClientV2Comments(t *testing.T) {
+	var got []string
+	client := NewClientV2("token")
+	client.c.Transport = serve(t, func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer token" {
+			t.Errorf("got authorization %q", auth)
+		}
+		got = append(got, r.URL.RequestURI())
+		w.Write([]byte(`{"comments":[{"id":5,"commentable_id":7,"commentable_type":"beatmapset","created_at":"2020-01-02T03:04:05+00:00","message":"hi","user_id":3}],
+			"cursor":{"created_at":"2020-01-02T03:04:05+00:00","id":5},"has_more":true,"sort":"new","users":[{"id":3,"username":"someone"}]}`))
+	})
+	bundle, err := client.Comments(CommentsOn(CommentableBeatmapset, 7), CommentsSort(CommentSortNew))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bundle.Comments) != 1 || bundle.Comments[0].ID != 5 || bundle.Comments[0].UserID != 3 || !bundle.HasMore || bundle.Users[0].Username != "someone" {
+		t.Errorf("got %+v", bundle)
+	}
+	if _, err := client.Comments(CommentsAfter(bundle.Cursor)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Comment(5); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"/api/v2/comments?commentable_type=beatmapset&commentable_id=7&sort=new",
+		"/api/v2/comments?cursor%5Bcreated_at%5D=2020-01-02T03%3A04%3A05%2B00%3A00&cursor%5Bid%5D=5",
+		"/api/v2/comments/5",
+	}
+	// the cursor's keys come from a map, so their order can differ
+	if got[1] != want[1] && got[1] != "/api/v2/comments?cursor%5Bid%5D=5&cursor%5Bcreated_at%5D=2020-01-02T03%3A04%3A05%2B00%3A00" {
+		t.Errorf("got cursor query %q", got[1])
+	}
+	got[1] = want[1]
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got queries %q, want %q", got, want)
+	}
+}
+
+func TestClientV2ForumTopic(t *testing.T) {
+	client := NewClientV2("token")
+	client.c.Transport = serve(t, func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.URL.RequestURI(), "/api/v2/forums/topics/12?sort=id_desc&limit=50&cursor_string=abc"; got != want {
+			t.Errorf("got query %q, want %q", got, want)
+		}
+		w.Write([]byte(`{"topic":{"id":12,"title":"Topic","post_count":2},"posts":[{"id":1,"topic_id":12,"body":{"html":"<p>a</p>","raw":"a"}}],"cursor_string":"def"}`))
+	})
+	topic, err := client.ForumTopic(12, ForumTopicSort(TopicSortNewest), ForumTopicLimit(100), ForumTopicAfter("abc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topic.Topic.Title != "Topic" || len(topic.Posts) != 1 || topic.Posts[0].Body.Raw != "a" || topic.Cursor == nil || *topic.Cursor != "def" {
+		t.Errorf("got %+v", topic)
+	}
+}
+
+func TestClientV2ForumWrite(t *testing.T) {
+	tests := []struct {
+		name, method, path string
+		body               map[string]interface{}
+		call               func(client *ClientV2) error
+	}{
+		{"create", http.MethodPost, "/api/v2/forums/topics", map[string]interface{}{"forum_id": 2.0, "title": "t", "body": "b"}, func(client *ClientV2) error {
+			_, err := client.CreateForumTopic(2, "t", "b")
+			return err
+		}},
+		{"reply", http.MethodPost, "/api/v2/forums/topics/3/reply", map[string]interface{}{"body": "b"}, func(client *ClientV2) error {
+			_, err := client.ReplyForumTopic(3, "b")
+			return err
+		}},
+		{"edit topic", http.MethodPut, "/api/v2/forums/topics/3", map[string]interface{}{"forum_topic": map[string]interface{}{"topic_title": "t"}}, func(client *ClientV2) error {
+			_, err := client.EditForumTopic(3, "t")
+			return err
+		}},
+		{"edit post", http.MethodPut, "/api/v2/forums/posts/4", map[string]interface{}{"body": "b"}, func(client *ClientV2) error {
+			_, err := client.EditForumPost(4, "b")
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClientV2("token")
+			client.c.Transport = serve(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != tt.method || r.URL.Path != tt.path || r.Header.Get("Content-Type") != "application/json" {
+					t.Errorf("got %s %s with content type %q", r.Method, r.URL.Path, r.Header.Get("Content-Type"))
+				}
+				data, _ := ioutil.ReadAll(r.Body)
+				var body map[string]interface{}
+				if err := json.Unmarshal(data, &body); err != nil || !reflect.DeepEqual(body, tt.body) {
+					t.Errorf("got body %s, want %v", data, tt.body)
+				}
+				w.Write([]byte(`{}`))
+			})
+			if err := tt.call(client); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestClientV2Error(t *testing.T) {
+	client := NewClientV2("token")
+	client.c.Transport = serve(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":"missing scope"}`))
+	})
+	if _, err := client.ReplyForumTopic(1, "b"); err == nil || err.Error() != "osu.ClientV2.ReplyForumTopic: missing scope" {
+		t.Errorf("got error %v", err)
+	}
+	client.c.Transport = serve(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	if _, err := client.Comment(1); err == nil || err.Error() != "osu.ClientV2.Comment: 404 Not Found" {
+		t.Errorf("got error %v", err)
+	}
+}
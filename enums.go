@@ -0,0 +1,474 @@
+package osu
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Mode is a game mode
+type Mode int
+
+// All game modes
+const (
+	ModeOsu Mode = iota
+	ModeTaiko
+	ModeCtb
+	ModeMania
+)
+
+var modeNames = map[Mode]string{
+	ModeOsu:   "osu",
+	ModeTaiko: "taiko",
+	ModeCtb:   "fruits",
+	ModeMania: "mania",
+}
+
+func (m Mode) String() string {
+	return enumString(m, modeNames)
+}
+
+// ParseMode parses a game mode from its name ("osu", "taiko", "fruits", "mania", or the aliases "std", "ctb" and "catch") or its number
+func ParseMode(s string) (Mode, error) {
+	m, ok := parseEnum(s, modeNames, map[string]Mode{"standard": ModeOsu, "std": ModeOsu, "ctb": ModeCtb, "catch": ModeCtb})
+	if !ok {
+		return 0, errors.New("osu.ParseMode: invalid mode " + strconv.Quote(s))
+	}
+	return m, nil
+}
+
+// MarshalText satisfies the encoding.TextMarshaler interface
+func (m Mode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface
+func (m *Mode) UnmarshalText(text []byte) error {
+	v, err := ParseMode(string(text))
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// Status is the ranked status of a beatmap
+type Status int
+
+// All ranked statuses
+const (
+	StatusGraveyard Status = iota - 2
+	StatusWIP
+	StatusPending
+	StatusRanked
+	StatusApproved
+	StatusQualified
+	StatusLoved
+)
+
+var statusNames = map[Status]string{
+	StatusGraveyard: "graveyard",
+	StatusWIP:       "wip",
+	StatusPending:   "pending",
+	StatusRanked:    "ranked",
+	StatusApproved:  "approved",
+	StatusQualified: "qualified",
+	StatusLoved:     "loved",
+}
+
+func (s Status) String() string {
+	return enumString(s, statusNames)
+}
+
+// ParseStatus parses a ranked status from its name ("ranked", "loved", ...) or its number
+func ParseStatus(s string) (Status, error) {
+	v, ok := parseEnum(s, statusNames, map[string]Status{"workinprogress": StatusWIP})
+	if !ok {
+		return 0, errors.New("osu.ParseStatus: invalid status " + strconv.Quote(s))
+	}
+	return v, nil
+}
+
+// MarshalText satisfies the encoding.TextMarshaler interface
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface
+func (s *Status) UnmarshalText(text []byte) error {
+	v, err := ParseStatus(string(text))
+	if err != nil {
+		return err
+	}
+	*s = v
+	return nil
+}
+
+// Genre is the genre of a beatmap's song
+type Genre int
+
+// All genres. 8 is not used by the API
+const (
+	GenreAny Genre = iota
+	GenreUnspecified
+	GenreVideoGame
+	GenreAnime
+	GenreRock
+	GenrePop
+	GenreOther
+	GenreNovelty
+	_
+	GenreHipHop
+	GenreElectronic
+	GenreMetal
+	GenreClassical
+	GenreFolk
+	GenreJazz
+)
+
+var genreNames = map[Genre]string{
+	GenreAny:         "Any",
+	GenreUnspecified: "Unspecified",
+	GenreVideoGame:   "Video Game",
+	GenreAnime:       "Anime",
+	GenreRock:        "Rock",
+	GenrePop:         "Pop",
+	GenreOther:       "Other",
+	GenreNovelty:     "Novelty",
+	GenreHipHop:      "Hip Hop",
+	GenreElectronic:  "Electronic",
+	GenreMetal:       "Metal",
+	GenreClassical:   "Classical",
+	GenreFolk:        "Folk",
+	GenreJazz:        "Jazz",
+}
+
+func (g Genre) String() string {
+	return enumString(g, genreNames)
+}
+
+// ParseGenre parses a genre from its name ("Video Game", "hiphop", ...) or its number
+func ParseGenre(s string) (Genre, error) {
+	v, ok := parseEnum(s, genreNames, nil)
+	if !ok {
+		return 0, errors.New("osu.ParseGenre: invalid genre " + strconv.Quote(s))
+	}
+	return v, nil
+}
+
+// MarshalText satisfies the encoding.TextMarshaler interface
+func (g Genre) MarshalText() ([]byte, error) {
+	return []byte(g.String()), nil
+}
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface
+func (g *Genre) UnmarshalText(text []byte) error {
+	v, err := ParseGenre(string(text))
+	if err != nil {
+		return err
+	}
+	*g = v
+	return nil
+}
+
+// Language is the language of a beatmap's song
+type Language int
+
+// All languages
+const (
+	LanguageAny Language = iota
+	LanguageUnspecified
+	LanguageEnglish
+	LanguageJapanese
+	LanguageChinese
+	LanguageInstrumental
+	LanguageKorean
+	LanguageFrench
+	LanguageGerman
+	LanguageSwedish
+	LanguageSpanish
+	LanguageItalian
+	LanguageRussian
+	LanguagePolish
+	LanguageOther
+)
+
+var languageNames = map[Language]string{
+	LanguageAny:          "Any",
+	LanguageUnspecified:  "Unspecified",
+	LanguageEnglish:      "English",
+	LanguageJapanese:     "Japanese",
+	LanguageChinese:      "Chinese",
+	LanguageInstrumental: "Instrumental",
+	LanguageKorean:       "Korean",
+	LanguageFrench:       "French",
+	LanguageGerman:       "German",
+	LanguageSwedish:      "Swedish",
+	LanguageSpanish:      "Spanish",
+	LanguageItalian:      "Italian",
+	LanguageRussian:      "Russian",
+	LanguagePolish:       "Polish",
+	LanguageOther:        "Other",
+}
+
+func (l Language) String() string {
+	return enumString(l, languageNames)
+}
+
+// ParseLanguage parses a language from its name ("English", "japanese", ...) or its number
+func ParseLanguage(s string) (Language, error) {
+	v, ok := parseEnum(s, languageNames, nil)
+	if !ok {
+		return 0, errors.New("osu.ParseLanguage: invalid language " + strconv.Quote(s))
+	}
+	return v, nil
+}
+
+// MarshalText satisfies the encoding.TextMarshaler interface
+func (l Language) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface
+func (l *Language) UnmarshalText(text []byte) error {
+	v, err := ParseLanguage(string(text))
+	if err != nil {
+		return err
+	}
+	*l = v
+	return nil
+}
+
+// ScoringType is the win condition of a multiplayer match
+type ScoringType int
+
+// All scoring types
+const (
+	ScoringTypeScore ScoringType = iota
+	ScoringTypeAccuracy
+	ScoringTypeCombo
+	ScoringTypeScoreV2
+)
+
+var scoringTypeNames = map[ScoringType]string{
+	ScoringTypeScore:    "score",
+	ScoringTypeAccuracy: "accuracy",
+	ScoringTypeCombo:    "combo",
+	ScoringTypeScoreV2:  "scorev2",
+}
+
+func (st ScoringType) String() string {
+	return enumString(st, scoringTypeNames)
+}
+
+// ParseScoringType parses a scoring type from its name ("score", "accuracy", "combo", "scorev2") or its number
+func ParseScoringType(s string) (ScoringType, error) {
+	v, ok := parseEnum(s, scoringTypeNames, nil)
+	if !ok {
+		return 0, errors.New("osu.ParseScoringType: invalid scoring type " + strconv.Quote(s))
+	}
+	return v, nil
+}
+
+// MarshalText satisfies the encoding.TextMarshaler interface
+func (st ScoringType) MarshalText() ([]byte, error) {
+	return []byte(st.String()), nil
+}
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface
+func (st *ScoringType) UnmarshalText(text []byte) error {
+	v, err := ParseScoringType(string(text))
+	if err != nil {
+		return err
+	}
+	*st = v
+	return nil
+}
+
+// TeamType is the team mode of a multiplayer match
+type TeamType int
+
+// All team types
+const (
+	TeamTypeHeadToHead TeamType = iota
+	TeamTypeTagCoop
+	TeamTypeTeamVs
+	TeamTypeTagTeamVs
+)
+
+var teamTypeNames = map[TeamType]string{
+	TeamTypeHeadToHead: "head-to-head",
+	TeamTypeTagCoop:    "tag-coop",
+	TeamTypeTeamVs:     "team-vs",
+	TeamTypeTagTeamVs:  "tag-team-vs",
+}
+
+func (tt TeamType) String() string {
+	return enumString(tt, teamTypeNames)
+}
+
+// ParseTeamType parses a team type from its name ("head-to-head", "tag-coop", "team-vs", "tag-team-vs") or its number
+func ParseTeamType(s string) (TeamType, error) {
+	v, ok := parseEnum(s, teamTypeNames, nil)
+	if !ok {
+		return 0, errors.New("osu.ParseTeamType: invalid team type " + strconv.Quote(s))
+	}
+	return v, nil
+}
+
+// MarshalText satisfies the encoding.TextMarshaler interface
+func (tt TeamType) MarshalText() ([]byte, error) {
+	return []byte(tt.String()), nil
+}
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface
+func (tt *TeamType) UnmarshalText(text []byte) error {
+	v, err := ParseTeamType(string(text))
+	if err != nil {
+		return err
+	}
+	*tt = v
+	return nil
+}
+
+// Rank is the grade given to a score. Ranks compare in order of quality, with silver grades above their gold counterparts
+type Rank int
+
+// All ranks. RankNone is used where the API gives no rank
+const (
+	RankNone Rank = iota
+	RankF
+	RankD
+	RankC
+	RankB
+	RankA
+	RankS
+	RankSH
+	RankSS
+	RankSSH
+)
+
+// rankNames holds the codes the API uses for each rank
+var rankNames = map[Rank]string{
+	RankNone: "",
+	RankF:    "F",
+	RankD:    "D",
+	RankC:    "C",
+	RankB:    "B",
+	RankA:    "A",
+	RankS:    "S",
+	RankSH:   "SH",
+	RankSS:   "X",
+	RankSSH:  "XH",
+}
+
+// String returns the code the API uses for the rank, e.g. "X" for RankSS
+func (r Rank) String() string {
+	return enumString(r, rankNames)
+}
+
+// ParseRank parses a rank from its API code ("XH", "X", "SH", "S", ...) or its common name ("SS", "SSH")
+func ParseRank(s string) (Rank, error) {
+	switch code := strings.ToUpper(strings.TrimSpace(s)); code {
+	case "", "0":
+		return RankNone, nil
+	case "SS":
+		return RankSS, nil
+	case "SSH":
+		return RankSSH, nil
+	default:
+		for r, name := range rankNames {
+			if name == code {
+				return r, nil
+			}
+		}
+	}
+	return 0, errors.New("osu.ParseRank: invalid rank " + strconv.Quote(s))
+}
+
+// MarshalText satisfies the encoding.TextMarshaler interface
+func (r Rank) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface
+func (r *Rank) UnmarshalText(text []byte) error {
+	v, err := ParseRank(string(text))
+	if err != nil {
+		return err
+	}
+	*r = v
+	return nil
+}
+
+// UsernameType is the way a user is represented in a query
+type UsernameType string
+
+// Both ways a user can be represented in a query
+const (
+	// UsernameTypeID is for referring to the ID number of a user
+	UsernameTypeID UsernameType = "int"
+	// UsernameTypeName is for referring to the name of a user
+	UsernameTypeName UsernameType = "string"
+)
+
+func (ut UsernameType) String() string {
+	return string(ut)
+}
+
+// ParseUsernameType parses a username type from "id" or "name", or from the API's "int" or "string"
+func ParseUsernameType(s string) (UsernameType, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "int", "id":
+		return UsernameTypeID, nil
+	case "string", "name":
+		return UsernameTypeName, nil
+	}
+	return "", errors.New("osu.ParseUsernameType: invalid username type " + strconv.Quote(s))
+}
+
+// MarshalText satisfies the encoding.TextMarshaler interface
+func (ut UsernameType) MarshalText() ([]byte, error) {
+	return []byte(ut), nil
+}
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface
+func (ut *UsernameType) UnmarshalText(text []byte) error {
+	v, err := ParseUsernameType(string(text))
+	if err != nil {
+		return err
+	}
+	*ut = v
+	return nil
+}
+
+// enumString returns the name of v, or its number if it has none
+func enumString[T ~int](v T, names map[T]string) string {
+	if name, ok := names[v]; ok {
+		return name
+	}
+	return strconv.Itoa(int(v))
+}
+
+// parseEnum looks s up by name or alias, accepting any number so that values added to the API still decode.
+// Names are matched ignoring case, spaces, dashes and underscores
+func parseEnum[T ~int](s string, names map[T]string, aliases map[string]T) (T, bool) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.Atoi(s); err == nil {
+		return T(n), true
+	}
+	key := normalizeName(s)
+	for v, name := range names {
+		if name != "" && normalizeName(name) == key {
+			return v, true
+		}
+	}
+	if v, ok := aliases[key]; ok {
+		return v, true
+	}
+	return 0, false
+}
+
+func normalizeName(s string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "", "_", "").Replace(s))
+}
@@ -0,0 +1,86 @@
+package osu
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseEnums(t *testing.T) {
+	tests := []struct {
+		in   string
+		got  func(string) (interface{}, error)
+		want interface{}
+	}{
+		{"mania", func(s string) (interface{}, error) { return ParseMode(s) }, ModeMania},
+		{"CTB", func(s string) (interface{}, error) { return ParseMode(s) }, ModeCtb},
+		{"std", func(s string) (interface{}, error) { return ParseMode(s) }, ModeOsu},
+		{"1", func(s string) (interface{}, error) { return ParseMode(s) }, ModeTaiko},
+		{"-2", func(s string) (interface{}, error) { return ParseStatus(s) }, StatusGraveyard},
+		{"Loved", func(s string) (interface{}, error) { return ParseStatus(s) }, StatusLoved},
+		{"work in progress", func(s string) (interface{}, error) { return ParseStatus(s) }, StatusWIP},
+		{"hip-hop", func(s string) (interface{}, error) { return ParseGenre(s) }, GenreHipHop},
+		{"video_game", func(s string) (interface{}, error) { return ParseGenre(s) }, GenreVideoGame},
+		{"xh", func(s string) (interface{}, error) { return ParseRank(s) }, RankSSH},
+		{"SS", func(s string) (interface{}, error) { return ParseRank(s) }, RankSS},
+		{"0", func(s string) (interface{}, error) { return ParseRank(s) }, RankNone},
+		{"id", func(s string) (interface{}, error) { return ParseUsernameType(s) }, UsernameTypeID},
+		{"string", func(s string) (interface{}, error) { return ParseUsernameType(s) }, UsernameTypeName},
+	}
+	for _, tt := range tests {
+		got, err := tt.got(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("%q: got %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, s := range []string{"", "osu!", "1.5"} {
+		if _, err := ParseMode(s); err == nil {
+			t.Errorf("%q: no error", s)
+		}
+	}
+	if _, err := ParseRank("Q"); err == nil {
+		t.Error("no error for rank Q")
+	}
+}
+
+func TestEnumUnknownNumbers(t *testing.T) {
+	// values the API adds later decode and keep their number
+	var b Beatmap
+	if err := json.Unmarshal([]byte(`{"approved":"5","genre_id":"99","language_id":"42","mode":"4"}`), &b); err != nil {
+		t.Fatal(err)
+	}
+	if b.Approved != 5 || b.GenreID != 99 || b.LanguageID != 42 || b.Mode != 4 {
+		t.Errorf("got %v, %v, %v, %v", b.Approved, b.GenreID, b.LanguageID, b.Mode)
+	}
+	if s := b.GenreID.String(); s != "99" {
+		t.Errorf("got name %q", s)
+	}
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back Beatmap
+	if err := json.Unmarshal(data, &back); err != nil || back.GenreID != 99 || back.Mode != 4 {
+		t.Errorf("got %v, %v after a round trip: %v", back.GenreID, back.Mode, err)
+	}
+}
+
+func TestEnumText(t *testing.T) {
+	in := struct {
+		Mode   Mode
+		Status Status
+		Rank   Rank
+		Team   TeamType
+	}{ModeCtb, StatusQualified, RankSS, TeamTypeTeamVs}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"Mode":"fruits","Status":"qualified","Rank":"X","Team":"team-vs"}` {
+		t.Errorf("got %s", data)
+	}
+	out := in
+	out.Mode, out.Status, out.Rank, out.Team = 0, 0, 0, 0
+	if err := json.Unmarshal(data, &out); err != nil || out != in {
+		t.Errorf("got %+v, %v", out, err)
+	}
+}
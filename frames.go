@@ -0,0 +1,229 @@
+package osu
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ulikunitz/xz/lzma"
+)
+
+// seedFrameTime is the TimeSinceLast of the frame osu! uses to store the replay's random seed
+const seedFrameTime = -12345 * time.Millisecond
+
+// Keys is a bitwise combination of the keys and mouse buttons pressed in a replay frame
+type Keys int
+
+// All keys. osu! sets KeyM1 along with KeyK1 and KeyM2 along with KeyK2.
+// In catch, KeyM1 is the dash key
+const (
+	KeyM1 Keys = 1 << iota
+	KeyM2
+	KeyK1
+	KeyK2
+	KeySmoke
+)
+
+var keyNames = []struct {
+	key  Keys
+	name string
+}{
+	{KeyK1, "K1"},
+	{KeyK2, "K2"},
+	{KeyM1, "M1"},
+	{KeyM2, "M2"},
+	{KeySmoke, "Smoke"},
+}
+
+// Has reports whether all of key is pressed
+func (k Keys) Has(key Keys) bool {
+	return k&key == key
+}
+
+// String returns the pressed keys joined with "+", e.g. "K1+Smoke", or "None".
+// M1 and M2 are left out when they are only set because of K1 and K2
+func (k Keys) String() string {
+	var names []string
+	for _, kn := range keyNames {
+		switch {
+		case !k.Has(kn.key):
+		case kn.key == KeyM1 && k.Has(KeyK1), kn.key == KeyM2 && k.Has(KeyK2):
+		default:
+			names = append(names, kn.name)
+		}
+	}
+	if unknown := k &^ (KeyM1 | KeyM2 | KeyK1 | KeyK2 | KeySmoke); unknown != 0 {
+		names = append(names, strconv.Itoa(int(unknown)))
+	}
+	if len(names) == 0 {
+		return "None"
+	}
+	return strings.Join(names, "+")
+}
+
+// IsSeed reports whether the point is the frame osu! stores the replay's random seed in
+func (p *ReplayPoint) IsSeed() bool {
+	return p.TimeSinceLast == seedFrameTime
+}
+
+// ManiaColumns returns the 0-based columns held in a mania frame, which stores them as a bitmask in X
+func (p *ReplayPoint) ManiaColumns() []int {
+	var columns []int
+	mask := int64(p.X)
+	for col := 0; mask != 0; col++ {
+		if mask&1 != 0 {
+			columns = append(columns, col)
+		}
+		mask >>= 1
+	}
+	return columns
+}
+
+// CatchDash reports whether the catcher is dashing in a catch frame
+func (p *ReplayPoint) CatchDash() bool {
+	return p.Keys.Has(KeyM1)
+}
+
+// CatchDirection returns -1 when the catcher moved left since prev, 1 when it moved right and 0 when it didn't move
+func (p *ReplayPoint) CatchDirection(prev *ReplayPoint) int {
+	switch {
+	case prev == nil || p.X == prev.X:
+		return 0
+	case p.X < prev.X:
+		return -1
+	}
+	return 1
+}
+
+// Seed returns the random seed stored in the replay, if it has one
+func (rc ReplayContent) Seed() (int, bool) {
+	for _, p := range rc {
+		if p.IsSeed() {
+			return int(p.Keys), true
+		}
+	}
+	return 0, false
+}
+
+// FrameError reports a malformed replay frame
+type FrameError struct {
+	// Index is the position of the frame in the replay data, counting malformed frames
+	Index int
+	Text  string
+	Err   error
+}
+
+func (e *FrameError) Error() string {
+	return fmt.Sprintf("osu: replay frame %d: %v: %q", e.Index, e.Err, e.Text)
+}
+
+// Unwrap returns the underlying error
+func (e *FrameError) Unwrap() error {
+	return e.Err
+}
+
+// ParseReplayFrames parses uncompressed replay frames written as "w|x|y|z,w|x|y|z,...", keeping every frame in its original order.
+// When strict is set the first malformed frame is returned as a *FrameError, otherwise malformed frames are skipped and returned
+func ParseReplayFrames(data string, strict bool) (ReplayContent, []*FrameError, error) {
+	out := make(ReplayContent, 0)
+	var skipped []*FrameError
+	var now time.Duration
+	for i, group := range strings.Split(data, ",") {
+		if strings.TrimSpace(group) == "" {
+			continue
+		}
+		p, err := parseReplayFrame(group)
+		if err != nil {
+			fe := &FrameError{Index: i, Text: group, Err: err}
+			if strict {
+				return nil, nil, fe
+			}
+			skipped = append(skipped, fe)
+			continue
+		}
+		if !p.IsSeed() {
+			now += p.TimeSinceLast
+		}
+		p.Time = now
+		out = append(out, p)
+	}
+	return out, skipped, nil
+}
+
+func parseReplayFrame(s string) (*ReplayPoint, error) {
+	parts := strings.Split(strings.TrimSpace(s), "|")
+	if len(parts) != 4 {
+		return nil, errors.New("expected 4 values")
+	}
+	w, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return nil, err
+	}
+	p := &ReplayPoint{TimeSinceLast: time.Duration(math.Round(w * float64(time.Millisecond)))}
+	if p.X, err = strconv.ParseFloat(parts[1], 64); err != nil {
+		return nil, err
+	}
+	if p.Y, err = strconv.ParseFloat(parts[2], 64); err != nil {
+		return nil, err
+	}
+	keys, err := strconv.ParseFloat(parts[3], 64)
+	if err != nil {
+		return nil, err
+	}
+	p.Keys = Keys(keys)
+	return p, nil
+}
+
+// FormatReplayFrames writes replay frames the way ParseReplayFrames reads them
+func FormatReplayFrames(rc ReplayContent) string {
+	var raw strings.Builder
+	for _, p := range rc {
+		raw.WriteString(strconv.FormatFloat(float64(p.TimeSinceLast)/float64(time.Millisecond), 'f', -1, 64))
+		raw.WriteString("|" + strconv.FormatFloat(p.X, 'f', -1, 64))
+		raw.WriteString("|" + strconv.FormatFloat(p.Y, 'f', -1, 64))
+		raw.WriteString("|" + strconv.Itoa(int(p.Keys)) + ",")
+	}
+	return raw.String()
+}
+
+// decodeReplayData decompresses and parses LZMA compressed replay frames
+func decodeReplayData(data []byte, strict bool) (ReplayContent, []*FrameError, error) {
+	if len(data) == 0 {
+		return ReplayContent{}, nil, nil
+	}
+	reader, err := lzma.NewReader(bytes.NewBuffer(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	outRaw, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ParseReplayFrames(string(outRaw), strict)
+}
+
+// encodeReplayData formats and LZMA compresses replay frames
+func encodeReplayData(rc ReplayContent) ([]byte, error) {
+	return compressLZMA([]byte(FormatReplayFrames(rc)))
+}
+
+// compressLZMA compresses data in the LZMA "alone" format osu! uses, with the uncompressed size in the header
+func compressLZMA(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := lzma.WriterConfig{Size: int64(len(data))}.NewWriter(&buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
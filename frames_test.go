@@ -0,0 +1,109 @@
+package osu
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseReplayFrames(t *testing.T) {
+	data := "0|256|-500|0,-1|256|-500|0,16|100.5|200.25|5,bad,-3|101|201|10,1|2|3,17.5|1|2|16,-12345|0|0|1234567,"
+	rc, skipped, err := ParseReplayFrames(data, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped) != 2 || skipped[0].Index != 3 || skipped[0].Text != "bad" || skipped[1].Index != 5 {
+		t.Errorf("got skipped %v", skipped)
+	}
+	want := ReplayContent{
+		{TimeSinceLast: 0, Time: 0, X: 256, Y: -500},
+		{TimeSinceLast: -time.Millisecond, Time: -time.Millisecond, X: 256, Y: -500},
+		{TimeSinceLast: 16 * time.Millisecond, Time: 15 * time.Millisecond, X: 100.5, Y: 200.25, Keys: KeyM1 | KeyK1},
+		{TimeSinceLast: -3 * time.Millisecond, Time: 12 * time.Millisecond, X: 101, Y: 201, Keys: KeyM2 | KeyK2},
+		{TimeSinceLast: 17500 * time.Microsecond, Time: 29500 * time.Microsecond, X: 1, Y: 2, Keys: KeySmoke},
+		{TimeSinceLast: seedFrameTime, Time: 29500 * time.Microsecond, Keys: 1234567},
+	}
+	if !reflect.DeepEqual(rc, want) {
+		for i, p := range rc {
+			t.Logf("frame %d: %+v", i, *p)
+		}
+		t.Fatal("frames differ")
+	}
+	if seed, ok := rc.Seed(); !ok || seed != 1234567 {
+		t.Errorf("got seed %d, %v", seed, ok)
+	}
+	if _, ok := rc[:5].Seed(); ok {
+		t.Error("found a seed in frames without one")
+	}
+
+	_, _, err = ParseReplayFrames(data, true)
+	var fe *FrameError
+	if !errors.As(err, &fe) || fe.Index != 3 || fe.Text != "bad" {
+		t.Errorf("strict: got %v", err)
+	}
+
+	// formatting the frames and parsing them again gives the same frames
+	again, skipped, err := ParseReplayFrames(FormatReplayFrames(rc), true)
+	if err != nil || len(skipped) > 0 {
+		t.Fatal(err, skipped)
+	}
+	if !reflect.DeepEqual(again, rc) {
+		t.Error("frames differ after formatting")
+	}
+
+	if rc, _, err := ParseReplayFrames("", true); err != nil || rc == nil || len(rc) != 0 {
+		t.Errorf("empty data: got %v, %v", rc, err)
+	}
+}
+
+func TestKeysString(t *testing.T) {
+	tests := []struct {
+		keys Keys
+		want string
+	}{
+		{0, "None"},
+		{KeyM1, "M1"},
+		{KeyM1 | KeyK1, "K1"},
+		{KeyM1 | KeyM2 | KeyK1 | KeyK2, "K1+K2"},
+		{KeyM1 | KeyM2 | KeyK2, "K2+M1"},
+		{KeyK1 | KeySmoke, "K1+Smoke"},
+		{KeyM2 | 64, "M2+64"},
+	}
+	for _, tt := range tests {
+		if got := tt.keys.String(); got != tt.want {
+			t.Errorf("Keys(%d): got %q, want %q", int(tt.keys), got, tt.want)
+		}
+	}
+	if !Keys(5).Has(KeyK1) || Keys(1).Has(KeyK1) || !Keys(15).Has(KeyM1|KeyM2) {
+		t.Error("Has reported the wrong keys")
+	}
+}
+
+func TestReplayPointModes(t *testing.T) {
+	if got := (&ReplayPoint{X: 0b1010001}).ManiaColumns(); !reflect.DeepEqual(got, []int{0, 4, 6}) {
+		t.Errorf("got mania columns %v", got)
+	}
+	if got := (&ReplayPoint{}).ManiaColumns(); got != nil {
+		t.Errorf("got mania columns %v without any held", got)
+	}
+	prev := &ReplayPoint{X: 200}
+	tests := []struct {
+		prev *ReplayPoint
+		x    float64
+		want int
+	}{
+		{nil, 100, 0},
+		{prev, 200, 0},
+		{prev, 150, -1},
+		{prev, 250, 1},
+	}
+	for _, tt := range tests {
+		if got := (&ReplayPoint{X: tt.x}).CatchDirection(tt.prev); got != tt.want {
+			t.Errorf("from %+v to %v: got %d, want %d", tt.prev, tt.x, got, tt.want)
+		}
+	}
+	if !(&ReplayPoint{Keys: KeyM1}).CatchDash() || (&ReplayPoint{Keys: KeyM2}).CatchDash() {
+		t.Error("CatchDash reported the wrong state")
+	}
+}
@@ -0,0 +1,100 @@
+package osu
+
+import (
+	"strconv"
+	"strings"
+)
+
+// BeatmapID identifies a single difficulty of a beatmapset
+type BeatmapID int64
+
+// BeatmapsetID identifies a beatmapset
+type BeatmapsetID int64
+
+// UserID identifies a user
+type UserID int64
+
+// ScoreID identifies a submitted score
+type ScoreID int64
+
+// MatchID identifies a multiplayer match
+type MatchID int64
+
+// GameID identifies a single game played in a multiplayer match
+type GameID int64
+
+func (id BeatmapID) String() string    { return strconv.FormatInt(int64(id), 10) }
+func (id BeatmapsetID) String() string { return strconv.FormatInt(int64(id), 10) }
+func (id UserID) String() string       { return strconv.FormatInt(int64(id), 10) }
+func (id ScoreID) String() string      { return strconv.FormatInt(int64(id), 10) }
+func (id MatchID) String() string      { return strconv.FormatInt(int64(id), 10) }
+func (id GameID) String() string       { return strconv.FormatInt(int64(id), 10) }
+
+// MarshalText satisfies the encoding.TextMarshaler interface
+func (id BeatmapID) MarshalText() ([]byte, error) { return []byte(id.String()), nil }
+
+// MarshalText satisfies the encoding.TextMarshaler interface
+func (id BeatmapsetID) MarshalText() ([]byte, error) { return []byte(id.String()), nil }
+
+// MarshalText satisfies the encoding.TextMarshaler interface
+func (id UserID) MarshalText() ([]byte, error) { return []byte(id.String()), nil }
+
+// MarshalText satisfies the encoding.TextMarshaler interface
+func (id ScoreID) MarshalText() ([]byte, error) { return []byte(id.String()), nil }
+
+// MarshalText satisfies the encoding.TextMarshaler interface
+func (id MatchID) MarshalText() ([]byte, error) { return []byte(id.String()), nil }
+
+// MarshalText satisfies the encoding.TextMarshaler interface
+func (id GameID) MarshalText() ([]byte, error) { return []byte(id.String()), nil }
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface
+func (id *BeatmapID) UnmarshalText(text []byte) error { return parseID((*int64)(id), text) }
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface
+func (id *BeatmapsetID) UnmarshalText(text []byte) error { return parseID((*int64)(id), text) }
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface
+func (id *UserID) UnmarshalText(text []byte) error { return parseID((*int64)(id), text) }
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface
+func (id *ScoreID) UnmarshalText(text []byte) error { return parseID((*int64)(id), text) }
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface
+func (id *MatchID) UnmarshalText(text []byte) error { return parseID((*int64)(id), text) }
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface
+func (id *GameID) UnmarshalText(text []byte) error { return parseID((*int64)(id), text) }
+
+// UnmarshalJSON satisfies the Unmarshaler interface. The v1 API quotes IDs while v2 does not, so both are accepted
+func (id *BeatmapID) UnmarshalJSON(data []byte) error { return parseID((*int64)(id), data) }
+
+// UnmarshalJSON satisfies the Unmarshaler interface. The v1 API quotes IDs while v2 does not, so both are accepted
+func (id *BeatmapsetID) UnmarshalJSON(data []byte) error { return parseID((*int64)(id), data) }
+
+// UnmarshalJSON satisfies the Unmarshaler interface. The v1 API quotes IDs while v2 does not, so both are accepted
+func (id *UserID) UnmarshalJSON(data []byte) error { return parseID((*int64)(id), data) }
+
+// UnmarshalJSON satisfies the Unmarshaler interface. The v1 API quotes IDs while v2 does not, so both are accepted
+func (id *ScoreID) UnmarshalJSON(data []byte) error { return parseID((*int64)(id), data) }
+
+// UnmarshalJSON satisfies the Unmarshaler interface. The v1 API quotes IDs while v2 does not, so both are accepted
+func (id *MatchID) UnmarshalJSON(data []byte) error { return parseID((*int64)(id), data) }
+
+// UnmarshalJSON satisfies the Unmarshaler interface. The v1 API quotes IDs while v2 does not, so both are accepted
+func (id *GameID) UnmarshalJSON(data []byte) error { return parseID((*int64)(id), data) }
+
+// parseID reads an ID from text, which may be quoted, empty or null
+func parseID(id *int64, text []byte) error {
+	str := strings.Trim(string(text), `"`)
+	if str == "" || str == "null" {
+		*id = 0
+		return nil
+	}
+	n, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return err
+	}
+	*id = n
+	return nil
+}
@@ -0,0 +1,50 @@
+package osu
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestIDUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		json string
+		want BeatmapID
+		err  bool
+	}{
+		{`"252002"`, 252002, false},
+		{`252002`, 252002, false},
+		{`""`, 0, false},
+		{`null`, 0, false},
+		{`"9000000000"`, 9000000000, false},
+		{`"abc"`, 0, true},
+		{`1.5`, 0, true},
+	}
+	for _, tt := range tests {
+		var got struct {
+			ID BeatmapID `json:"id"`
+		}
+		err := json.Unmarshal([]byte(`{"id":`+tt.json+`}`), &got)
+		if (err != nil) != tt.err || got.ID != tt.want {
+			t.Errorf("%s: got %d, %v, want %d", tt.json, got.ID, err, tt.want)
+		}
+	}
+}
+
+func TestIDText(t *testing.T) {
+	// IDs marshal as text, so they can be map keys
+	in := map[UserID]ScoreID{1: 4000000000, 7: 2}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"1":"4000000000","7":"2"}` {
+		t.Errorf("got %s", data)
+	}
+	var out map[UserID]ScoreID
+	if err := json.Unmarshal(data, &out); err != nil || len(out) != 2 || out[1] != 4000000000 || out[7] != 2 {
+		t.Errorf("got %v, %v", out, err)
+	}
+	if got := MatchID(1936471).String(); got != "1936471" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,187 @@
+// Package osubin reads and writes the little-endian binary encoding used by osu! files such as .osr replays and .db databases
+package osubin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"time"
+)
+
+// ticksAtUnixEpoch is the number of .NET ticks (100ns since 0001-01-01) at 1970-01-01
+const ticksAtUnixEpoch = 621355968000000000
+
+// TicksToTime converts .NET ticks to a time in UTC. 0 gives the zero time
+func TicksToTime(ticks int64) time.Time {
+	if ticks == 0 {
+		return time.Time{}
+	}
+	ticks -= ticksAtUnixEpoch
+	return time.Unix(ticks/1e7, ticks%1e7*100).UTC()
+}
+
+// TimeToTicks converts a time to .NET ticks. The zero time gives 0
+func TimeToTicks(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()*1e7 + int64(t.Nanosecond())/100 + ticksAtUnixEpoch
+}
+
+// Reader reads values from an osu! binary file. After the first error every read returns the zero value,
+// so a sequence of reads only needs to check Err once
+type Reader struct {
+	r   io.Reader
+	err error
+	buf [8]byte
+}
+
+// NewReader returns a Reader reading from r
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: r}
+}
+
+// Err returns the first error encountered. A file that ends early gives io.ErrUnexpectedEOF
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// Fail records err as the reader's error unless it already has one
+func (r *Reader) Fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+func (r *Reader) read(n int) []byte {
+	if r.err != nil {
+		if n > len(r.buf) {
+			return nil
+		}
+		return make([]byte, n)
+	}
+	if n > len(r.buf) {
+		// copy rather than allocating n up front, as n may come from a corrupt length
+		var buf bytes.Buffer
+		if _, err := io.CopyN(&buf, r.r, int64(n)); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			r.err = err
+			return nil
+		}
+		return buf.Bytes()
+	}
+	b := r.buf[:n]
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		r.err = err
+		for i := range b {
+			b[i] = 0
+		}
+	}
+	return b
+}
+
+// AtEOF reports whether there is nothing left to read. It only works when the underlying reader is an io.ByteScanner
+func (r *Reader) AtEOF() bool {
+	bs, ok := r.r.(io.ByteScanner)
+	if !ok || r.err != nil {
+		return r.err != nil
+	}
+	if _, err := bs.ReadByte(); err != nil {
+		return true
+	}
+	bs.UnreadByte()
+	return false
+}
+
+// Byte reads a single byte
+func (r *Reader) Byte() byte {
+	return r.read(1)[0]
+}
+
+// Bool reads a byte, which is true when non-zero
+func (r *Reader) Bool() bool {
+	return r.Byte() != 0
+}
+
+// Int16 reads a 16 bit integer
+func (r *Reader) Int16() int16 {
+	return int16(binary.LittleEndian.Uint16(r.read(2)))
+}
+
+// Int32 reads a 32 bit integer
+func (r *Reader) Int32() int32 {
+	return int32(binary.LittleEndian.Uint32(r.read(4)))
+}
+
+// Int64 reads a 64 bit integer
+func (r *Reader) Int64() int64 {
+	return int64(binary.LittleEndian.Uint64(r.read(8)))
+}
+
+// Float32 reads a single precision float
+func (r *Reader) Float32() float32 {
+	return math.Float32frombits(binary.LittleEndian.Uint32(r.read(4)))
+}
+
+// Float64 reads a double precision float
+func (r *Reader) Float64() float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(r.read(8)))
+}
+
+// ULEB128 reads an unsigned LEB128 variable length integer
+func (r *Reader) ULEB128() uint64 {
+	var n uint64
+	for shift := uint(0); ; shift += 7 {
+		b := r.Byte()
+		if shift >= 64 {
+			r.Fail(errors.New("osubin: ULEB128 overflows 64 bits"))
+			return 0
+		}
+		n |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return n
+		}
+	}
+}
+
+// String reads a string, which is 0x00 when empty or 0x0b followed by a ULEB128 length and UTF-8 bytes
+func (r *Reader) String() string {
+	switch b := r.Byte(); b {
+	case 0x00:
+		return ""
+	case 0x0b:
+		n := r.ULEB128()
+		if n > math.MaxInt32 {
+			r.Fail(errors.New("osubin: string too long"))
+			return ""
+		}
+		return string(r.Bytes(int(n)))
+	default:
+		r.Fail(errors.New("osubin: invalid string marker"))
+		return ""
+	}
+}
+
+// Bytes reads n bytes
+func (r *Reader) Bytes(n int) []byte {
+	if n < 0 {
+		r.Fail(errors.New("osubin: negative length"))
+		return nil
+	}
+	if n <= len(r.buf) {
+		return append([]byte(nil), r.read(n)...)
+	}
+	return r.read(n)
+}
+
+// DateTime reads a time stored as 64 bit .NET ticks
+func (r *Reader) DateTime() time.Time {
+	return TicksToTime(r.Int64())
+}
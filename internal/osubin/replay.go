@@ -0,0 +1,110 @@
+package osubin
+
+import "time"
+
+// Game versions that changed the replay format
+const (
+	// ReplayVersionScoreID is the first version to store the online score ID
+	ReplayVersionScoreID = 20121008
+	// ReplayVersionScoreID64 is the first version to store the online score ID as 64 bits
+	ReplayVersionScoreID64 = 20140721
+)
+
+// modTarget is the Target Practice mod, whose plays store their accuracy
+const modTarget = 1 << 23
+
+// Replay holds the fields of an .osr replay before osu!lazer's data. scores.db stores each score the same way, without replay data
+type Replay struct {
+	Mode       byte
+	Version    int32
+	BeatmapMD5 string
+	PlayerName string
+	ReplayMD5  string
+	Count300   uint16
+	Count100   uint16
+	Count50    uint16
+	Countgeki  uint16
+	Countkatu  uint16
+	Countmiss  uint16
+	Score      int32
+	Maxcombo   uint16
+	Perfect    bool
+	Mods       uint32
+	LifeBar    string
+	Timestamp  time.Time
+	// Data holds the LZMA compressed replay frames. scores.db has none, which is written as a length of -1
+	Data []byte
+	// ScoreID is stored from ReplayVersionScoreID
+	ScoreID int64
+	// TargetAccuracy is stored for Target Practice plays
+	TargetAccuracy float64
+}
+
+// Replay reads a replay's fields
+func (r *Reader) Replay() Replay {
+	rp := Replay{
+		Mode:       r.Byte(),
+		Version:    r.Int32(),
+		BeatmapMD5: r.String(),
+		PlayerName: r.String(),
+		ReplayMD5:  r.String(),
+		Count300:   uint16(r.Int16()),
+		Count100:   uint16(r.Int16()),
+		Count50:    uint16(r.Int16()),
+		Countgeki:  uint16(r.Int16()),
+		Countkatu:  uint16(r.Int16()),
+		Countmiss:  uint16(r.Int16()),
+		Score:      r.Int32(),
+		Maxcombo:   uint16(r.Int16()),
+		Perfect:    r.Bool(),
+		Mods:       uint32(r.Int32()),
+		LifeBar:    r.String(),
+		Timestamp:  r.DateTime(),
+	}
+	if n := r.Int32(); n > 0 {
+		rp.Data = r.Bytes(int(n))
+	}
+	switch {
+	case rp.Version >= ReplayVersionScoreID64:
+		rp.ScoreID = r.Int64()
+	case rp.Version >= ReplayVersionScoreID:
+		rp.ScoreID = int64(r.Int32())
+	}
+	if rp.Mods&modTarget != 0 {
+		rp.TargetAccuracy = r.Float64()
+	}
+	return rp
+}
+
+// Replay writes a replay's fields
+func (w *Writer) Replay(rp Replay) {
+	w.Byte(rp.Mode)
+	w.Int32(rp.Version)
+	w.String(rp.BeatmapMD5)
+	w.String(rp.PlayerName)
+	w.String(rp.ReplayMD5)
+	for _, n := range []uint16{rp.Count300, rp.Count100, rp.Count50, rp.Countgeki, rp.Countkatu, rp.Countmiss} {
+		w.Int16(int16(n))
+	}
+	w.Int32(rp.Score)
+	w.Int16(int16(rp.Maxcombo))
+	w.Bool(rp.Perfect)
+	w.Int32(int32(rp.Mods))
+	w.String(rp.LifeBar)
+	w.DateTime(rp.Timestamp)
+	if rp.Data == nil {
+		w.Int32(-1)
+	} else {
+		w.Int32(int32(len(rp.Data)))
+		w.Bytes(rp.Data)
+	}
+	switch {
+	case rp.Version >= ReplayVersionScoreID64:
+		w.Int64(rp.ScoreID)
+	case rp.Version >= ReplayVersionScoreID:
+		w.Int32(int32(rp.ScoreID))
+	}
+	if rp.Mods&modTarget != 0 {
+		w.Float64(rp.TargetAccuracy)
+	}
+}
@@ -0,0 +1,115 @@
+package osubin
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"time"
+)
+
+// Writer writes values in the osu! binary encoding. After the first error every write is skipped,
+// so a sequence of writes only needs to check Err once
+type Writer struct {
+	w   io.Writer
+	err error
+	buf [10]byte
+}
+
+// NewWriter returns a Writer writing to w
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Err returns the first error encountered
+func (w *Writer) Err() error {
+	return w.err
+}
+
+func (w *Writer) write(b []byte) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = w.w.Write(b)
+}
+
+// Byte writes a single byte
+func (w *Writer) Byte(b byte) {
+	w.buf[0] = b
+	w.write(w.buf[:1])
+}
+
+// Bool writes a byte, 1 for true and 0 for false
+func (w *Writer) Bool(b bool) {
+	if b {
+		w.Byte(1)
+	} else {
+		w.Byte(0)
+	}
+}
+
+// Int16 writes a 16 bit integer
+func (w *Writer) Int16(n int16) {
+	binary.LittleEndian.PutUint16(w.buf[:2], uint16(n))
+	w.write(w.buf[:2])
+}
+
+// Int32 writes a 32 bit integer
+func (w *Writer) Int32(n int32) {
+	binary.LittleEndian.PutUint32(w.buf[:4], uint32(n))
+	w.write(w.buf[:4])
+}
+
+// Int64 writes a 64 bit integer
+func (w *Writer) Int64(n int64) {
+	binary.LittleEndian.PutUint64(w.buf[:8], uint64(n))
+	w.write(w.buf[:8])
+}
+
+// Float32 writes a single precision float
+func (w *Writer) Float32(f float32) {
+	w.Int32(int32(math.Float32bits(f)))
+}
+
+// Float64 writes a double precision float
+func (w *Writer) Float64(f float64) {
+	w.Int64(int64(math.Float64bits(f)))
+}
+
+// ULEB128 writes an unsigned LEB128 variable length integer
+func (w *Writer) ULEB128(n uint64) {
+	i := 0
+	for {
+		b := byte(n & 0x7f)
+		n >>= 7
+		if n != 0 {
+			b |= 0x80
+		}
+		w.buf[i] = b
+		i++
+		if n == 0 {
+			break
+		}
+	}
+	w.write(w.buf[:i])
+}
+
+// String writes a string, as 0x00 when empty or 0x0b followed by a ULEB128 length and UTF-8 bytes
+func (w *Writer) String(s string) {
+	if s == "" {
+		w.Byte(0x00)
+		return
+	}
+	w.Byte(0x0b)
+	w.ULEB128(uint64(len(s)))
+	w.write([]byte(s))
+}
+
+// Bytes writes b as is
+func (w *Writer) Bytes(b []byte) {
+	w.write(b)
+}
+
+// DateTime writes a time as 64 bit .NET ticks
+func (w *Writer) DateTime(t time.Time) {
+	w.Int64(TimeToTicks(t))
+}
@@ -0,0 +1,102 @@
+// Package ziparchive reads the zip archives osu! uses for beatmapsets and skins, finding files the way osu! does
+package ziparchive
+
+import (
+	"archive/zip"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+)
+
+// Archive is an open zip archive
+type Archive struct {
+	r        *zip.Reader
+	closer   io.Closer
+	notFound error
+	// files holds the archive's files by normalized name
+	files map[string]*zip.File
+}
+
+// Open opens the zip file at path. Missing files are reported as notFound. The archive must be closed after use
+func Open(path string, notFound error) (*Archive, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	a := newArchive(&zr.Reader, notFound)
+	a.closer = zr
+	return a, nil
+}
+
+// NewReader reads a zip archive of the given size from r. Missing files are reported as notFound
+func NewReader(r io.ReaderAt, size int64, notFound error) (*Archive, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return newArchive(zr, notFound), nil
+}
+
+func newArchive(zr *zip.Reader, notFound error) *Archive {
+	a := &Archive{r: zr, notFound: notFound, files: make(map[string]*zip.File)}
+	for _, f := range zr.File {
+		if !f.FileInfo().IsDir() {
+			a.files[Normalize(f.Name)] = f
+		}
+	}
+	return a
+}
+
+// Normalize makes a file name match however beatmaps and skins refer to it: osu! file names are case insensitive and may use backslashes
+func Normalize(name string) string {
+	name = strings.ReplaceAll(strings.Trim(name, `"`), `\`, "/")
+	return strings.ToLower(strings.TrimPrefix(path.Clean("/"+name), "/"))
+}
+
+// Close closes an archive opened with Open
+func (a *Archive) Close() error {
+	if a.closer == nil {
+		return nil
+	}
+	return a.closer.Close()
+}
+
+// Files returns the names of the files in the archive
+func (a *Archive) Files() []string {
+	names := make([]string, 0, len(a.r.File))
+	for _, f := range a.r.File {
+		if !f.FileInfo().IsDir() {
+			names = append(names, f.Name)
+		}
+	}
+	return names
+}
+
+// Lookup returns the name a file has in the archive, matching names the way osu! does
+func (a *Archive) Lookup(name string) (string, bool) {
+	f, ok := a.files[Normalize(name)]
+	if !ok {
+		return "", false
+	}
+	return f.Name, true
+}
+
+// Open opens a file in the archive. Names are matched the way osu! does, ignoring case and the direction of slashes
+func (a *Archive) Open(name string) (io.ReadCloser, error) {
+	f, ok := a.files[Normalize(name)]
+	if !ok {
+		return nil, a.notFound
+	}
+	return f.Open()
+}
+
+// ReadFile returns the contents of a file in the archive
+func (a *Archive) ReadFile(name string) ([]byte, error) {
+	rc, err := a.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
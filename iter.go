@@ -0,0 +1,170 @@
+package osu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var (
+	sinceParam = regexp.MustCompile(`&since=[^&]*`)
+	limitParam = regexp.MustCompile(`&limit=([0-9]+)`)
+)
+
+// maxBeatmapsLimit is the most beatmaps get_beatmaps returns at once
+const maxBeatmapsLimit = 500
+
+// AllBeatmaps returns an iterator over every ranked or loved beatmap matching opts, walking past the
+// 500 result limit with since queries and skipping beatmaps already yielded. BeatmapsSince only sets where the first page starts.
+// When a whole page of beatmaps share one approved date, that date is fetched again with a page of 500, and if more
+// than 500 beatmaps share it the iterator stops with an error rather than skip some of them.
+// It stops with ctx's error once ctx is done
+func (client *Client) AllBeatmaps(ctx context.Context, opts ...BeatmapOption) iter.Seq2[*Beatmap, error] {
+	return func(yield func(*Beatmap, error) bool) {
+		query := apiURL + "get_beatmaps?k=" + client.key
+		for _, opt := range opts {
+			query = opt(query)
+		}
+		// later pages set their own since
+		base := sinceParam.ReplaceAllString(query, "")
+		limit := maxBeatmapsLimit
+		if m := limitParam.FindStringSubmatch(base); m != nil {
+			limit, _ = strconv.Atoi(m[1])
+		}
+		pageLimit := limit
+		seen := make(map[BeatmapID]bool)
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(nil, errors.New("osu.Client.AllBeatmaps: "+err.Error()))
+				return
+			}
+			maps, err := client.beatmaps(ctx, query)
+			if err != nil {
+				yield(nil, errors.New("osu.Client.AllBeatmaps: "+err.Error()))
+				return
+			}
+			fresh := 0
+			for _, m := range maps {
+				if seen[m.BeatmapID] {
+					continue
+				}
+				seen[m.BeatmapID] = true
+				fresh++
+				if !yield(m, nil) {
+					return
+				}
+			}
+			if len(maps) == 0 {
+				return
+			}
+			last := maps[len(maps)-1].ApprovedDate
+			if last.IsZero() {
+				return
+			}
+			// since is exclusive, so step back a second to pick up the rest of the
+			// beatmaps that share the last ranked date
+			since := "&since=" + url.QueryEscape(last.UTC().Add(-time.Second).Format("2006-01-02 15:04:05"))
+			if len(maps) >= pageLimit && maps[0].ApprovedDate.Equal(last) {
+				// the whole page shares one date, so the next page would start with it again
+				if pageLimit >= maxBeatmapsLimit {
+					yield(nil, fmt.Errorf("osu.Client.AllBeatmaps: more than %d beatmaps share the approved date %v", maxBeatmapsLimit, last))
+					return
+				}
+				pageLimit = maxBeatmapsLimit
+				query = limitParam.ReplaceAllString(base, "") + fmt.Sprintf("&limit=%d", maxBeatmapsLimit) + since
+				continue
+			}
+			if fresh == 0 {
+				return
+			}
+			pageLimit = limit
+			query = base + since
+		}
+	}
+}
+
+// AllComments returns an iterator over every comment matching opts, following the cursor
+// of each page until the listing is exhausted. Pinned and included parent comments are not yielded
+func (client *ClientV2) AllComments(ctx context.Context, opts ...CommentsOption) iter.Seq2[*Comment, error] {
+	return func(yield func(*Comment, error) bool) {
+		base := apiV2URL + "comments?"
+		for _, opt := range opts {
+			base = opt(base)
+		}
+		seen := make(map[int64]bool)
+		query := base
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(nil, errors.New("osu.ClientV2.AllComments: "+err.Error()))
+				return
+			}
+			var bundle CommentBundle
+			err := client.do(ctx, http.MethodGet, query, nil, &bundle)
+			if err != nil {
+				yield(nil, errors.New("osu.ClientV2.AllComments: "+err.Error()))
+				return
+			}
+			fresh := 0
+			for _, c := range bundle.Comments {
+				if seen[c.ID] {
+					continue
+				}
+				seen[c.ID] = true
+				fresh++
+				if !yield(c, nil) {
+					return
+				}
+			}
+			if !bundle.HasMore || len(bundle.Cursor) == 0 || fresh == 0 {
+				return
+			}
+			query = CommentsAfter(bundle.Cursor)(base)
+		}
+	}
+}
+
+// AllForumPosts returns an iterator over every post in a forum topic, following the cursor
+// of each page until the topic is exhausted
+func (client *ClientV2) AllForumPosts(ctx context.Context, topicID int64, opts ...ForumTopicOption) iter.Seq2[*ForumPost, error] {
+	return func(yield func(*ForumPost, error) bool) {
+		base := apiV2URL + fmt.Sprintf("forums/topics/%d?", topicID)
+		for _, opt := range opts {
+			base = opt(base)
+		}
+		seen := make(map[int64]bool)
+		query := base
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(nil, errors.New("osu.ClientV2.AllForumPosts: "+err.Error()))
+				return
+			}
+			var topic ForumTopicPosts
+			err := client.do(ctx, http.MethodGet, query, nil, &topic)
+			if err != nil {
+				yield(nil, errors.New("osu.ClientV2.AllForumPosts: "+err.Error()))
+				return
+			}
+			fresh := 0
+			for _, p := range topic.Posts {
+				if seen[p.ID] {
+					continue
+				}
+				seen[p.ID] = true
+				fresh++
+				if !yield(p, nil) {
+					return
+				}
+			}
+			if topic.Cursor == nil || *topic.Cursor == "" || fresh == 0 {
+				return
+			}
+			query = ForumTopicAfter(*topic.Cursor)(base)
+		}
+	}
+}
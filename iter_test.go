@@ -0,0 +1,142 @@
+package osu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serveBeatmaps answers get_beatmaps with the beatmaps approved at dates, in order, the way the API pages them
+func serveBeatmaps(t *testing.T, dates []string) http.RoundTripper {
+	return serve(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if len(q["since"]) > 1 || len(q["limit"]) > 1 {
+			t.Errorf("got query %q", r.URL.RawQuery)
+		}
+		limit := 500
+		if l := q.Get("limit"); l != "" {
+			limit, _ = strconv.Atoi(l)
+		}
+		out := []map[string]string{}
+		for i, d := range dates {
+			if s := q.Get("since"); s != "" && d <= s {
+				continue
+			}
+			if len(out) == limit {
+				break
+			}
+			out = append(out, map[string]string{"beatmap_id": strconv.Itoa(i + 1), "approved_date": d})
+		}
+		json.NewEncoder(w).Encode(out)
+	})
+}
+
+func TestAllBeatmaps(t *testing.T) {
+	day := func(n int) string {
+		return time.Date(2020, 1, n, 0, 0, 0, 0, time.UTC).Format("2006-01-02 15:04:05")
+	}
+	// five difficulties of a set share the second date
+	dates := []string{day(1), day(2), day(2), day(2), day(2), day(2), day(3), day(4)}
+	many := make([]string, 501)
+	for i := range many {
+		many[i] = day(1)
+	}
+	tests := []struct {
+		name  string
+		dates []string
+		opts  []BeatmapOption
+		want  int
+		err   bool
+	}{
+		{"one page", dates, nil, 8, false},
+		{"pages", dates, []BeatmapOption{BeatmapsLimit(3)}, 8, false},
+		{"page of one date", dates, []BeatmapOption{BeatmapsLimit(2)}, 8, false},
+		{"page of one date at the end", dates[:6], []BeatmapOption{BeatmapsLimit(1)}, 6, false},
+		{"since", dates, []BeatmapOption{BeatmapsLimit(3), BeatmapsSince(time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC))}, 2, false},
+		{"more than 500 on one date", many, []BeatmapOption{BeatmapsLimit(10)}, 500, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient("key")
+			client.c.Transport = serveBeatmaps(t, tt.dates)
+			seen := make(map[BeatmapID]bool)
+			var err error
+			for m, e := range client.AllBeatmaps(context.Background(), tt.opts...) {
+				if e != nil {
+					err = e
+					break
+				}
+				if seen[m.BeatmapID] {
+					t.Errorf("beatmap %d yielded twice", m.BeatmapID)
+				}
+				seen[m.BeatmapID] = true
+			}
+			if len(seen) != tt.want || (err != nil) != tt.err {
+				t.Errorf("got %d beatmaps and error %v, want %d and error %v", len(seen), err, tt.want, tt.err)
+			}
+		})
+	}
+}
+
+func TestAllBeatmapsCancel(t *testing.T) {
+	client := NewClient("key")
+	client.c.Transport = serveBeatmaps(t, []string{"2020-01-01 00:00:00"})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, err := range client.AllBeatmaps(ctx) {
+		if err == nil || !strings.Contains(err.Error(), "context canceled") {
+			t.Errorf("got error %v", err)
+		}
+	}
+}
+
+func TestAllComments(t *testing.T) {
+	pages := 0
+	client := NewClientV2("token")
+	client.c.Transport = serve(t, func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		id, _ := strconv.Atoi(r.URL.Query().Get("cursor[id]"))
+		if id == 0 {
+			id = 10
+		}
+		// pages overlap by one comment
+		fmt.Fprintf(w, `{"comments":[{"id":%d},{"id":%d}],"has_more":%v,"cursor":{"id":%d}}`, id, id-1, id > 4, id-1)
+	})
+	var ids []int64
+	for c, err := range client.AllComments(context.Background(), CommentsSort(CommentSortNew)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, c.ID)
+	}
+	if fmt.Sprint(ids) != "[10 9 8 7 6 5 4 3]" || pages != 7 {
+		t.Errorf("got comments %v in %d pages", ids, pages)
+	}
+}
+
+func TestAllForumPosts(t *testing.T) {
+	client := NewClientV2("token")
+	client.c.Transport = serve(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("cursor_string") {
+		case "":
+			w.Write([]byte(`{"posts":[{"id":1},{"id":2}],"cursor_string":"a"}`))
+		case "a":
+			w.Write([]byte(`{"posts":[{"id":3}],"cursor_string":null}`))
+		}
+	})
+	var ids []int64
+	for p, err := range client.AllForumPosts(context.Background(), 5) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, p.ID)
+	}
+	if fmt.Sprint(ids) != "[1 2 3]" {
+		t.Errorf("got posts %v", ids)
+	}
+}
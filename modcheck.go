@@ -0,0 +1,113 @@
+package osu
+
+import (
+	"strings"
+)
+
+// Groups of mods used to validate combinations
+const (
+	// UnrankedMods can be played but never give ranked scores
+	UnrankedMods = Relax | Relax2 | Autoplay | Cinema | Target | ScoreV2 | Key1 | Key2 | Key3 | KeyCoop
+	// DifficultyChangingMods change a beatmap's difficulty attributes or star rating
+	DifficultyChangingMods = Easy | HardRock | DoubleTime | Nightcore | HalfTime | Flashlight | Hidden | TouchDevice | KeyMod
+	// ManiaOnlyMods are only available in osu!mania
+	ManiaOnlyMods = KeyMod | FadeIn | Random | Mirror
+	// OsuOnlyMods are only available in osu!standard
+	OsuOnlyMods = TouchDevice | Relax2 | SpunOut | Target
+)
+
+// incompatibleMods lists the pairs of mods that can't be enabled together
+var incompatibleMods = [][2]Mod{
+	{Easy, HardRock},
+	{DoubleTime, HalfTime},
+	{Nightcore, HalfTime},
+	{NoFail, SuddenDeath},
+	{NoFail, Perfect},
+	{Hidden, FadeIn},
+	{Relax, Relax2},
+	{Relax, NoFail},
+	{Relax, SuddenDeath},
+	{Relax, Perfect},
+	{Relax, Autoplay},
+	{Relax2, NoFail},
+	{Relax2, SuddenDeath},
+	{Relax2, Perfect},
+	{Relax2, Autoplay},
+	{Relax2, SpunOut},
+	{Autoplay, NoFail},
+	{Autoplay, SuddenDeath},
+	{Autoplay, Perfect},
+	{Autoplay, SpunOut},
+	{Cinema, NoFail},
+	{Cinema, SuddenDeath},
+	{Cinema, Perfect},
+	{Cinema, Relax},
+	{Cinema, Relax2},
+	{Cinema, SpunOut},
+}
+
+// ModsError describes why a combination of mods can't be played
+type ModsError struct {
+	Mods     Mods
+	Mode     Mode
+	Problems []string
+}
+
+func (e *ModsError) Error() string {
+	return "osu: invalid mods " + e.Mods.Acronyms() + " for " + e.Mode.String() + ": " + strings.Join(e.Problems, "; ")
+}
+
+// Validate checks that the mods can be played together in the given mode, returning a *ModsError listing every problem found
+func (mods Mods) Validate(m Mode) error {
+	var problems []string
+	for _, pair := range incompatibleMods {
+		if mods.Has(pair[0]) && mods.Has(pair[1]) {
+			problems = append(problems, pair[0].String()+" is incompatible with "+pair[1].String())
+		}
+	}
+	if mods.Has(Nightcore) && !mods.Has(DoubleTime) {
+		problems = append(problems, "Nightcore requires DoubleTime")
+	}
+	if mods.Has(Perfect) && !mods.Has(SuddenDeath) {
+		problems = append(problems, "Perfect requires SuddenDeath")
+	}
+	if keys := mods & Mods(KeyMod&^KeyCoop); len(keys.List()) > 1 {
+		problems = append(problems, "only one key mod can be used, got "+keys.String())
+	}
+	if m != ModeMania && mods.HasAny(ManiaOnlyMods) {
+		problems = append(problems, (mods&Mods(ManiaOnlyMods)).String()+" is only available in mania")
+	}
+	if m != ModeOsu && mods.HasAny(OsuOnlyMods) {
+		problems = append(problems, (mods&Mods(OsuOnlyMods)).String()+" is only available in osu")
+	}
+	if m == ModeMania && mods.Has(Relax) {
+		problems = append(problems, "Relax is not available in mania")
+	}
+	if mods&^Mods(LastMod-1) != 0 {
+		problems = append(problems, "unknown mod bits set")
+	}
+	if len(problems) > 0 {
+		return &ModsError{Mods: mods, Mode: m, Problems: problems}
+	}
+	return nil
+}
+
+// Ranked reports whether a score set with the mods in the given mode can be ranked
+func (mods Mods) Ranked(m Mode) bool {
+	return mods.Validate(m) == nil && !mods.HasAny(UnrankedMods)
+}
+
+// DifficultyChanging returns the mods that change a beatmap's difficulty attributes or star rating
+func (mods Mods) DifficultyChanging() Mods {
+	return mods & Mods(DifficultyChangingMods)
+}
+
+// AllowedInFreeMod reports whether every mod can be picked by players in a multiplayer room with free mods enabled
+func (mods Mods) AllowedInFreeMod() bool {
+	return mods&^Mods(FreeModAllowed) == 0
+}
+
+// IncreasesScore reports whether any of the mods increase the score multiplier
+func (mods Mods) IncreasesScore() bool {
+	return mods.HasAny(ScoreIncreaseMods)
+}
@@ -0,0 +1,60 @@
+package osu
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestModsValidate(t *testing.T) {
+	tests := []struct {
+		mods     Mods
+		mode     Mode
+		problems int
+		ranked   bool
+	}{
+		{0, ModeOsu, 0, true},
+		{Mods(Hidden | HardRock | DoubleTime | Flashlight), ModeOsu, 0, true},
+		{Mods(Nightcore | DoubleTime), ModeTaiko, 0, true},
+		{Mods(Easy | HardRock), ModeOsu, 1, false},
+		{Mods(DoubleTime | HalfTime), ModeOsu, 1, false},
+		{Mods(Nightcore), ModeOsu, 1, false},
+		{Mods(Perfect), ModeOsu, 1, false},
+		{Mods(NoFail | Perfect | SuddenDeath), ModeOsu, 2, false},
+		{Mods(Key4), ModeMania, 0, true},
+		{Mods(Key4 | Key5), ModeMania, 1, false},
+		{Mods(Key4), ModeOsu, 1, false},
+		{Mods(FadeIn | Hidden), ModeMania, 1, false},
+		{Mods(SpunOut), ModeTaiko, 1, false},
+		{Mods(Relax), ModeMania, 1, false},
+		// Relax is valid but unranked
+		{Mods(Relax), ModeOsu, 0, false},
+		{Mods(ScoreV2), ModeOsu, 0, false},
+		{Mods(LastMod), ModeOsu, 1, false},
+	}
+	for _, tt := range tests {
+		err := tt.mods.Validate(tt.mode)
+		var me *ModsError
+		switch {
+		case tt.problems == 0 && err != nil:
+			t.Errorf("%s in %v: got %v", tt.mods.Acronyms(), tt.mode, err)
+		case tt.problems > 0 && (!errors.As(err, &me) || len(me.Problems) != tt.problems):
+			t.Errorf("%s in %v: got %v, want %d problems", tt.mods.Acronyms(), tt.mode, err, tt.problems)
+		}
+		if got := tt.mods.Ranked(tt.mode); got != tt.ranked {
+			t.Errorf("%s in %v: got ranked %v", tt.mods.Acronyms(), tt.mode, got)
+		}
+	}
+}
+
+func TestModsGroups(t *testing.T) {
+	mods := Mods(Hidden | NoFail | SpunOut | DoubleTime)
+	if got := mods.DifficultyChanging(); got != Mods(Hidden|DoubleTime) {
+		t.Errorf("got difficulty changing %v", got)
+	}
+	if !mods.IncreasesScore() || Mods(NoFail|Easy).IncreasesScore() {
+		t.Error("wrong IncreasesScore")
+	}
+	if !Mods(Hidden|HardRock).AllowedInFreeMod() || Mods(DoubleTime).AllowedInFreeMod() {
+		t.Error("wrong AllowedInFreeMod")
+	}
+}
@@ -0,0 +1,304 @@
+package osu
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Mod is used to represent which modifiers are applied to beatmaps
+type Mod int64
+
+// All mods
+const (
+	None   Mod = 0
+	NoFail Mod = 1 << (iota - 1)
+	Easy
+	TouchDevice
+	Hidden
+	HardRock
+	SuddenDeath
+	DoubleTime
+	Relax
+	HalfTime
+	Nightcore
+	Flashlight
+	Autoplay
+	SpunOut
+	Relax2
+	Perfect
+	Key4
+	Key5
+	Key6
+	Key7
+	Key8
+	FadeIn
+	Random
+	Cinema
+	Target
+	Key9
+	KeyCoop
+	Key1
+	Key3
+	Key2
+	ScoreV2
+	Mirror
+	LastMod
+	KeyMod            = Key1 | Key2 | Key3 | Key4 | Key5 | Key6 | Key7 | Key8 | Key9 | KeyCoop
+	FreeModAllowed    = NoFail | Easy | Hidden | HardRock | SuddenDeath | Flashlight | FadeIn | Relax | Relax2 | SpunOut | KeyMod
+	ScoreIncreaseMods = Hidden | HardRock | DoubleTime | Flashlight | FadeIn
+)
+
+var modNames = map[Mod]string{
+	None:        "None",
+	NoFail:      "NoFail",
+	Easy:        "Easy",
+	TouchDevice: "TouchDevice",
+	Hidden:      "Hidden",
+	HardRock:    "HardRock",
+	SuddenDeath: "SuddenDeath",
+	DoubleTime:  "DoubleTime",
+	Relax:       "Relax",
+	HalfTime:    "HalfTime",
+	Nightcore:   "Nightcore",
+	Flashlight:  "Flashlight",
+	Autoplay:    "Autoplay",
+	SpunOut:     "SpunOut",
+	Relax2:      "Relax2",
+	Perfect:     "Perfect",
+	Key4:        "Key4",
+	Key5:        "Key5",
+	Key6:        "Key6",
+	Key7:        "Key7",
+	Key8:        "Key8",
+	FadeIn:      "FadeIn",
+	Random:      "Random",
+	Cinema:      "Cinema",
+	Target:      "Target",
+	Key9:        "Key9",
+	KeyCoop:     "KeyCoop",
+	Key1:        "Key1",
+	Key3:        "Key3",
+	Key2:        "Key2",
+	ScoreV2:     "ScoreV2",
+	Mirror:      "Mirror",
+	LastMod:     "LastMod",
+}
+
+var modAcronyms = map[Mod]string{
+	NoFail:      "NF",
+	Easy:        "EZ",
+	TouchDevice: "TD",
+	Hidden:      "HD",
+	HardRock:    "HR",
+	SuddenDeath: "SD",
+	DoubleTime:  "DT",
+	Relax:       "RX",
+	HalfTime:    "HT",
+	Nightcore:   "NC",
+	Flashlight:  "FL",
+	Autoplay:    "AT",
+	SpunOut:     "SO",
+	Relax2:      "AP",
+	Perfect:     "PF",
+	Key4:        "4K",
+	Key5:        "5K",
+	Key6:        "6K",
+	Key7:        "7K",
+	Key8:        "8K",
+	FadeIn:      "FI",
+	Random:      "RD",
+	Cinema:      "CN",
+	Target:      "TP",
+	Key9:        "9K",
+	KeyCoop:     "CO",
+	Key1:        "1K",
+	Key3:        "3K",
+	Key2:        "2K",
+	ScoreV2:     "V2",
+	Mirror:      "MR",
+}
+
+// String returns the name of a single mod, or the names of every mod in a combination
+func (m Mod) String() string {
+	if name, ok := modNames[m]; ok {
+		return name
+	}
+	return Mods(m).String()
+}
+
+// Acronym returns the two character acronym of a single mod, e.g. "HD" for Hidden, or "" if m is not a single known mod
+func (m Mod) Acronym() string {
+	return modAcronyms[m]
+}
+
+// Mods represents a combination of mods
+type Mods int64
+
+func (mods Mods) String() string {
+	l := mods.List()
+	if len(l) == 0 {
+		return "[]"
+	}
+	ret := ""
+	for _, v := range l {
+		ret += ", " + v.String()
+	}
+	return "[" + ret[2:] + "]"
+}
+
+// List returns a slice containing the individual mods contained by a field
+func (mods Mods) List() []Mod {
+	num := int64(mods)
+	list := make([]Mod, 0)
+	i := uint(0)
+	for num != 0 {
+		if num&1 == 1 {
+			list = append(list, Mod(1<<i))
+		}
+		i++
+		num >>= 1
+	}
+	return list
+}
+
+// Acronyms returns the mods as a string of acronyms, e.g. "HDDT", or "NM" if there are none.
+// DoubleTime is left out when Nightcore is present, and SuddenDeath when Perfect is present
+func (mods Mods) Acronyms() string {
+	if mods == 0 {
+		return "NM"
+	}
+	ret := ""
+	for _, m := range mods.List() {
+		if m == DoubleTime && mods.Has(Nightcore) || m == SuddenDeath && mods.Has(Perfect) {
+			continue
+		}
+		if a := m.Acronym(); a != "" {
+			ret += a
+		} else {
+			ret += "(" + strconv.FormatInt(int64(m), 10) + ")"
+		}
+	}
+	return ret
+}
+
+// Has reports whether every mod in m is present
+func (mods Mods) Has(m Mod) bool {
+	return mods&Mods(m) == Mods(m)
+}
+
+// HasAny reports whether any mod in m is present
+func (mods Mods) HasAny(m Mod) bool {
+	return mods&Mods(m) != 0
+}
+
+// With returns the mods with m added, along with any mods m implies (Nightcore implies DoubleTime, Perfect implies SuddenDeath)
+func (mods Mods) With(m Mod) Mods {
+	return (mods | Mods(m)).Normalize()
+}
+
+// Without returns the mods with m removed, along with any mods that imply m
+func (mods Mods) Without(m Mod) Mods {
+	mods &^= Mods(m)
+	if !mods.Has(DoubleTime) {
+		mods &^= Mods(Nightcore)
+	}
+	if !mods.Has(SuddenDeath) {
+		mods &^= Mods(Perfect)
+	}
+	return mods
+}
+
+// Normalize adds the mods implied by others, the way the game submits them: Nightcore implies DoubleTime and Perfect implies SuddenDeath
+func (mods Mods) Normalize() Mods {
+	if mods.Has(Nightcore) {
+		mods |= Mods(DoubleTime)
+	}
+	if mods.Has(Perfect) {
+		mods |= Mods(SuddenDeath)
+	}
+	return mods
+}
+
+// ParseMods parses a combination of mods. It accepts acronyms with or without separators ("HDDT", "+HD,HR", "HD HR"),
+// mod names ("Hidden, DoubleTime"), "NM" or "None" for no mods, the numbers in parentheses Acronyms writes for bits
+// without an acronym ("HD(4194304)") and the numeric bitfield the API uses ("72").
+// The result is normalized
+func ParseMods(s string) (Mods, error) {
+	s = strings.Trim(strings.TrimSpace(s), "[]")
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return Mods(n).Normalize(), nil
+	}
+	var mods Mods
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == '+' || r == '|' || r == ' '
+	})
+	for _, field := range fields {
+		if strings.EqualFold(field, "NM") || strings.EqualFold(field, "None") {
+			continue
+		}
+		if m, ok := modByName(field); ok {
+			mods |= Mods(m)
+			continue
+		}
+		for i := 0; i < len(field); {
+			// bits without an acronym are written as their number in parentheses
+			if field[i] == '(' {
+				end := strings.IndexByte(field[i:], ')')
+				if end < 0 {
+					return 0, errors.New("osu.ParseMods: invalid mods " + strconv.Quote(s))
+				}
+				n, err := strconv.ParseInt(field[i+1:i+end], 10, 64)
+				if err != nil || n <= 0 {
+					return 0, errors.New("osu.ParseMods: invalid mods " + strconv.Quote(s))
+				}
+				mods |= Mods(n)
+				i += end + 1
+				continue
+			}
+			if i+2 > len(field) {
+				return 0, errors.New("osu.ParseMods: invalid mods " + strconv.Quote(s))
+			}
+			m, ok := modByAcronym(field[i : i+2])
+			if !ok {
+				return 0, errors.New("osu.ParseMods: unknown mod " + strconv.Quote(field[i:i+2]))
+			}
+			mods |= Mods(m)
+			i += 2
+		}
+	}
+	return mods.Normalize(), nil
+}
+
+// MarshalText satisfies the encoding.TextMarshaler interface
+func (mods Mods) MarshalText() ([]byte, error) {
+	return []byte(mods.Acronyms()), nil
+}
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface
+func (mods *Mods) UnmarshalText(text []byte) error {
+	m, err := ParseMods(string(text))
+	if err != nil {
+		return err
+	}
+	*mods = m
+	return nil
+}
+
+func modByName(name string) (Mod, bool) {
+	for m, n := range modNames {
+		if m != LastMod && strings.EqualFold(n, name) {
+			return m, true
+		}
+	}
+	return 0, false
+}
+
+func modByAcronym(acronym string) (Mod, bool) {
+	for m, a := range modAcronyms {
+		if strings.EqualFold(a, acronym) {
+			return m, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,90 @@
+package osu
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseMods(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Mods
+	}{
+		{"HDDT", Mods(Hidden | DoubleTime)},
+		{"+HD,HR", Mods(Hidden | HardRock)},
+		{"hd hr", Mods(Hidden | HardRock)},
+		{"NM", 0},
+		{"None", 0},
+		{"", 0},
+		{"NC", Mods(Nightcore | DoubleTime)},
+		{"PF", Mods(Perfect | SuddenDeath)},
+		{"Hidden, DoubleTime", Mods(Hidden | DoubleTime)},
+		{"[Hidden, DoubleTime]", Mods(Hidden | DoubleTime)},
+		{"72", Mods(Hidden | DoubleTime)},
+		{"4K", Mods(Key4)},
+		{"HD(1099511627776)", Mods(Hidden) | 1099511627776},
+		{"(2147483648)", Mods(LastMod)},
+	}
+	for _, tt := range tests {
+		got, err := ParseMods(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("%q: got %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"HDX", "ZZ", "HD(", "(abc)", "(0)", "(-8)"} {
+		if _, err := ParseMods(in); err == nil {
+			t.Errorf("%q: no error", in)
+		}
+	}
+}
+
+func TestModsAcronyms(t *testing.T) {
+	tests := []struct {
+		mods Mods
+		want string
+	}{
+		{0, "NM"},
+		{Mods(Hidden | HardRock), "HDHR"},
+		{Mods(Nightcore | DoubleTime | Hidden), "HDNC"},
+		{Mods(Perfect | SuddenDeath), "PF"},
+		{Mods(Hidden) | 1099511627776, "HD(1099511627776)"},
+		{Mods(LastMod), "(2147483648)"},
+	}
+	for _, tt := range tests {
+		if got := tt.mods.Acronyms(); got != tt.want {
+			t.Errorf("%d: got %q, want %q", tt.mods, got, tt.want)
+		}
+	}
+}
+
+func TestModsTextRoundTrip(t *testing.T) {
+	for _, mods := range []Mods{0, Mods(Hidden | DoubleTime), Mods(Nightcore | DoubleTime), Mods(Key4 | Mirror), Mods(LastMod), Mods(Hidden) | 1099511627776 | Mods(Cinema)} {
+		data, err := json.Marshal(mods)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Mods
+		if err := json.Unmarshal(data, &got); err != nil || got != mods {
+			t.Errorf("%d: got %d, %v from %s", mods, got, err, data)
+		}
+	}
+}
+
+func TestModsSet(t *testing.T) {
+	mods := Mods(Hidden).With(Nightcore)
+	if mods != Mods(Hidden|Nightcore|DoubleTime) {
+		t.Errorf("got %v", mods)
+	}
+	if mods.Without(DoubleTime) != Mods(Hidden) {
+		t.Errorf("got %v without DoubleTime", mods.Without(DoubleTime))
+	}
+	if mods.Without(Nightcore) != Mods(Hidden|DoubleTime) {
+		t.Errorf("got %v without Nightcore", mods.Without(Nightcore))
+	}
+	if !mods.Has(Hidden|DoubleTime) || mods.Has(Hidden|HardRock) || !mods.HasAny(Hidden|HardRock) || mods.HasAny(HardRock|Easy) {
+		t.Error("wrong Has or HasAny")
+	}
+	if got := Mods(Hidden | DoubleTime).String(); got != "[Hidden, DoubleTime]" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,344 @@
+package osu
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pixelrazor/osu/internal/osubin"
+	"github.com/ulikunitz/xz/lzma"
+)
+
+// replayVersion is the version written to replays built from the API
+const replayVersion = 20210520
+
+// LifeBarPoint is a point of the life bar graph shown on the results screen
+type LifeBarPoint struct {
+	// Time since the start of the beatmap
+	Time time.Duration
+	// Life from 0 - 1
+	Life float64
+}
+
+// ReplayFile holds the contents of an .osr replay file
+type ReplayFile struct {
+	Mode Mode
+	// Version is the game version that set the score, e.g. 20210520
+	Version    int32
+	BeatmapMD5 string
+	PlayerName string
+	// ReplayMD5 is the hash osu! uses to identify the score
+	ReplayMD5   string
+	Count300    int64
+	Count100    int64
+	Count50     int64
+	Countgeki   int64
+	Countkatu   int64
+	Countmiss   int64
+	Score       int64
+	Maxcombo    int64
+	Perfect     bool
+	EnabledMods Mods
+	LifeBar     []LifeBarPoint
+	Timestamp   time.Time
+	Content     ReplayContent
+	// Skipped holds the frames that were malformed and left out of Content
+	Skipped []*FrameError
+	// ScoreID is 0 for scores that weren't submitted
+	ScoreID ScoreID
+	// TargetAccuracy is the accuracy of a Target Practice play, from 0 - 1
+	TargetAccuracy float64
+	// LazerData holds the JSON score information osu!lazer appends to its replays
+	LazerData json.RawMessage
+}
+
+// ReadReplayFile reads the .osr file at path
+func ReadReplayFile(path string) (*ReplayFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.New("osu.ReadReplayFile: " + err.Error())
+	}
+	defer f.Close()
+	rf, err := DecodeReplayFile(f)
+	if err != nil {
+		return nil, errors.New("osu.ReadReplayFile: " + err.Error())
+	}
+	return rf, nil
+}
+
+// DecodeReplayFile reads an .osr replay file
+func DecodeReplayFile(r io.Reader) (*ReplayFile, error) {
+	br := osubin.NewReader(bufio.NewReader(r))
+	rp := br.Replay()
+	rf := &ReplayFile{
+		Mode:           Mode(rp.Mode),
+		Version:        rp.Version,
+		BeatmapMD5:     rp.BeatmapMD5,
+		PlayerName:     rp.PlayerName,
+		ReplayMD5:      rp.ReplayMD5,
+		Count300:       int64(rp.Count300),
+		Count100:       int64(rp.Count100),
+		Count50:        int64(rp.Count50),
+		Countgeki:      int64(rp.Countgeki),
+		Countkatu:      int64(rp.Countkatu),
+		Countmiss:      int64(rp.Countmiss),
+		Score:          int64(rp.Score),
+		Maxcombo:       int64(rp.Maxcombo),
+		Perfect:        rp.Perfect,
+		EnabledMods:    Mods(rp.Mods),
+		Timestamp:      rp.Timestamp,
+		ScoreID:        ScoreID(rp.ScoreID),
+		TargetAccuracy: rp.TargetAccuracy,
+	}
+	if err := br.Err(); err != nil {
+		return nil, errors.New("osu.DecodeReplayFile: " + err.Error())
+	}
+	if !br.AtEOF() {
+		extra := br.Bytes(int(br.Int32()))
+		if err := br.Err(); err != nil {
+			return nil, errors.New("osu.DecodeReplayFile: lazer data: " + err.Error())
+		}
+		reader, err := lzma.NewReader(bytes.NewReader(extra))
+		if err != nil {
+			return nil, errors.New("osu.DecodeReplayFile: lazer data: " + err.Error())
+		}
+		if rf.LazerData, err = ioutil.ReadAll(reader); err != nil {
+			return nil, errors.New("osu.DecodeReplayFile: lazer data: " + err.Error())
+		}
+	}
+	var err error
+	if rf.LifeBar, err = parseLifeBar(rp.LifeBar); err != nil {
+		return nil, errors.New("osu.DecodeReplayFile: life bar: " + err.Error())
+	}
+	if rf.Content, rf.Skipped, err = decodeReplayData(rp.Data, false); err != nil {
+		return nil, errors.New("osu.DecodeReplayFile: replay data: " + err.Error())
+	}
+	return rf, nil
+}
+
+// parseLifeBar parses a life bar graph written as "time|life,time|life,..."
+func parseLifeBar(s string) ([]LifeBarPoint, error) {
+	var points []LifeBarPoint
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		parts := strings.Split(p, "|")
+		if len(parts) != 2 {
+			return nil, errors.New("invalid point " + strconv.Quote(p))
+		}
+		ms, err := strconv.ParseFloat(parts[0], 64)
+		if err != nil {
+			return nil, err
+		}
+		life, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, LifeBarPoint{time.Duration(ms * float64(time.Millisecond)), life})
+	}
+	return points, nil
+}
+
+// Hits returns the judgement counts of the replay
+func (rf *ReplayFile) Hits() Hits {
+	return Hits{rf.Count300, rf.Count100, rf.Count50, rf.Countmiss, rf.Countgeki, rf.Countkatu}
+}
+
+// Accuracy returns the accuracy of the replay, from 0 to 1
+func (rf *ReplayFile) Accuracy() float64 {
+	return rf.Hits().Accuracy(rf.Mode)
+}
+
+// Grade calculates the rank the replay is given
+func (rf *ReplayFile) Grade() Rank {
+	return rf.Hits().Grade(rf.Mode, rf.EnabledMods)
+}
+
+// WriteFile writes the replay to an .osr file at path
+func (rf *ReplayFile) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.New("osu.ReplayFile.WriteFile: " + err.Error())
+	}
+	if err := rf.Encode(f); err != nil {
+		f.Close()
+		return errors.New("osu.ReplayFile.WriteFile: " + err.Error())
+	}
+	if err := f.Close(); err != nil {
+		return errors.New("osu.ReplayFile.WriteFile: " + err.Error())
+	}
+	return nil
+}
+
+// Encode writes the replay as an .osr file, compressing Content and LazerData with LZMA
+func (rf *ReplayFile) Encode(w io.Writer) error {
+	data, err := encodeReplayData(rf.Content)
+	if err != nil {
+		return errors.New("osu.ReplayFile.Encode: replay data: " + err.Error())
+	}
+	var lazer []byte
+	if len(rf.LazerData) > 0 {
+		if lazer, err = compressLZMA(rf.LazerData); err != nil {
+			return errors.New("osu.ReplayFile.Encode: lazer data: " + err.Error())
+		}
+	}
+	bw := bufio.NewWriter(w)
+	ow := osubin.NewWriter(bw)
+	ow.Replay(osubin.Replay{
+		Mode:           byte(rf.Mode),
+		Version:        rf.Version,
+		BeatmapMD5:     rf.BeatmapMD5,
+		PlayerName:     rf.PlayerName,
+		ReplayMD5:      rf.ReplayMD5,
+		Count300:       uint16(rf.Count300),
+		Count100:       uint16(rf.Count100),
+		Count50:        uint16(rf.Count50),
+		Countgeki:      uint16(rf.Countgeki),
+		Countkatu:      uint16(rf.Countkatu),
+		Countmiss:      uint16(rf.Countmiss),
+		Score:          int32(rf.Score),
+		Maxcombo:       uint16(rf.Maxcombo),
+		Perfect:        rf.Perfect,
+		Mods:           uint32(rf.EnabledMods),
+		LifeBar:        formatLifeBar(rf.LifeBar),
+		Timestamp:      rf.Timestamp,
+		Data:           data,
+		ScoreID:        int64(rf.ScoreID),
+		TargetAccuracy: rf.TargetAccuracy,
+	})
+	if lazer != nil {
+		ow.Int32(int32(len(lazer)))
+		ow.Bytes(lazer)
+	}
+	if err := ow.Err(); err != nil {
+		return errors.New("osu.ReplayFile.Encode: " + err.Error())
+	}
+	if err := bw.Flush(); err != nil {
+		return errors.New("osu.ReplayFile.Encode: " + err.Error())
+	}
+	return nil
+}
+
+// formatLifeBar writes a life bar graph as "time|life,time|life,..."
+func formatLifeBar(points []LifeBarPoint) string {
+	var s strings.Builder
+	for _, p := range points {
+		s.WriteString(strconv.FormatFloat(float64(p.Time)/float64(time.Millisecond), 'f', -1, 64))
+		s.WriteString("|" + strconv.FormatFloat(p.Life, 'f', -1, 64) + ",")
+	}
+	return s.String()
+}
+
+// ReplayScore is a score a replay file can be built for: a *Score, *BestScore or *RecentScore
+type ReplayScore interface {
+	// setReplayScore copies the score into rf and returns its rank
+	setReplayScore(rf *ReplayFile) Rank
+}
+
+func (s *Score) setReplayScore(rf *ReplayFile) Rank {
+	if rf.PlayerName == "" {
+		rf.PlayerName = s.Username
+	}
+	rf.ScoreID = s.ScoreID
+	rf.Score = s.Score
+	rf.Maxcombo = s.Maxcombo
+	rf.Perfect = bool(s.Perfect)
+	rf.EnabledMods = s.EnabledMods
+	rf.Timestamp = s.Date
+	rf.setHits(s.Hits())
+	return s.Rank
+}
+
+func (s *BestScore) setReplayScore(rf *ReplayFile) Rank {
+	rf.ScoreID = s.ScoreID
+	rf.Score = s.Score
+	rf.Maxcombo = s.Maxcombo
+	rf.Perfect = bool(s.Perfect)
+	rf.EnabledMods = s.EnabledMods
+	rf.Timestamp = s.Date
+	rf.setHits(s.Hits())
+	return s.Rank
+}
+
+func (s *RecentScore) setReplayScore(rf *ReplayFile) Rank {
+	rf.Score = s.Score
+	rf.Maxcombo = s.Maxcombo
+	rf.Perfect = bool(s.Perfect)
+	rf.EnabledMods = s.EnabledMods
+	rf.Timestamp = s.Date
+	rf.setHits(s.Hits())
+	return s.Rank
+}
+
+// NewReplayFile builds a complete replay from the frames returned by Client.Replay, the score they belong to
+// and the beatmap it was set on. player is the name written to the replay; when empty, the username of a *Score is used
+func NewReplayFile(replay *Replay, score ReplayScore, beatmap *Beatmap, player string) *ReplayFile {
+	rf := &ReplayFile{
+		Mode:       beatmap.Mode,
+		Version:    replayVersion,
+		BeatmapMD5: beatmap.FileMd5,
+		PlayerName: player,
+		Content:    replay.Content,
+		Skipped:    replay.Skipped,
+	}
+	rank := score.setReplayScore(rf)
+	rf.ReplayMD5 = rf.Hash(rank)
+	return rf
+}
+
+func (rf *ReplayFile) setHits(h Hits) {
+	rf.Count300, rf.Count100, rf.Count50, rf.Countmiss, rf.Countgeki, rf.Countkatu = h.Count300, h.Count100, h.Count50, h.Countmiss, h.Countgeki, h.Countkatu
+}
+
+// Hash returns the replay MD5 osu! expects for the replay's score with the given rank, which is what ReplayMD5 should be set to.
+// A rank of RankNone is calculated from the hits
+func (rf *ReplayFile) Hash(rank Rank) string {
+	if rank == RankNone {
+		rank = rf.Grade()
+	}
+	s := fmt.Sprintf("%dp%do%do%dt%da%sr%de%sy%so%du%s%d%s",
+		rf.Count100+rf.Count300, rf.Count50, rf.Countgeki, rf.Countkatu, rf.Countmiss,
+		rf.BeatmapMD5, rf.Maxcombo, formatDotNetBool(rf.Perfect), rf.PlayerName, rf.Score, rank, int32(rf.EnabledMods), "True")
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// formatDotNetBool formats a bool the way .NET does, which osu! uses when hashing replays
+func formatDotNetBool(b bool) string {
+	if b {
+		return "True"
+	}
+	return "False"
+}
+
+// ToScore converts the replay's score to a Score, as the API would return it without a user ID or pp
+func (rf *ReplayFile) ToScore() *Score {
+	return &Score{
+		ScoreID:         rf.ScoreID,
+		Score:           rf.Score,
+		Username:        rf.PlayerName,
+		Count300:        rf.Count300,
+		Count100:        rf.Count100,
+		Count50:         rf.Count50,
+		Countmiss:       rf.Countmiss,
+		Maxcombo:        rf.Maxcombo,
+		Countkatu:       rf.Countkatu,
+		Countgeki:       rf.Countgeki,
+		Perfect:         Flag(rf.Perfect),
+		EnabledMods:     rf.EnabledMods,
+		Date:            rf.Timestamp,
+		Rank:            rf.Grade(),
+		ReplayAvailable: len(rf.Content) > 0,
+	}
+}
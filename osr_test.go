@@ -0,0 +1,341 @@
+package osu
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pixelrazor/osu/internal/osubin"
+)
+
+func testReplay(t *testing.T) *ReplayFile {
+	t.Helper()
+	// the frames include the seed frame and a negative delta, which osu! writes when the game rewinds
+	content, skipped, err := ParseReplayFrames("0|256|-500|0,-1|256|-500|0,16|100.5|200.25|5,-3|101|201|5,17|102|202|10,-12345|0|0|16516604,", true)
+	if err != nil || len(skipped) > 0 {
+		t.Fatal(err, skipped)
+	}
+	return &ReplayFile{
+		Mode:        ModeOsu,
+		Version:     20210520,
+		BeatmapMD5:  "0123456789abcdef0123456789abcdef",
+		PlayerName:  "player",
+		ReplayMD5:   "fedcba9876543210fedcba9876543210",
+		Count300:    300,
+		Count100:    12,
+		Count50:     3,
+		Countgeki:   60,
+		Countkatu:   8,
+		Countmiss:   1,
+		Score:       12345678,
+		Maxcombo:    456,
+		Perfect:     false,
+		EnabledMods: Mods(Hidden | HardRock),
+		LifeBar:     []LifeBarPoint{{Time: 0, Life: 1}, {Time: 1500 * time.Millisecond, Life: 0.75}},
+		Timestamp:   time.Date(2021, 5, 20, 12, 30, 15, 0, time.UTC),
+		Content:     content,
+		// the score ID doesn't fit in 32 bits
+		ScoreID: 4000000000,
+	}
+}
+
+func roundTrip(t *testing.T, rf *ReplayFile) *ReplayFile {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := rf.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecodeReplayFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Timestamp.Equal(rf.Timestamp) {
+		t.Errorf("got timestamp %v, want %v", got.Timestamp, rf.Timestamp)
+	}
+	got.Timestamp = rf.Timestamp
+	return got
+}
+
+func TestReplayFileRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(rf *ReplayFile)
+	}{
+		{"score ID 64", func(rf *ReplayFile) {}},
+		{"score ID 32", func(rf *ReplayFile) {
+			rf.Version = 20130101
+			rf.ScoreID = 123456789
+		}},
+		{"no score ID", func(rf *ReplayFile) {
+			rf.Version = 20120101
+			rf.ScoreID = 0
+		}},
+		{"target accuracy", func(rf *ReplayFile) {
+			rf.EnabledMods = Mods(Target)
+			rf.TargetAccuracy = 0.9375
+		}},
+		{"lazer data", func(rf *ReplayFile) {
+			rf.LazerData = json.RawMessage(`{"online_id":4000000000,"mods":[{"acronym":"HD"},{"acronym":"HR"}],"statistics":{"great":300}}`)
+		}},
+		{"no frames", func(rf *ReplayFile) {
+			rf.Content = ReplayContent{}
+			rf.LifeBar = nil
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := testReplay(t)
+			tt.edit(want)
+			got := roundTrip(t, want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got  %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestReplayFileFrames(t *testing.T) {
+	got := roundTrip(t, testReplay(t))
+	seed, ok := got.Content.Seed()
+	if !ok || seed != 16516604 {
+		t.Errorf("got seed %d, %v, want 16516604", seed, ok)
+	}
+	// negative deltas move time back, and the seed frame doesn't move it
+	times := []time.Duration{0, -1, 15, 12, 29, 29}
+	if len(got.Content) != len(times) {
+		t.Fatalf("got %d frames, want %d", len(got.Content), len(times))
+	}
+	for i, p := range got.Content {
+		if p.Time != times[i]*time.Millisecond {
+			t.Errorf("frame %d: got time %v, want %v", i, p.Time, times[i]*time.Millisecond)
+		}
+	}
+}
+
+func TestDecodeReplayFileTruncated(t *testing.T) {
+	rf := testReplay(t)
+	var plain bytes.Buffer
+	if err := rf.Encode(&plain); err != nil {
+		t.Fatal(err)
+	}
+	rf.LazerData = json.RawMessage(`{"online_id":1}`)
+	var buf bytes.Buffer
+	if err := rf.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	for n := 0; n < len(data); n++ {
+		// without its lazer data the replay is complete
+		if n == plain.Len() {
+			continue
+		}
+		if _, err := DecodeReplayFile(bytes.NewReader(data[:n])); err == nil {
+			t.Errorf("no error decoding the first %d of %d bytes", n, len(data))
+		}
+	}
+}
+
+// writeReplayHeader writes the fields of an .osr file up to its replay data with the osubin writer,
+// independently of ReplayFile.Encode
+func writeReplayHeader(w *osubin.Writer, version int32, mods Mods, lifeBar string) {
+	w.Byte(byte(ModeTaiko))
+	w.Int32(version)
+	w.String("0123456789abcdef0123456789abcdef")
+	w.String("player")
+	w.String("fedcba9876543210fedcba9876543210")
+	// counts and the max combo are unsigned 16-bit
+	for _, n := range []uint16{40000, 12, 0, 3, 4, 1} {
+		w.Int16(int16(n))
+	}
+	w.Int32(1000000)
+	combo := uint16(40015)
+	w.Int16(int16(combo))
+	w.Bool(true)
+	w.Int32(int32(mods))
+	w.String(lifeBar)
+	w.DateTime(time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC))
+}
+
+func TestDecodeReplayFile(t *testing.T) {
+	frames, err := compressLZMA([]byte("16|1|2|5,x,16|3|4|0,"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w := osubin.NewWriter(&buf)
+	writeReplayHeader(w, 20130101, Mods(DoubleTime|Hidden), "0|1,1500.5|0.5,")
+	w.Int32(int32(len(frames)))
+	w.Bytes(frames)
+	w.Int32(123456789)
+	if err := w.Err(); err != nil {
+		t.Fatal(err)
+	}
+	rf, err := DecodeReplayFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rf.Mode != ModeTaiko || rf.Version != 20130101 || rf.PlayerName != "player" || rf.ScoreID != 123456789 {
+		t.Errorf("got %+v", rf)
+	}
+	if rf.Count300 != 40000 || rf.Count100 != 12 || rf.Countgeki != 3 || rf.Countkatu != 4 || rf.Countmiss != 1 || rf.Maxcombo != 40015 {
+		t.Errorf("got hits %+v and max combo %d", rf.Hits(), rf.Maxcombo)
+	}
+	if !rf.Perfect || rf.EnabledMods != Mods(DoubleTime|Hidden) || !rf.Timestamp.Equal(time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("got perfect %v, mods %v and timestamp %v", rf.Perfect, rf.EnabledMods, rf.Timestamp)
+	}
+	wantLife := []LifeBarPoint{{0, 1}, {1500500 * time.Microsecond, 0.5}}
+	if !reflect.DeepEqual(rf.LifeBar, wantLife) {
+		t.Errorf("got life bar %v", rf.LifeBar)
+	}
+	if len(rf.Content) != 2 || rf.Content[1].Time != 32*time.Millisecond || len(rf.Skipped) != 1 || rf.Skipped[0].Index != 1 {
+		t.Errorf("got %d frames and skipped %v", len(rf.Content), rf.Skipped)
+	}
+}
+
+func TestDecodeReplayFileErrors(t *testing.T) {
+	tests := []struct {
+		err     string
+		lifeBar string
+		frames  []byte
+	}{
+		{"life bar", "0|1,oops,", nil},
+		{"life bar", "0|x,", nil},
+		{"replay data", "", []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14}},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		w := osubin.NewWriter(&buf)
+		writeReplayHeader(w, 20210520, 0, tt.lifeBar)
+		w.Int32(int32(len(tt.frames)))
+		w.Bytes(tt.frames)
+		w.Int64(1)
+		if _, err := DecodeReplayFile(&buf); err == nil || !strings.HasPrefix(err.Error(), "osu.DecodeReplayFile: "+tt.err) {
+			t.Errorf("%q, %v: got %v, want a %s error", tt.lifeBar, tt.frames, err, tt.err)
+		}
+	}
+}
+
+func TestReplayFileToScore(t *testing.T) {
+	rf := testReplay(t)
+	s := rf.ToScore()
+	want := &Score{
+		ScoreID:         4000000000,
+		Score:           12345678,
+		Username:        "player",
+		Count300:        300,
+		Count100:        12,
+		Count50:         3,
+		Countmiss:       1,
+		Maxcombo:        456,
+		Countkatu:       8,
+		Countgeki:       60,
+		EnabledMods:     Mods(Hidden | HardRock),
+		Date:            rf.Timestamp,
+		Rank:            RankA,
+		ReplayAvailable: true,
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("got  %+v\nwant %+v", s, want)
+	}
+	if acc := rf.Accuracy(); acc != s.Accuracy(ModeOsu) || acc != rf.Hits().Accuracy(ModeOsu) {
+		t.Errorf("got accuracy %v from the replay and %v from the score", acc, s.Accuracy(ModeOsu))
+	}
+	rf.Countmiss, rf.Count100, rf.Count50 = 0, 0, 0
+	if g := rf.Grade(); g != RankSSH {
+		t.Errorf("got grade %v, want %v", g, RankSSH)
+	}
+}
+
+func TestNewReplayFile(t *testing.T) {
+	date := time.Date(2013, 6, 22, 9, 11, 16, 0, time.UTC)
+	replay := &Replay{
+		Content: ReplayContent{{TimeSinceLast: 16 * time.Millisecond, Time: 16 * time.Millisecond, X: 1, Y: 2, Keys: KeyM1 | KeyK1}},
+		Skipped: []*FrameError{{Index: 1, Text: "bad"}},
+	}
+	beatmap := &Beatmap{Mode: ModeTaiko, FileMd5: "c8f08438204abfcdd1a748ebfae67421"}
+	tests := []struct {
+		name    string
+		score   ReplayScore
+		player  string
+		want    string
+		scoreID ScoreID
+		// hash is the string osu! hashes for ReplayMD5
+		hash string
+	}{
+		{"score", &Score{ScoreID: 7654321, Username: "User name", Score: 1234567, Maxcombo: 321, Count300: 300, Count100: 50, Count50: 10,
+			Countmiss: 1, Countgeki: 50, Countkatu: 10, EnabledMods: 76, Date: date, Rank: RankSH}, "", "User name", 7654321,
+			"350p10o50o10t1ac8f08438204abfcdd1a748ebfae67421r321eFalseyUser nameo1234567uSH76True"},
+		{"score with player", &Score{ScoreID: 7654321, Username: "User name", Score: 1234567, Maxcombo: 321, Count300: 300, Count100: 50,
+			Count50: 10, Countmiss: 1, Countgeki: 50, Countkatu: 10, EnabledMods: 76, Date: date, Rank: RankSH}, "Other", "Other", 7654321,
+			"350p10o50o10t1ac8f08438204abfcdd1a748ebfae67421r321eFalseyOthero1234567uSH76True"},
+		{"best score", &BestScore{ScoreID: 7654321, Score: 1234567, Maxcombo: 421, Count300: 300, Count100: 50, Count50: 10, Countmiss: 1,
+			Countgeki: 50, Countkatu: 10, Perfect: true, EnabledMods: 76, Date: date, Rank: RankSH}, "User name", "User name", 7654321,
+			"350p10o50o10t1ac8f08438204abfcdd1a748ebfae67421r421eTrueyUser nameo1234567uSH76True"},
+		// recent scores have no score ID
+		{"recent score", &RecentScore{Score: 1234567, Maxcombo: 421, Count300: 300, Count100: 50, Count50: 10, Countmiss: 1,
+			Countgeki: 50, Countkatu: 10, EnabledMods: 76, Date: date, Rank: RankF}, "User name", "User name", 0,
+			"350p10o50o10t1ac8f08438204abfcdd1a748ebfae67421r421eFalseyUser nameo1234567uF76True"},
+	}
+	for _, tt := range tests {
+		rf := NewReplayFile(replay, tt.score, beatmap, tt.player)
+		if rf.Mode != ModeTaiko || rf.Version != replayVersion || rf.BeatmapMD5 != beatmap.FileMd5 || rf.PlayerName != tt.want {
+			t.Errorf("%s: got mode %v, version %d, beatmap %q and player %q", tt.name, rf.Mode, rf.Version, rf.BeatmapMD5, rf.PlayerName)
+		}
+		if rf.ScoreID != tt.scoreID || rf.Score != 1234567 || rf.Count100 != 50 || rf.Countgeki != 50 || rf.EnabledMods != 76 || !rf.Timestamp.Equal(date) {
+			t.Errorf("%s: got %+v", tt.name, rf)
+		}
+		if len(rf.Content) != 1 || len(rf.Skipped) != 1 {
+			t.Errorf("%s: got %d frames and %d skipped", tt.name, len(rf.Content), len(rf.Skipped))
+		}
+		sum := md5.Sum([]byte(tt.hash))
+		if want := hex.EncodeToString(sum[:]); rf.ReplayMD5 != want {
+			t.Errorf("%s: got replay MD5 %s, want %s", tt.name, rf.ReplayMD5, want)
+		}
+	}
+}
+
+func TestClientReplayFile(t *testing.T) {
+	data, err := encodeReplayData(ReplayContent{{TimeSinceLast: 16 * time.Millisecond, X: 1, Y: 2, Keys: KeyM1 | KeyK1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayResponse := `{"content":"` + base64.StdEncoding.EncodeToString(data) + `","encoding":"base64"}`
+	client := NewClient("key")
+	client.c.Transport = serve(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("m") != "1" || r.URL.Path != "/api/get_beatmaps" && q.Get("mods") != "76" {
+			t.Errorf("%s: got query %q", r.URL.Path, r.URL.RawQuery)
+		}
+		switch r.URL.Path {
+		case "/api/get_replay":
+			w.Write([]byte(replayResponse))
+		case "/api/get_scores":
+			w.Write([]byte(scoresResponse))
+		case "/api/get_beatmaps":
+			w.Write([]byte(beatmapsResponse))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	})
+	rf, err := client.ReplayFile(ModeTaiko, 252002, 1, Hidden, DoubleTime, TouchDevice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the beatmap is an osu! map converted to taiko, and the replay takes the requested mode
+	if rf.Mode != ModeTaiko || rf.BeatmapMD5 != "c8f08438204abfcdd1a748ebfae67421" || rf.PlayerName != "User name" || rf.ScoreID != 7654321 {
+		t.Errorf("got %+v", rf)
+	}
+	if len(rf.Content) != 1 || rf.Content[0].Keys != KeyM1|KeyK1 {
+		t.Errorf("got frames %v", rf.Content)
+	}
+	if rf.ReplayMD5 != rf.Hash(RankSH) {
+		t.Errorf("got replay MD5 %s, want %s", rf.ReplayMD5, rf.Hash(RankSH))
+	}
+}
@@ -0,0 +1,155 @@
+package osudb
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pixelrazor/osu"
+	"github.com/pixelrazor/osu/internal/osubin"
+)
+
+// collectionVersion is the version written to collection.db files that don't have one
+const collectionVersion = 20150203
+
+// CollectionDB holds the contents of collection.db
+type CollectionDB struct {
+	// Version is the game version that wrote the file. 0 writes a version osu! accepts
+	Version     int32
+	Collections []*Collection
+}
+
+// Collection is a named list of beatmaps, identified by the MD5 of their .osu files
+type Collection struct {
+	Name   string
+	Hashes []string
+}
+
+// NewCollection returns a collection holding the given beatmaps
+func NewCollection(name string, maps []*osu.Beatmap) *Collection {
+	c := &Collection{Name: name}
+	for _, b := range maps {
+		c.Add(b.FileMd5)
+	}
+	return c
+}
+
+// Has reports whether the collection holds the beatmap with the given MD5
+func (c *Collection) Has(hash string) bool {
+	for _, h := range c.Hashes {
+		if strings.EqualFold(h, hash) {
+			return true
+		}
+	}
+	return false
+}
+
+// Add adds beatmaps by MD5, skipping those already in the collection
+func (c *Collection) Add(hashes ...string) {
+	for _, h := range hashes {
+		if h != "" && !c.Has(h) {
+			c.Hashes = append(c.Hashes, h)
+		}
+	}
+}
+
+// Merge adds the beatmaps of other to the collection
+func (c *Collection) Merge(other *Collection) {
+	c.Add(other.Hashes...)
+}
+
+// Get returns the collection with the given name, or nil
+func (db *CollectionDB) Get(name string) *Collection {
+	for _, c := range db.Collections {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// Merge adds the collections of other. Collections with the same name are merged into one
+func (db *CollectionDB) Merge(other *CollectionDB) {
+	for _, oc := range other.Collections {
+		if c := db.Get(oc.Name); c != nil {
+			c.Merge(oc)
+			continue
+		}
+		c := &Collection{Name: oc.Name}
+		c.Merge(oc)
+		db.Collections = append(db.Collections, c)
+	}
+}
+
+// ReadCollections reads the collection.db file at path
+func ReadCollections(path string) (*CollectionDB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.New("osudb: " + err.Error())
+	}
+	defer f.Close()
+	return DecodeCollections(f)
+}
+
+// DecodeCollections reads a collection.db file
+func DecodeCollections(r io.Reader) (*CollectionDB, error) {
+	br := osubin.NewReader(bufio.NewReader(r))
+	db := &CollectionDB{Version: br.Int32()}
+	n := br.Int32()
+	for i := int32(0); i < n && br.Err() == nil; i++ {
+		c := &Collection{Name: br.String()}
+		count := br.Int32()
+		for j := int32(0); j < count && br.Err() == nil; j++ {
+			c.Hashes = append(c.Hashes, br.String())
+		}
+		db.Collections = append(db.Collections, c)
+	}
+	if err := br.Err(); err != nil {
+		return nil, errors.New("osudb: collection.db: " + err.Error())
+	}
+	return db, nil
+}
+
+// WriteFile writes the collections to a collection.db file at path
+func (db *CollectionDB) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.New("osudb: " + err.Error())
+	}
+	if err := db.Encode(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return errors.New("osudb: " + err.Error())
+	}
+	return nil
+}
+
+// Encode writes the collections as a collection.db file
+func (db *CollectionDB) Encode(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	ow := osubin.NewWriter(bw)
+	version := db.Version
+	if version == 0 {
+		version = collectionVersion
+	}
+	ow.Int32(version)
+	ow.Int32(int32(len(db.Collections)))
+	for _, c := range db.Collections {
+		ow.String(c.Name)
+		ow.Int32(int32(len(c.Hashes)))
+		for _, h := range c.Hashes {
+			ow.String(h)
+		}
+	}
+	if err := ow.Err(); err != nil {
+		return errors.New("osudb: collection.db: " + err.Error())
+	}
+	if err := bw.Flush(); err != nil {
+		return errors.New("osudb: collection.db: " + err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package osudb
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pixelrazor/osu"
+	"github.com/pixelrazor/osu/internal/osubin"
+)
+
+func TestDecodeCollections(t *testing.T) {
+	var buf bytes.Buffer
+	w := osubin.NewWriter(&buf)
+	w.Int32(20210520)
+	w.Int32(2)
+	w.String("Favourites")
+	w.Int32(2)
+	w.String("0123456789abcdef0123456789abcdef")
+	w.String("fedcba9876543210fedcba9876543210")
+	w.String("")
+	w.Int32(0)
+	if err := w.Err(); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	db, err := DecodeCollections(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &CollectionDB{Version: 20210520, Collections: []*Collection{
+		{Name: "Favourites", Hashes: []string{"0123456789abcdef0123456789abcdef", "fedcba9876543210fedcba9876543210"}},
+		{Name: ""},
+	}}
+	if !reflect.DeepEqual(db, want) {
+		t.Errorf("got %+v", db)
+	}
+	// encoding it again gives the same file
+	var out bytes.Buffer
+	if err := db.Encode(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), data) {
+		t.Errorf("got %x\nwant %x", out.Bytes(), data)
+	}
+	for n := 0; n < len(data); n++ {
+		if _, err := DecodeCollections(bytes.NewReader(data[:n])); err == nil {
+			t.Errorf("no error decoding the first %d of %d bytes", n, len(data))
+		}
+	}
+}
+
+func TestCollectionsRoundTrip(t *testing.T) {
+	db := &CollectionDB{Collections: []*Collection{
+		NewCollection("Tournament", []*osu.Beatmap{{FileMd5: "aaaa"}, {FileMd5: "bbbb"}, {FileMd5: "AAAA"}, {}}),
+		{Name: "日本語", Hashes: []string{"cccc"}},
+	}}
+	path := filepath.Join(t.TempDir(), "collection.db")
+	if err := db.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadCollections(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a missing version is written as one osu! accepts
+	want := &CollectionDB{Version: collectionVersion, Collections: []*Collection{
+		{Name: "Tournament", Hashes: []string{"aaaa", "bbbb"}},
+		{Name: "日本語", Hashes: []string{"cccc"}},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if _, err := ReadCollections(filepath.Join(t.TempDir(), "missing.db")); err == nil {
+		t.Error("no error reading a missing file")
+	}
+}
+
+func TestCollectionsMerge(t *testing.T) {
+	db := &CollectionDB{Collections: []*Collection{
+		{Name: "A", Hashes: []string{"1", "2"}},
+		{Name: "B", Hashes: []string{"3"}},
+	}}
+	other := &CollectionDB{Collections: []*Collection{
+		{Name: "B", Hashes: []string{"3", "4"}},
+		{Name: "C", Hashes: []string{"5", "5"}},
+	}}
+	db.Merge(other)
+	want := []*Collection{
+		{Name: "A", Hashes: []string{"1", "2"}},
+		{Name: "B", Hashes: []string{"3", "4"}},
+		{Name: "C", Hashes: []string{"5"}},
+	}
+	if !reflect.DeepEqual(db.Collections, want) {
+		t.Errorf("got %+v", db.Collections)
+	}
+	// merged collections are copies
+	other.Collections[1].Hashes[0] = "changed"
+	if db.Get("C").Hashes[0] != "5" {
+		t.Error("merged collection shares its hashes with the original")
+	}
+	if db.Get("D") != nil {
+		t.Error("got a missing collection")
+	}
+	c := db.Get("A")
+	if !c.Has("1") || c.Has("5") {
+		t.Error("Has reported the wrong beatmaps")
+	}
+}
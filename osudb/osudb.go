@@ -0,0 +1,328 @@
+// Package osudb reads the databases osu!stable keeps in its install folder (osu!.db, collection.db and scores.db) and writes collection.db
+package osudb
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pixelrazor/osu"
+	"github.com/pixelrazor/osu/internal/osubin"
+)
+
+// Database versions that changed the osu!.db format
+const (
+	// versionFloatDifficulty is the first version to store difficulty settings as floats and to store star ratings
+	versionFloatDifficulty = 20140609
+	// versionNoEntrySize is the first version that doesn't store the size of each beatmap entry
+	versionNoEntrySize = 20191106
+	// versionFloatStarRatings is the first version to store star ratings as floats
+	versionFloatStarRatings = 20250107
+)
+
+// RankedStatus is the ranked status of a beatmap as osu!.db stores it
+type RankedStatus byte
+
+// All ranked statuses
+const (
+	RankedStatusUnknown RankedStatus = iota
+	RankedStatusUnsubmitted
+	// RankedStatusPending covers pending, WIP and graveyard beatmaps
+	RankedStatusPending
+	rankedStatusUnused
+	RankedStatusRanked
+	RankedStatusApproved
+	RankedStatusQualified
+	RankedStatusLoved
+)
+
+// Status converts the ranked status to the API's, reporting false for unknown and unsubmitted beatmaps
+func (s RankedStatus) Status() (osu.Status, bool) {
+	switch s {
+	case RankedStatusPending:
+		return osu.StatusPending, true
+	case RankedStatusRanked:
+		return osu.StatusRanked, true
+	case RankedStatusApproved:
+		return osu.StatusApproved, true
+	case RankedStatusQualified:
+		return osu.StatusQualified, true
+	case RankedStatusLoved:
+		return osu.StatusLoved, true
+	}
+	return 0, false
+}
+
+// Permissions is a bitwise combination of the permissions of the player logged in to osu!
+type Permissions int32
+
+// All permissions
+const (
+	PermissionNormal Permissions = 1 << iota
+	PermissionModerator
+	PermissionSupporter
+	PermissionFriend
+	PermissionPeppy
+	PermissionWorldCupStaff
+)
+
+// Database holds the contents of osu!.db, the list of installed beatmaps
+type Database struct {
+	// Version is the game version that wrote the database, e.g. 20210520
+	Version         int32
+	FolderCount     int32
+	AccountUnlocked bool
+	// UnlockDate is when a locked account will be unlocked
+	UnlockDate  time.Time
+	PlayerName  string
+	Beatmaps    []*Beatmap
+	Permissions Permissions
+}
+
+// Beatmap is an installed beatmap
+type Beatmap struct {
+	Artist        string
+	ArtistUnicode string
+	Title         string
+	TitleUnicode  string
+	Creator       string
+	// Version is the name of the difficulty
+	Version       string
+	AudioFilename string
+	FileMd5       string
+	// Filename is the name of the .osu file in the beatmap's folder
+	Filename     string
+	RankedStatus RankedStatus
+	CountNormal  int
+	CountSlider  int
+	CountSpinner int
+	LastModified time.Time
+	DiffApproach float64
+	DiffSize     float64
+	DiffDrain    float64
+	DiffOverall  float64
+	// SliderVelocity is the beatmap's slider multiplier
+	SliderVelocity float64
+	// StarRatings holds the star ratings osu! has calculated for each mode and combination of difficulty changing mods
+	StarRatings  map[osu.Mode]map[osu.Mods]float64
+	DrainTime    time.Duration
+	TotalTime    time.Duration
+	PreviewTime  time.Duration
+	TimingPoints []TimingPoint
+	BeatmapID    osu.BeatmapID
+	BeatmapsetID osu.BeatmapsetID
+	ThreadID     int32
+	// Grades holds the best local grade in each mode, indexed by osu.Mode
+	Grades        [4]osu.Rank
+	LocalOffset   int16
+	StackLeniency float64
+	Mode          osu.Mode
+	Source        string
+	Tags          string
+	OnlineOffset  int16
+	TitleFont     string
+	Unplayed      bool
+	LastPlayed    time.Time
+	Osz2          bool
+	// FolderName is the name of the beatmap's folder in the Songs directory
+	FolderName        string
+	LastChecked       time.Time
+	IgnoreSounds      bool
+	IgnoreSkin        bool
+	DisableStoryboard bool
+	DisableVideo      bool
+	VisualOverride    bool
+	ManiaScrollSpeed  byte
+}
+
+// TimingPoint is an uninherited or inherited timing point as osu!.db stores it
+type TimingPoint struct {
+	// BeatLength is in milliseconds for uninherited points, or a negative inverse slider velocity multiplier as a percentage
+	BeatLength float64
+	// Offset is in milliseconds
+	Offset      float64
+	Uninherited bool
+}
+
+// Grade returns the best local grade in the given mode
+func (b *Beatmap) Grade(m osu.Mode) osu.Rank {
+	if m < 0 || int(m) >= len(b.Grades) {
+		return osu.RankNone
+	}
+	return b.Grades[m]
+}
+
+// Matches reports whether the installed beatmap is the same version of the beatmap as the API's
+func (b *Beatmap) Matches(api *osu.Beatmap) bool {
+	return strings.EqualFold(b.FileMd5, api.FileMd5)
+}
+
+// ByMD5 returns the installed beatmap with the given MD5, or nil
+func (db *Database) ByMD5(md5 string) *Beatmap {
+	for _, b := range db.Beatmaps {
+		if strings.EqualFold(b.FileMd5, md5) {
+			return b
+		}
+	}
+	return nil
+}
+
+// ByID returns the installed beatmap with the given ID, or nil. Unsubmitted beatmaps have no ID
+func (db *Database) ByID(ID osu.BeatmapID) *Beatmap {
+	if ID == 0 {
+		return nil
+	}
+	for _, b := range db.Beatmaps {
+		if b.BeatmapID == ID {
+			return b
+		}
+	}
+	return nil
+}
+
+// ReadDatabase reads the osu!.db file at path
+func ReadDatabase(path string) (*Database, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.New("osudb: " + err.Error())
+	}
+	defer f.Close()
+	return DecodeDatabase(f)
+}
+
+// DecodeDatabase reads an osu!.db file
+func DecodeDatabase(r io.Reader) (*Database, error) {
+	br := osubin.NewReader(bufio.NewReader(r))
+	db := &Database{
+		Version:         br.Int32(),
+		FolderCount:     br.Int32(),
+		AccountUnlocked: br.Bool(),
+		UnlockDate:      br.DateTime(),
+		PlayerName:      br.String(),
+	}
+	n := br.Int32()
+	for i := int32(0); i < n && br.Err() == nil; i++ {
+		db.Beatmaps = append(db.Beatmaps, decodeBeatmap(br, db.Version))
+	}
+	db.Permissions = Permissions(br.Int32())
+	if err := br.Err(); err != nil {
+		return nil, errors.New("osudb: osu!.db: " + err.Error())
+	}
+	return db, nil
+}
+
+func decodeBeatmap(br *osubin.Reader, version int32) *Beatmap {
+	if version < versionNoEntrySize {
+		br.Int32()
+	}
+	b := &Beatmap{
+		Artist:        br.String(),
+		ArtistUnicode: br.String(),
+		Title:         br.String(),
+		TitleUnicode:  br.String(),
+		Creator:       br.String(),
+		Version:       br.String(),
+		AudioFilename: br.String(),
+		FileMd5:       br.String(),
+		Filename:      br.String(),
+		RankedStatus:  RankedStatus(br.Byte()),
+		CountNormal:   int(uint16(br.Int16())),
+		CountSlider:   int(uint16(br.Int16())),
+		CountSpinner:  int(uint16(br.Int16())),
+		LastModified:  br.DateTime(),
+	}
+	for _, p := range []*float64{&b.DiffApproach, &b.DiffSize, &b.DiffDrain, &b.DiffOverall} {
+		if version < versionFloatDifficulty {
+			*p = float64(br.Byte())
+		} else {
+			*p = float64(br.Float32())
+		}
+	}
+	b.SliderVelocity = br.Float64()
+	if version >= versionFloatDifficulty {
+		b.StarRatings = make(map[osu.Mode]map[osu.Mods]float64)
+		for m := osu.ModeOsu; m <= osu.ModeMania; m++ {
+			b.StarRatings[m] = decodeStarRatings(br, version)
+		}
+	}
+	b.DrainTime = time.Duration(br.Int32()) * time.Second
+	b.TotalTime = time.Duration(br.Int32()) * time.Millisecond
+	b.PreviewTime = time.Duration(br.Int32()) * time.Millisecond
+	n := br.Int32()
+	for i := int32(0); i < n && br.Err() == nil; i++ {
+		b.TimingPoints = append(b.TimingPoints, TimingPoint{
+			BeatLength:  br.Float64(),
+			Offset:      br.Float64(),
+			Uninherited: br.Bool(),
+		})
+	}
+	b.BeatmapID = osu.BeatmapID(br.Int32())
+	b.BeatmapsetID = osu.BeatmapsetID(br.Int32())
+	b.ThreadID = br.Int32()
+	for i := range b.Grades {
+		b.Grades[i] = grade(br.Byte())
+	}
+	b.LocalOffset = br.Int16()
+	b.StackLeniency = float64(br.Float32())
+	b.Mode = osu.Mode(br.Byte())
+	b.Source = br.String()
+	b.Tags = br.String()
+	b.OnlineOffset = br.Int16()
+	b.TitleFont = br.String()
+	b.Unplayed = br.Bool()
+	b.LastPlayed = br.DateTime()
+	b.Osz2 = br.Bool()
+	b.FolderName = br.String()
+	b.LastChecked = br.DateTime()
+	b.IgnoreSounds = br.Bool()
+	b.IgnoreSkin = br.Bool()
+	b.DisableStoryboard = br.Bool()
+	b.DisableVideo = br.Bool()
+	b.VisualOverride = br.Bool()
+	if version < versionFloatDifficulty {
+		br.Int16()
+	}
+	// the last modification time again, as seconds
+	br.Int32()
+	b.ManiaScrollSpeed = br.Byte()
+	return b
+}
+
+// decodeStarRatings reads a list of mods and star rating pairs, each value prefixed with a type byte
+func decodeStarRatings(br *osubin.Reader, version int32) map[osu.Mods]float64 {
+	ratings := make(map[osu.Mods]float64)
+	n := br.Int32()
+	for i := int32(0); i < n && br.Err() == nil; i++ {
+		if br.Byte() != 0x08 {
+			br.Fail(errors.New("invalid star rating entry"))
+		}
+		mods := osu.Mods(br.Int32())
+		var stars float64
+		if version >= versionFloatStarRatings {
+			if br.Byte() != 0x0c {
+				br.Fail(errors.New("invalid star rating entry"))
+			}
+			stars = float64(br.Float32())
+		} else {
+			if br.Byte() != 0x0d {
+				br.Fail(errors.New("invalid star rating entry"))
+			}
+			stars = br.Float64()
+		}
+		ratings[mods] = stars
+	}
+	return ratings
+}
+
+// grades maps the grades osu! stores to ranks
+var grades = []osu.Rank{osu.RankSSH, osu.RankSH, osu.RankSS, osu.RankS, osu.RankA, osu.RankB, osu.RankC, osu.RankD, osu.RankF}
+
+func grade(b byte) osu.Rank {
+	if int(b) < len(grades) {
+		return grades[b]
+	}
+	return osu.RankNone
+}
@@ -0,0 +1,286 @@
+package osudb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pixelrazor/osu"
+	"github.com/pixelrazor/osu/internal/osubin"
+)
+
+var (
+	modified = time.Date(2020, 3, 4, 5, 6, 7, 0, time.UTC)
+	played   = time.Date(2021, 5, 20, 12, 0, 0, 0, time.UTC)
+)
+
+// testBeatmap returns the beatmap every test database holds, as a database of the given version decodes it
+func testBeatmap(version int32) *Beatmap {
+	b := &Beatmap{
+		Artist:         "Artist",
+		ArtistUnicode:  "アーティスト",
+		Title:          "Title",
+		TitleUnicode:   "タイトル",
+		Creator:        "mapper",
+		Version:        "Insane",
+		AudioFilename:  "audio.mp3",
+		FileMd5:        "0123456789abcdef0123456789abcdef",
+		Filename:       "Artist - Title (mapper) [Insane].osu",
+		RankedStatus:   RankedStatusRanked,
+		CountNormal:    40000,
+		CountSlider:    200,
+		CountSpinner:   3,
+		LastModified:   modified,
+		DiffApproach:   9,
+		DiffSize:       4,
+		DiffDrain:      6,
+		DiffOverall:    8,
+		SliderVelocity: 1.8,
+		DrainTime:      90 * time.Second,
+		TotalTime:      100500 * time.Millisecond,
+		PreviewTime:    40 * time.Second,
+		TimingPoints: []TimingPoint{
+			{BeatLength: 333.25, Offset: 500, Uninherited: true},
+			{BeatLength: -50, Offset: 1500},
+		},
+		BeatmapID:        252002,
+		BeatmapsetID:     93398,
+		ThreadID:         12,
+		Grades:           [4]osu.Rank{osu.RankSH, osu.RankNone, osu.RankA, osu.RankSSH},
+		LocalOffset:      -5,
+		StackLeniency:    0.5,
+		Mode:             osu.ModeOsu,
+		Source:           "Source",
+		Tags:             "tag1 tag2",
+		OnlineOffset:     3,
+		TitleFont:        "",
+		Unplayed:         false,
+		LastPlayed:       played,
+		FolderName:       "93398 Artist - Title",
+		LastChecked:      played,
+		IgnoreSkin:       true,
+		DisableVideo:     true,
+		ManiaScrollSpeed: 20,
+	}
+	if version >= versionFloatDifficulty {
+		// difficulty settings written as floats keep their fractions
+		b.DiffApproach, b.DiffSize = 9.5, 4.2
+		b.StarRatings = map[osu.Mode]map[osu.Mods]float64{
+			osu.ModeOsu:   {0: 5.5, osu.Mods(osu.DoubleTime): 7.25},
+			osu.ModeTaiko: {},
+			osu.ModeCtb:   {},
+			osu.ModeMania: {0: 3},
+		}
+	}
+	return b
+}
+
+// grade indexes of the ranks testBeatmap's grades are stored as
+var gradeBytes = map[osu.Rank]byte{osu.RankSSH: 0, osu.RankSH: 1, osu.RankA: 4, osu.RankNone: 9}
+
+// encodeBeatmap writes a beatmap entry the way osu! writes it in a database of the given version
+func encodeBeatmap(w *osubin.Writer, version int32, b *Beatmap) {
+	var entry bytes.Buffer
+	ew := osubin.NewWriter(&entry)
+	for _, s := range []string{b.Artist, b.ArtistUnicode, b.Title, b.TitleUnicode, b.Creator, b.Version, b.AudioFilename, b.FileMd5, b.Filename} {
+		ew.String(s)
+	}
+	ew.Byte(byte(b.RankedStatus))
+	for _, n := range []int{b.CountNormal, b.CountSlider, b.CountSpinner} {
+		ew.Int16(int16(uint16(n)))
+	}
+	ew.DateTime(b.LastModified)
+	for _, d := range []float64{b.DiffApproach, b.DiffSize, b.DiffDrain, b.DiffOverall} {
+		if version < versionFloatDifficulty {
+			ew.Byte(byte(d))
+		} else {
+			ew.Float32(float32(d))
+		}
+	}
+	ew.Float64(b.SliderVelocity)
+	if version >= versionFloatDifficulty {
+		for m := osu.ModeOsu; m <= osu.ModeMania; m++ {
+			ratings := b.StarRatings[m]
+			ew.Int32(int32(len(ratings)))
+			// in order of mods, so that the file is the same every run
+			for mods := osu.Mods(0); mods <= osu.Mods(osu.DoubleTime); mods++ {
+				stars, ok := ratings[mods]
+				if !ok {
+					continue
+				}
+				ew.Byte(0x08)
+				ew.Int32(int32(mods))
+				if version >= versionFloatStarRatings {
+					ew.Byte(0x0c)
+					ew.Float32(float32(stars))
+				} else {
+					ew.Byte(0x0d)
+					ew.Float64(stars)
+				}
+			}
+		}
+	}
+	ew.Int32(int32(b.DrainTime / time.Second))
+	ew.Int32(int32(b.TotalTime / time.Millisecond))
+	ew.Int32(int32(b.PreviewTime / time.Millisecond))
+	ew.Int32(int32(len(b.TimingPoints)))
+	for _, tp := range b.TimingPoints {
+		ew.Float64(tp.BeatLength)
+		ew.Float64(tp.Offset)
+		ew.Bool(tp.Uninherited)
+	}
+	ew.Int32(int32(b.BeatmapID))
+	ew.Int32(int32(b.BeatmapsetID))
+	ew.Int32(b.ThreadID)
+	for _, g := range b.Grades {
+		ew.Byte(gradeBytes[g])
+	}
+	ew.Int16(b.LocalOffset)
+	ew.Float32(float32(b.StackLeniency))
+	ew.Byte(byte(b.Mode))
+	ew.String(b.Source)
+	ew.String(b.Tags)
+	ew.Int16(b.OnlineOffset)
+	ew.String(b.TitleFont)
+	ew.Bool(b.Unplayed)
+	ew.DateTime(b.LastPlayed)
+	ew.Bool(b.Osz2)
+	ew.String(b.FolderName)
+	ew.DateTime(b.LastChecked)
+	for _, f := range []bool{b.IgnoreSounds, b.IgnoreSkin, b.DisableStoryboard, b.DisableVideo, b.VisualOverride} {
+		ew.Bool(f)
+	}
+	if version < versionFloatDifficulty {
+		ew.Int16(0)
+	}
+	ew.Int32(int32(b.LastModified.Unix()))
+	ew.Byte(b.ManiaScrollSpeed)
+	if version < versionNoEntrySize {
+		w.Int32(int32(entry.Len()))
+	}
+	w.Bytes(entry.Bytes())
+}
+
+// encodeDatabase writes an osu!.db file holding two copies of testBeatmap
+func encodeDatabase(t *testing.T, version int32) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := osubin.NewWriter(&buf)
+	w.Int32(version)
+	w.Int32(7)
+	w.Bool(true)
+	w.DateTime(time.Time{})
+	w.String("player")
+	w.Int32(2)
+	encodeBeatmap(w, version, testBeatmap(version))
+	second := testBeatmap(version)
+	second.FileMd5, second.BeatmapID, second.Mode = "fedcba9876543210fedcba9876543210", 0, osu.ModeMania
+	encodeBeatmap(w, version, second)
+	w.Int32(int32(PermissionNormal | PermissionSupporter))
+	if err := w.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeDatabase(t *testing.T) {
+	// one version on each side of every format change
+	for _, version := range []int32{20140608, versionFloatDifficulty, 20191105, versionNoEntrySize, 20250106, versionFloatStarRatings} {
+		db, err := DecodeDatabase(bytes.NewReader(encodeDatabase(t, version)))
+		if err != nil {
+			t.Errorf("%d: %v", version, err)
+			continue
+		}
+		if db.Version != version || db.FolderCount != 7 || !db.AccountUnlocked || db.PlayerName != "player" || db.Permissions != PermissionNormal|PermissionSupporter {
+			t.Errorf("%d: got %+v", version, db)
+		}
+		if len(db.Beatmaps) != 2 {
+			t.Fatalf("%d: got %d beatmaps", version, len(db.Beatmaps))
+		}
+		got, want := db.Beatmaps[0], testBeatmap(version)
+		// times are compared as instants
+		if !got.LastModified.Equal(want.LastModified) || !got.LastPlayed.Equal(want.LastPlayed) || !got.LastChecked.Equal(want.LastChecked) {
+			t.Errorf("%d: got times %v, %v, %v", version, got.LastModified, got.LastPlayed, got.LastChecked)
+		}
+		got.LastModified, got.LastPlayed, got.LastChecked = want.LastModified, want.LastPlayed, want.LastChecked
+		// float32 fields lose precision
+		if d := got.DiffSize - want.DiffSize; d > 1e-6 || d < -1e-6 {
+			t.Errorf("%d: got CS %v, want %v", version, got.DiffSize, want.DiffSize)
+		}
+		got.DiffSize = want.DiffSize
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%d: got  %+v\nwant %+v", version, got, want)
+		}
+		if db.Beatmaps[1].Mode != osu.ModeMania || db.Beatmaps[1].ManiaScrollSpeed != want.ManiaScrollSpeed {
+			t.Errorf("%d: the second beatmap is %+v", version, db.Beatmaps[1])
+		}
+	}
+}
+
+func TestDecodeDatabaseTruncated(t *testing.T) {
+	data := encodeDatabase(t, versionFloatStarRatings)
+	for _, n := range []int{0, 10, 100, len(data) / 2, len(data) - 1} {
+		if _, err := DecodeDatabase(bytes.NewReader(data[:n])); err == nil {
+			t.Errorf("no error decoding the first %d of %d bytes", n, len(data))
+		}
+	}
+}
+
+func TestDecodeDatabaseStarRatingType(t *testing.T) {
+	// a database claiming a version before float star ratings but holding them
+	data := encodeDatabase(t, versionFloatStarRatings)
+	binary.LittleEndian.PutUint32(data, versionFloatStarRatings-1)
+	if _, err := DecodeDatabase(bytes.NewReader(data)); err == nil {
+		t.Error("no error for star ratings of the wrong type")
+	}
+}
+
+func TestDatabaseLookup(t *testing.T) {
+	db, err := DecodeDatabase(bytes.NewReader(encodeDatabase(t, versionFloatStarRatings)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := db.ByMD5("0123456789ABCDEF0123456789ABCDEF"); b != db.Beatmaps[0] {
+		t.Errorf("ByMD5: got %+v", b)
+	}
+	if b := db.ByMD5("missing"); b != nil {
+		t.Errorf("ByMD5: got %+v for a missing beatmap", b)
+	}
+	if b := db.ByID(252002); b != db.Beatmaps[0] {
+		t.Errorf("ByID: got %+v", b)
+	}
+	// the second beatmap is unsubmitted
+	if b := db.ByID(0); b != nil {
+		t.Errorf("ByID: got %+v for ID 0", b)
+	}
+	b := db.Beatmaps[0]
+	if !b.Matches(&osu.Beatmap{FileMd5: "0123456789ABCDEF0123456789abcdef"}) || b.Matches(&osu.Beatmap{FileMd5: "x"}) {
+		t.Error("Matches compared the MD5 wrongly")
+	}
+	if b.Grade(osu.ModeOsu) != osu.RankSH || b.Grade(osu.ModeMania) != osu.RankSSH || b.Grade(osu.Mode(7)) != osu.RankNone {
+		t.Errorf("got grades %v", b.Grades)
+	}
+}
+
+func TestRankedStatus(t *testing.T) {
+	tests := []struct {
+		status RankedStatus
+		want   osu.Status
+		ok     bool
+	}{
+		{RankedStatusUnknown, 0, false},
+		{RankedStatusUnsubmitted, 0, false},
+		{RankedStatusPending, osu.StatusPending, true},
+		{RankedStatusRanked, osu.StatusRanked, true},
+		{RankedStatusApproved, osu.StatusApproved, true},
+		{RankedStatusQualified, osu.StatusQualified, true},
+		{RankedStatusLoved, osu.StatusLoved, true},
+	}
+	for _, tt := range tests {
+		if got, ok := tt.status.Status(); got != tt.want || ok != tt.ok {
+			t.Errorf("%d: got %v, %v, want %v, %v", tt.status, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package osudb
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pixelrazor/osu"
+	"github.com/pixelrazor/osu/internal/osubin"
+)
+
+// ScoreDB holds the contents of scores.db, the scores set locally
+type ScoreDB struct {
+	// Version is the game version that wrote the file, e.g. 20210520
+	Version  int32
+	Beatmaps []*BeatmapScores
+}
+
+// BeatmapScores holds the local scores on a beatmap
+type BeatmapScores struct {
+	FileMd5 string
+	// Scores hold the same fields as .osr files, without replay data. Use ToScore to compare them with online scores
+	Scores []*osu.ReplayFile
+}
+
+// ByMD5 returns the scores on the beatmap with the given MD5, or nil
+func (db *ScoreDB) ByMD5(md5 string) *BeatmapScores {
+	for _, b := range db.Beatmaps {
+		if strings.EqualFold(b.FileMd5, md5) {
+			return b
+		}
+	}
+	return nil
+}
+
+// ReadScores reads the scores.db file at path
+func ReadScores(path string) (*ScoreDB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.New("osudb: " + err.Error())
+	}
+	defer f.Close()
+	return DecodeScores(f)
+}
+
+// DecodeScores reads a scores.db file
+func DecodeScores(r io.Reader) (*ScoreDB, error) {
+	br := osubin.NewReader(bufio.NewReader(r))
+	db := &ScoreDB{Version: br.Int32()}
+	n := br.Int32()
+	for i := int32(0); i < n && br.Err() == nil; i++ {
+		b := &BeatmapScores{FileMd5: br.String()}
+		count := br.Int32()
+		for j := int32(0); j < count && br.Err() == nil; j++ {
+			b.Scores = append(b.Scores, decodeScore(br))
+		}
+		db.Beatmaps = append(db.Beatmaps, b)
+	}
+	if err := br.Err(); err != nil {
+		return nil, errors.New("osudb: scores.db: " + err.Error())
+	}
+	return db, nil
+}
+
+// decodeScore reads a score, which is stored like an .osr file without replay data
+func decodeScore(br *osubin.Reader) *osu.ReplayFile {
+	rp := br.Replay()
+	return &osu.ReplayFile{
+		Mode:           osu.Mode(rp.Mode),
+		Version:        rp.Version,
+		BeatmapMD5:     rp.BeatmapMD5,
+		PlayerName:     rp.PlayerName,
+		ReplayMD5:      rp.ReplayMD5,
+		Count300:       int64(rp.Count300),
+		Count100:       int64(rp.Count100),
+		Count50:        int64(rp.Count50),
+		Countgeki:      int64(rp.Countgeki),
+		Countkatu:      int64(rp.Countkatu),
+		Countmiss:      int64(rp.Countmiss),
+		Score:          int64(rp.Score),
+		Maxcombo:       int64(rp.Maxcombo),
+		Perfect:        rp.Perfect,
+		EnabledMods:    osu.Mods(rp.Mods),
+		Timestamp:      rp.Timestamp,
+		ScoreID:        osu.ScoreID(rp.ScoreID),
+		TargetAccuracy: rp.TargetAccuracy,
+	}
+}
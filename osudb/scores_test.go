@@ -0,0 +1,120 @@
+package osudb
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pixelrazor/osu"
+	"github.com/pixelrazor/osu/internal/osubin"
+)
+
+// testScore returns a score of the given game version as scores.db stores it
+func testScore(version int32, scoreID int64, mods osu.Mods) osubin.Replay {
+	return osubin.Replay{
+		Mode:       byte(osu.ModeOsu),
+		Version:    version,
+		BeatmapMD5: "0123456789abcdef0123456789abcdef",
+		PlayerName: "player",
+		ReplayMD5:  "fedcba9876543210fedcba9876543210",
+		Count300:   40000,
+		Count100:   12,
+		Count50:    3,
+		Countgeki:  60,
+		Countkatu:  8,
+		Countmiss:  1,
+		Score:      12345678,
+		Maxcombo:   40015,
+		Mods:       uint32(mods),
+		Timestamp:  time.Date(2021, 5, 20, 12, 30, 15, 0, time.UTC),
+		ScoreID:    scoreID,
+	}
+}
+
+func encodeScores(t *testing.T, scores map[string][]osubin.Replay, order []string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := osubin.NewWriter(&buf)
+	w.Int32(20210520)
+	w.Int32(int32(len(order)))
+	for _, md5 := range order {
+		w.String(md5)
+		w.Int32(int32(len(scores[md5])))
+		for _, s := range scores[md5] {
+			w.Replay(s)
+		}
+	}
+	if err := w.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeScores(t *testing.T) {
+	target := testScore(20210520, 4000000000, osu.Mods(osu.Target))
+	target.TargetAccuracy = 0.9375
+	scores := map[string][]osubin.Replay{
+		"0123456789abcdef0123456789abcdef": {
+			// one score on each side of the changes to the score ID
+			testScore(20121007, 0, 0),
+			testScore(osubin.ReplayVersionScoreID, 123456789, osu.Mods(osu.Hidden)),
+			testScore(osubin.ReplayVersionScoreID64-1, 123456789, 0),
+			testScore(osubin.ReplayVersionScoreID64, 4000000000, osu.Mods(osu.Hidden|osu.HardRock)),
+			target,
+		},
+		"fedcba9876543210fedcba9876543210": {},
+	}
+	data := encodeScores(t, scores, []string{"0123456789abcdef0123456789abcdef", "fedcba9876543210fedcba9876543210"})
+	db, err := DecodeScores(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Version != 20210520 || len(db.Beatmaps) != 2 || len(db.Beatmaps[1].Scores) != 0 {
+		t.Fatalf("got %+v", db)
+	}
+	got := db.Beatmaps[0].Scores
+	wantIDs := []osu.ScoreID{0, 123456789, 123456789, 4000000000, 4000000000}
+	if len(got) != len(wantIDs) {
+		t.Fatalf("got %d scores, want %d", len(got), len(wantIDs))
+	}
+	for i, s := range got {
+		if s.ScoreID != wantIDs[i] {
+			t.Errorf("score %d: got ID %d, want %d", i, s.ScoreID, wantIDs[i])
+		}
+		if s.Count300 != 40000 || s.Maxcombo != 40015 || s.Countgeki != 60 || s.PlayerName != "player" || len(s.Content) != 0 || s.LifeBar != nil {
+			t.Errorf("score %d: got %+v", i, s)
+		}
+	}
+	if got[3].EnabledMods != osu.Mods(osu.Hidden|osu.HardRock) || got[4].TargetAccuracy != 0.9375 {
+		t.Errorf("got mods %v and target accuracy %v", got[3].EnabledMods, got[4].TargetAccuracy)
+	}
+	s := got[3].ToScore()
+	if s.ScoreID != 4000000000 || s.Username != "player" || s.Date != got[3].Timestamp || s.ReplayAvailable {
+		t.Errorf("got score %+v", s)
+	}
+
+	for n := 0; n < len(data); n++ {
+		if _, err := DecodeScores(bytes.NewReader(data[:n])); err == nil {
+			t.Errorf("no error decoding the first %d of %d bytes", n, len(data))
+		}
+	}
+}
+
+func TestScoresByMD5(t *testing.T) {
+	scores := map[string][]osubin.Replay{"abc": {testScore(20210520, 1, 0)}, "def": {}}
+	path := filepath.Join(t.TempDir(), "scores.db")
+	db, err := DecodeScores(bytes.NewReader(encodeScores(t, scores, []string{"abc", "def"})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := db.ByMD5("ABC"); b == nil || len(b.Scores) != 1 {
+		t.Errorf("got %+v", b)
+	}
+	if b := db.ByMD5("missing"); b != nil {
+		t.Errorf("got %+v for a missing beatmap", b)
+	}
+	if _, err := ReadScores(path); err == nil {
+		t.Error("no error reading a missing file")
+	}
+}
@@ -0,0 +1,216 @@
+// Package osz reads and writes osu! beatmapset (.osz) archives
+package osz
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pixelrazor/osu"
+	"github.com/pixelrazor/osu/beatmap"
+	"github.com/pixelrazor/osu/internal/ziparchive"
+)
+
+// ErrNotFound is returned when a file is not in an archive
+var ErrNotFound = errors.New("osz: file not found")
+
+// Archive is an open .osz archive
+type Archive struct {
+	z *ziparchive.Archive
+}
+
+// Difficulty is a .osu file of an archive
+type Difficulty struct {
+	Filename string
+	// MD5 is the hash of the file, which the API returns as Beatmap.FileMd5
+	MD5     string
+	Beatmap *beatmap.Beatmap
+}
+
+// Open opens the .osz file at path. The archive must be closed after use
+func Open(path string) (*Archive, error) {
+	z, err := ziparchive.Open(path, ErrNotFound)
+	if err != nil {
+		return nil, errors.New("osz: " + err.Error())
+	}
+	return &Archive{z: z}, nil
+}
+
+// NewReader reads an .osz archive of the given size from r
+func NewReader(r io.ReaderAt, size int64) (*Archive, error) {
+	z, err := ziparchive.NewReader(r, size, ErrNotFound)
+	if err != nil {
+		return nil, errors.New("osz: " + err.Error())
+	}
+	return &Archive{z: z}, nil
+}
+
+// Close closes an archive opened with Open
+func (a *Archive) Close() error {
+	return a.z.Close()
+}
+
+// Files returns the names of the files in the archive
+func (a *Archive) Files() []string {
+	return a.z.Files()
+}
+
+// Open opens a file in the archive. Names are matched the way osu! does, ignoring case and the direction of slashes
+func (a *Archive) Open(name string) (io.ReadCloser, error) {
+	return a.z.Open(name)
+}
+
+// ReadFile returns the contents of a file in the archive
+func (a *Archive) ReadFile(name string) ([]byte, error) {
+	return a.z.ReadFile(name)
+}
+
+// Difficulties parses every .osu file in the archive
+func (a *Archive) Difficulties() ([]*Difficulty, error) {
+	var diffs []*Difficulty
+	for _, name := range a.Files() {
+		if !strings.EqualFold(path.Ext(name), ".osu") {
+			continue
+		}
+		data, err := a.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("osz: %s: %w", name, err)
+		}
+		b, err := beatmap.Parse(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("osz: %s: %w", name, err)
+		}
+		sum := md5.Sum(data)
+		diffs = append(diffs, &Difficulty{Filename: name, MD5: hex.EncodeToString(sum[:]), Beatmap: b})
+	}
+	return diffs, nil
+}
+
+// Audio returns the audio file of a difficulty
+func (a *Archive) Audio(d *Difficulty) ([]byte, error) {
+	if d.Beatmap.General.AudioFilename == "" {
+		return nil, ErrNotFound
+	}
+	return a.ReadFile(d.Beatmap.General.AudioFilename)
+}
+
+// Background returns the background image of a difficulty
+func (a *Archive) Background(d *Difficulty) ([]byte, error) {
+	if d.Beatmap.Events.Background == nil {
+		return nil, ErrNotFound
+	}
+	return a.ReadFile(d.Beatmap.Events.Background.Filename)
+}
+
+// Videos returns the background videos of a difficulty, by file name
+func (a *Archive) Videos(d *Difficulty) (map[string][]byte, error) {
+	videos := make(map[string][]byte)
+	for _, v := range d.Beatmap.Events.Videos {
+		data, err := a.ReadFile(v.Filename)
+		if err != nil {
+			return nil, fmt.Errorf("osz: %s: %w", v.Filename, err)
+		}
+		videos[v.Filename] = data
+	}
+	return videos, nil
+}
+
+// MismatchError lists the beatmaps of the API that an archive doesn't have an up to date difficulty for
+type MismatchError struct {
+	Beatmaps []*osu.Beatmap
+}
+
+func (e *MismatchError) Error() string {
+	ids := make([]string, len(e.Beatmaps))
+	for i, b := range e.Beatmaps {
+		ids[i] = b.BeatmapID.String()
+	}
+	return "osz: no difficulty matches the MD5 of beatmaps " + strings.Join(ids, ", ")
+}
+
+// Verify checks that every beatmap from the API has a difficulty in the archive with the same MD5,
+// returning a *MismatchError listing those that are missing or out of date
+func (a *Archive) Verify(maps []*osu.Beatmap) error {
+	diffs, err := a.Difficulties()
+	if err != nil {
+		return err
+	}
+	hashes := make(map[string]bool)
+	for _, d := range diffs {
+		hashes[d.MD5] = true
+	}
+	var mismatched []*osu.Beatmap
+	for _, b := range maps {
+		if !hashes[strings.ToLower(b.FileMd5)] {
+			mismatched = append(mismatched, b)
+		}
+	}
+	if len(mismatched) > 0 {
+		return &MismatchError{Beatmaps: mismatched}
+	}
+	return nil
+}
+
+// Write writes an .osz archive holding files, by name. Files are written in order of name
+func Write(w io.Writer, files map[string][]byte) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	zw := zip.NewWriter(w)
+	for _, name := range names {
+		fw, err := zw.Create(strings.ReplaceAll(name, `\`, "/"))
+		if err != nil {
+			return errors.New("osz: " + err.Error())
+		}
+		if _, err := fw.Write(files[name]); err != nil {
+			return errors.New("osz: " + err.Error())
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return errors.New("osz: " + err.Error())
+	}
+	return nil
+}
+
+// WriteDir writes an .osz archive holding every file under dir, such as a beatmapset folder of the Songs directory
+func WriteDir(w io.Writer, dir string) error {
+	zw := zip.NewWriter(w)
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		fw, err := zw.Create(filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(fw, f)
+		return err
+	})
+	if err != nil {
+		return errors.New("osz: " + err.Error())
+	}
+	if err := zw.Close(); err != nil {
+		return errors.New("osz: " + err.Error())
+	}
+	return nil
+}
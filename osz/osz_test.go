@@ -0,0 +1,186 @@
+package osz
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pixelrazor/osu"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("..", "beatmap", "testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func md5Hex(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// testArchive writes an archive holding the v14 and v3 sample maps and the files they refer to, except for v14's video
+func testArchive(t *testing.T) (*Archive, map[string][]byte) {
+	t.Helper()
+	files := map[string][]byte{
+		"Insane.osu": readFixture(t, "v14.osu"),
+		"Easy.osu":   readFixture(t, "v3.osu"),
+		"audio.mp3":  []byte("audio"),
+		"BG.jpg":     []byte("background"),
+		`sb\bg.png`:  []byte("sprite"),
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, files); err != nil {
+		t.Fatal(err)
+	}
+	a, err := NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a, files
+}
+
+func TestWrite(t *testing.T) {
+	a, files := testArchive(t)
+	// files are written in order of name, with forward slashes
+	want := []string{"BG.jpg", "Easy.osu", "Insane.osu", "audio.mp3", "sb/bg.png"}
+	if got := a.Files(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got files %q, want %q", got, want)
+	}
+	for name, data := range files {
+		got, err := a.ReadFile(name)
+		if err != nil || !bytes.Equal(got, data) {
+			t.Errorf("%s: got %q, %v", name, got, err)
+		}
+	}
+	// names are matched the way osu! does
+	for _, name := range []string{"bg.JPG", `SB\BG.png`, "./sb//bg.png", `"audio.mp3"`} {
+		if _, err := a.ReadFile(name); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	if _, err := a.ReadFile("missing.png"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got %v for a missing file", err)
+	}
+}
+
+func TestDifficulties(t *testing.T) {
+	a, files := testArchive(t)
+	diffs, err := a.Difficulties()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 2 || diffs[0].Filename != "Easy.osu" || diffs[1].Filename != "Insane.osu" {
+		t.Fatalf("got difficulties %+v", diffs)
+	}
+	for _, d := range diffs {
+		if d.MD5 != md5Hex(files[d.Filename]) {
+			t.Errorf("%s: got MD5 %s", d.Filename, d.MD5)
+		}
+	}
+	insane := diffs[1]
+	if insane.Beatmap.Metadata.Version != "Insane" {
+		t.Errorf("got version %q", insane.Beatmap.Metadata.Version)
+	}
+	if audio, err := a.Audio(insane); err != nil || string(audio) != "audio" {
+		t.Errorf("got audio %q, %v", audio, err)
+	}
+	// the map refers to bg.jpg, which the archive holds as BG.jpg
+	if bg, err := a.Background(insane); err != nil || string(bg) != "background" {
+		t.Errorf("got background %q, %v", bg, err)
+	}
+	if _, err := a.Videos(insane); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got %v for a missing video", err)
+	}
+	if _, err := a.Background(diffs[0]); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got %v for a map without a background", err)
+	}
+}
+
+func TestDifficultiesError(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, map[string][]byte{"broken.osu": []byte("not a beatmap")}); err != nil {
+		t.Fatal(err)
+	}
+	a, err := NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Difficulties(); err == nil {
+		t.Error("no error for a malformed .osu file")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	a, files := testArchive(t)
+	maps := []*osu.Beatmap{
+		{BeatmapID: 1, FileMd5: md5Hex(files["Insane.osu"])},
+		{BeatmapID: 2, FileMd5: md5Hex([]byte("an older version"))},
+		{BeatmapID: 3, FileMd5: md5Hex(files["Easy.osu"])},
+		{BeatmapID: 4},
+	}
+	if err := a.Verify(maps[:1]); err != nil {
+		t.Errorf("got %v", err)
+	}
+	err := a.Verify(maps)
+	var me *MismatchError
+	if !errors.As(err, &me) || len(me.Beatmaps) != 2 || me.Beatmaps[0].BeatmapID != 2 || me.Beatmaps[1].BeatmapID != 4 {
+		t.Fatalf("got %v", err)
+	}
+	if err.Error() != "osz: no difficulty matches the MD5 of beatmaps 2, 4" {
+		t.Errorf("got message %q", err.Error())
+	}
+}
+
+func TestWriteDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"map.osu":       readFixture(t, "v14.osu"),
+		"sb/sprite.png": []byte("sprite"),
+	}
+	for name, data := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "set.osz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteDir(f, dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	if got := a.Files(); !reflect.DeepEqual(got, []string{"map.osu", "sb/sprite.png"}) {
+		t.Errorf("got files %q", got)
+	}
+	for name, data := range files {
+		if got, err := a.ReadFile(name); err != nil || !bytes.Equal(got, data) {
+			t.Errorf("%s: got %q, %v", name, got, err)
+		}
+	}
+	if _, err := Open(filepath.Join(dir, "map.osu")); err == nil {
+		t.Error("opened a file that isn't a zip archive")
+	}
+}
@@ -0,0 +1,141 @@
+// Package pp calculates osu!standard star ratings and performance points locally from beatmaps parsed by package beatmap.
+// The calculation follows the osu!standard difficulty and performance calculators of osu!lazer as released in November 2022,
+// including that version's rules for slider tails. It is not the current calculator: later changes aren't included,
+// so the results differ from the star ratings and pp osu! shows now
+package pp
+
+import (
+	"errors"
+	"math"
+
+	"github.com/pixelrazor/osu"
+	"github.com/pixelrazor/osu/beatmap"
+)
+
+const (
+	// starMultiplier scales the square root of a skill's difficulty into a rating
+	starMultiplier = 0.0675
+	// performanceBaseMultiplier scales total pp, and the star rating to match it
+	performanceBaseMultiplier = 1.14
+)
+
+// Attributes holds the difficulty of a beatmap with a set of mods
+type Attributes struct {
+	Mods       osu.Mods
+	StarRating float64
+	// AimDifficulty, SpeedDifficulty and FlashlightDifficulty are the ratings of each skill.
+	// FlashlightDifficulty is 0 without Flashlight
+	AimDifficulty        float64
+	SpeedDifficulty      float64
+	FlashlightDifficulty float64
+	// SpeedNoteCount is the number of objects weighted by how much they count towards speed
+	SpeedNoteCount float64
+	// SliderFactor is the ratio of the aim rating without sliders to the one with them, 1 for maps where sliders don't add aim difficulty
+	SliderFactor float64
+	// ApproachRate and OverallDifficulty are the values a beatmap without mods would need to play the same at normal speed
+	ApproachRate      float64
+	OverallDifficulty float64
+	DrainRate         float64
+	MaxCombo          int
+	HitCircleCount    int
+	SliderCount       int
+	SpinnerCount      int
+	// AimStrains, SpeedStrains and FlashlightStrains hold the highest strain of each 400ms section of the beatmap,
+	// in order of time and scaled by the clock rate
+	AimStrains        []float64
+	SpeedStrains      []float64
+	FlashlightStrains []float64
+}
+
+// Difficulty calculates the difficulty of an osu!standard beatmap with a set of mods
+func Difficulty(b *beatmap.Beatmap, mods osu.Mods) (*Attributes, error) {
+	if b.General.Mode != osu.ModeOsu {
+		return nil, errors.New("pp: only osu!standard beatmaps are supported, not " + b.General.Mode.String())
+	}
+	rate := mods.Rate()
+	diff := b.Difficulty
+	scaled := osu.AdjustDifficulty(osu.ModeOsu, diff.ApproachRate, diff.OverallDifficulty, diff.CircleSize, diff.HPDrainRate, mods, 0)
+	attrs := &Attributes{
+		Mods:              mods,
+		ApproachRate:      scaled.AR,
+		OverallDifficulty: scaled.OD,
+		DrainRate:         scaled.HP,
+	}
+	if len(b.HitObjects) == 0 {
+		return attrs, nil
+	}
+	var err error
+	if attrs.MaxCombo, err = b.MaxCombo(osu.ModeOsu); err != nil {
+		return nil, err
+	}
+	attrs.HitCircleCount, attrs.SliderCount, attrs.SpinnerCount = b.Counts()
+
+	// objects are placed and timed at normal speed, the rate only applies to the difficulty objects
+	normal := osu.AdjustDifficulty(osu.ModeOsu, diff.ApproachRate, diff.OverallDifficulty, diff.CircleSize, diff.HPDrainRate, mods, 1)
+	objects := playable(b, mods, normal)
+	radius := objectRadius * (1 - 0.7*(normal.CS-5)/5) / 2
+	diffs := diffObjects(objects, radius, normal.HitWindows.Great, rate)
+
+	aim, aimNoSliders, speed := newAim(true), newAim(false), newSpeed()
+	skills := []*skill{aim, aimNoSliders, speed}
+	var flashlight *skill
+	if mods.Has(osu.Flashlight) {
+		flashlight = newFlashlight(mods.Has(osu.Hidden))
+		skills = append(skills, flashlight)
+	}
+	for _, d := range diffs {
+		for _, s := range skills {
+			s.process(d)
+		}
+	}
+
+	aimRating := math.Sqrt(aim.difficulty(reducedSectionCount, difficultyMultiplier)) * starMultiplier
+	aimRatingNoSliders := math.Sqrt(aimNoSliders.difficulty(reducedSectionCount, difficultyMultiplier)) * starMultiplier
+	speedRating := math.Sqrt(speed.difficulty(speedReducedSectionCount, speedDifficultyMultiplier)) * starMultiplier
+	flashlightRating := 0.0
+	if flashlight != nil {
+		// flashlight difficulty is the plain sum of its peaks
+		sum := 0.0
+		for _, p := range flashlight.strainPeaks() {
+			sum += p
+		}
+		flashlightRating = math.Sqrt(sum*difficultyMultiplier) * starMultiplier
+		attrs.FlashlightStrains = flashlight.strainPeaks()
+	}
+	attrs.SliderFactor = 1
+	if aimRating > 0 {
+		attrs.SliderFactor = aimRatingNoSliders / aimRating
+	}
+	if mods.Has(osu.TouchDevice) {
+		aimRating = math.Pow(aimRating, 0.8)
+	}
+	if mods.Has(osu.Relax) {
+		speedRating = 0
+	}
+
+	basePerformance := math.Pow(
+		math.Pow(skillPerformance(aimRating), 1.1)+
+			math.Pow(skillPerformance(speedRating), 1.1)+
+			math.Pow(flashlightPerformance(flashlightRating), 1.1),
+		1/1.1)
+	if basePerformance > 0.00001 {
+		attrs.StarRating = math.Cbrt(performanceBaseMultiplier) * 0.027 * (math.Cbrt(100000/math.Pow(2, 1/1.1)*basePerformance) + 4)
+	}
+	attrs.AimDifficulty = aimRating
+	attrs.SpeedDifficulty = speedRating
+	attrs.SpeedNoteCount = speed.relevantCount()
+	attrs.FlashlightDifficulty = flashlightRating
+	attrs.AimStrains = aim.strainPeaks()
+	attrs.SpeedStrains = speed.strainPeaks()
+	return attrs, nil
+}
+
+// skillPerformance converts an aim or speed rating into its base pp
+func skillPerformance(rating float64) float64 {
+	return math.Pow(5*math.Max(1, rating/starMultiplier)-4, 3) / 100000
+}
+
+// flashlightPerformance converts a flashlight rating into its base pp
+func flashlightPerformance(rating float64) float64 {
+	return rating * rating * 25
+}
@@ -0,0 +1,198 @@
+package pp
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pixelrazor/osu"
+	"github.com/pixelrazor/osu/beatmap"
+)
+
+// tolerance is how far calculated values may be from the expected ones
+const tolerance = 1e-5
+
+func readBeatmap(t *testing.T, name string) *beatmap.Beatmap {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	b, err := beatmap.Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func near(got, want float64) bool {
+	return math.Abs(got-want) <= tolerance
+}
+
+// difficultyTests are the fixtures with the values this package calculates for them: jumps.osu has jumps, triples and
+// a stack, sliders.osu streams and long sliders with repeats and velocity changes, and old.osu is a version 5 beatmap
+var difficultyTests = []struct {
+	file                          string
+	mods                          osu.Mods
+	stars, aim, speed, flashlight float64
+	sliderFactor                  float64
+	maxCombo                      int
+}{
+	{"jumps.osu", 0, 3.480944, 1.804006, 1.493927, 0, 0.939678, 130},
+	{"jumps.osu", osu.Mods(osu.HardRock), 3.750616, 2.006558, 1.498957, 0, 0.917579, 130},
+	{"jumps.osu", osu.Mods(osu.DoubleTime), 4.782841, 2.463525, 2.076408, 0, 0.945947, 130},
+	{"jumps.osu", osu.Mods(osu.HalfTime | osu.Easy), 2.554888, 1.263219, 1.178994, 0, 0.973517, 130},
+	{"jumps.osu", osu.Mods(osu.Flashlight), 3.739328, 1.804006, 1.493927, 0.630209, 0.939678, 130},
+	{"jumps.osu", osu.Mods(osu.Hidden | osu.Flashlight), 3.786244, 1.804006, 1.493927, 0.684710, 0.939678, 130},
+	{"sliders.osu", 0, 4.820767, 2.331813, 2.282308, 0, 0.870370, 199},
+	{"sliders.osu", osu.Mods(osu.HardRock), 5.156387, 2.609571, 2.304262, 0, 0.839843, 199},
+	{"sliders.osu", osu.Mods(osu.DoubleTime), 6.495363, 2.985862, 3.221469, 0, 0.939264, 199},
+	{"sliders.osu", osu.Mods(osu.HalfTime | osu.Easy), 3.642806, 1.809820, 1.670725, 0, 0.883087, 199},
+	{"sliders.osu", osu.Mods(osu.Flashlight), 4.914657, 2.331813, 2.282308, 0.548265, 0.870370, 199},
+	{"sliders.osu", osu.Mods(osu.Hidden | osu.Flashlight), 4.975496, 2.331813, 2.282308, 0.692655, 0.870370, 199},
+	{"old.osu", 0, 3.107960, 1.696831, 1.165827, 0, 0.853214, 50},
+	{"old.osu", osu.Mods(osu.HardRock), 3.309058, 1.831660, 1.175276, 0, 0.823328, 50},
+	{"old.osu", osu.Mods(osu.DoubleTime), 3.916413, 2.072309, 1.609137, 0, 0.923017, 50},
+	{"old.osu", osu.Mods(osu.HalfTime | osu.Easy), 2.410569, 1.319843, 0.893045, 0, 0.846124, 50},
+	{"old.osu", osu.Mods(osu.Flashlight), 3.149477, 1.696831, 1.165827, 0.235064, 0.853214, 50},
+	{"old.osu", osu.Mods(osu.Hidden | osu.Flashlight), 3.179027, 1.696831, 1.165827, 0.301663, 0.853214, 50},
+}
+
+func TestDifficulty(t *testing.T) {
+	for _, tt := range difficultyTests {
+		t.Run(tt.file+" "+tt.mods.String(), func(t *testing.T) {
+			a, err := Difficulty(readBeatmap(t, tt.file), tt.mods)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !near(a.StarRating, tt.stars) || !near(a.AimDifficulty, tt.aim) || !near(a.SpeedDifficulty, tt.speed) || !near(a.FlashlightDifficulty, tt.flashlight) {
+				t.Errorf("got stars %f, aim %f, speed %f, flashlight %f, want %f, %f, %f, %f",
+					a.StarRating, a.AimDifficulty, a.SpeedDifficulty, a.FlashlightDifficulty, tt.stars, tt.aim, tt.speed, tt.flashlight)
+			}
+			if !near(a.SliderFactor, tt.sliderFactor) {
+				t.Errorf("got slider factor %f, want %f", a.SliderFactor, tt.sliderFactor)
+			}
+			if a.MaxCombo != tt.maxCombo {
+				t.Errorf("got max combo %d, want %d", a.MaxCombo, tt.maxCombo)
+			}
+		})
+	}
+}
+
+// referenceTolerance is how far values may be from osu!'s, which rounds some of them
+const referenceTolerance = 1e-3
+
+// TestDifficultyReference checks the difficulty of ranked maps against osu!'s own calculator. Each map is a .osu file in
+// testdata/reference next to a .json file of the same name holding a list of {"mods": "HDHR", "attributes": {...}}, where
+// attributes is the response of the API's POST /beatmaps/{id}/attributes with those mods
+func TestDifficultyReference(t *testing.T) {
+	references, err := filepath.Glob(filepath.Join("testdata", "reference", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(references) == 0 {
+		t.Skip("no reference maps in testdata/reference")
+	}
+	for _, path := range references {
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var tests []struct {
+			Mods       osu.Mods `json:"mods"`
+			Attributes struct {
+				StarRating           float64 `json:"star_rating"`
+				MaxCombo             int     `json:"max_combo"`
+				AimDifficulty        float64 `json:"aim_difficulty"`
+				SpeedDifficulty      float64 `json:"speed_difficulty"`
+				FlashlightDifficulty float64 `json:"flashlight_difficulty"`
+				SliderFactor         float64 `json:"slider_factor"`
+			} `json:"attributes"`
+		}
+		if err := json.Unmarshal(data, &tests); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		b := readBeatmap(t, filepath.Join("reference", name+".osu"))
+		for _, tt := range tests {
+			want := tt.Attributes
+			t.Run(name+" "+tt.Mods.String(), func(t *testing.T) {
+				a, err := Difficulty(b, tt.Mods)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, v := range []struct {
+					name      string
+					got, want float64
+				}{
+					{"stars", a.StarRating, want.StarRating},
+					{"aim", a.AimDifficulty, want.AimDifficulty},
+					{"speed", a.SpeedDifficulty, want.SpeedDifficulty},
+					{"flashlight", a.FlashlightDifficulty, want.FlashlightDifficulty},
+					{"slider factor", a.SliderFactor, want.SliderFactor},
+				} {
+					if math.Abs(v.got-v.want) > referenceTolerance {
+						t.Errorf("got %s %f, want %f", v.name, v.got, v.want)
+					}
+				}
+				if a.MaxCombo != want.MaxCombo {
+					t.Errorf("got max combo %d, want %d", a.MaxCombo, want.MaxCombo)
+				}
+			})
+		}
+	}
+}
+
+func TestDifficultyHidden(t *testing.T) {
+	// Hidden only changes flashlight, through how long objects take to fade in
+	b := readBeatmap(t, "jumps.osu")
+	normal, err := Difficulty(b, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hidden, err := Difficulty(b, osu.Mods(osu.Hidden))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hidden.StarRating != normal.StarRating {
+		t.Errorf("got stars %f with Hidden, want %f", hidden.StarRating, normal.StarRating)
+	}
+	d := osu.AdjustDifficulty(osu.ModeOsu, 9, 8, 4, 5, osu.Mods(osu.Hidden), 1)
+	for _, o := range playable(b, osu.Mods(osu.Hidden), d) {
+		if !near(o.fadeIn, 0.4*d.Preempt) {
+			t.Fatalf("got fade in %f, want %f", o.fadeIn, 0.4*d.Preempt)
+		}
+	}
+}
+
+func TestSliderTail(t *testing.T) {
+	// the tail is judged at the legacy last tick: 36ms before the end, or half way through a short slider
+	b := readBeatmap(t, "sliders.osu")
+	d := osu.AdjustDifficulty(osu.ModeOsu, 8.5, 7, 4.2, 6, 0, 1)
+	sliders := 0
+	for _, o := range playable(b, 0, d) {
+		if o.kind != kindSlider {
+			continue
+		}
+		sliders++
+		want := math.Max(o.start+(o.end-o.start)/2, o.end-36)
+		if tail := o.tail(); !near(tail.time, want) || tail.pos != o.endPos {
+			t.Errorf("slider at %v: got tail at %v, %v, want %v, %v", o.start, tail.time, tail.pos, want, o.endPos)
+		}
+	}
+	if sliders == 0 {
+		t.Fatal("no sliders")
+	}
+}
+
+func TestDifficultyMode(t *testing.T) {
+	b := readBeatmap(t, "jumps.osu")
+	b.General.Mode = osu.ModeTaiko
+	if _, err := Difficulty(b, 0); err == nil {
+		t.Error("no error for a taiko beatmap")
+	}
+}
@@ -0,0 +1,195 @@
+package pp
+
+import (
+	"math"
+
+	"github.com/pixelrazor/osu/beatmap"
+)
+
+const (
+	// normalisedRadius is the circle radius that distances are scaled to, making them independent of circle size
+	normalisedRadius = 50
+	// minDeltaTime caps the time between objects so that simultaneous objects don't break the calculation
+	minDeltaTime        = 25
+	maximumSliderRadius = normalisedRadius * 2.4
+	assumedSliderRadius = normalisedRadius * 1.8
+)
+
+// diffObject is an object with the movement and timing from the previous objects that the skills evaluate.
+// Times are scaled by the clock rate
+type diffObject struct {
+	index    int
+	objects  []*diffObject
+	base     *object
+	last     *object
+	lastLast *object
+	radius   float64
+
+	startTime  float64
+	deltaTime  float64
+	strainTime float64
+
+	lazyJumpDistance    float64
+	minimumJumpDistance float64
+	minimumJumpTime     float64
+	travelDistance      float64
+	travelTime          float64
+	angle               float64
+	hasAngle            bool
+	// hitWindowGreat is the full width of the window for a 300
+	hitWindowGreat float64
+}
+
+// diffObjects creates the difficulty objects of every object but the first
+func diffObjects(objects []*object, radius, great, rate float64) []*diffObject {
+	var diffs []*diffObject
+	for i := 1; i < len(objects); i++ {
+		d := &diffObject{
+			index:  len(diffs),
+			base:   objects[i],
+			last:   objects[i-1],
+			radius: radius,
+		}
+		if i > 1 {
+			d.lastLast = objects[i-2]
+		}
+		d.startTime = d.base.start / rate
+		d.deltaTime = (d.base.start - d.last.start) / rate
+		d.strainTime = math.Max(d.deltaTime, minDeltaTime)
+		if d.base.kind != kindSpinner {
+			d.hitWindowGreat = 2 * great / rate
+		}
+		d.setDistances(rate)
+		diffs = append(diffs, d)
+	}
+	for _, d := range diffs {
+		d.objects = diffs
+	}
+	return diffs
+}
+
+// previous returns the difficulty object i+1 objects before d, or nil
+func (d *diffObject) previous(i int) *diffObject {
+	if j := d.index - (i + 1); j >= 0 {
+		return d.objects[j]
+	}
+	return nil
+}
+
+// next returns the difficulty object i+1 objects after d, or nil
+func (d *diffObject) next(i int) *diffObject {
+	if j := d.index + i + 1; j < len(d.objects) {
+		return d.objects[j]
+	}
+	return nil
+}
+
+func (d *diffObject) setDistances(rate float64) {
+	if d.base.kind == kindSlider {
+		d.computeSliderCursor(d.base)
+		// repeats are rewarded until nested objects are evaluated on their own
+		d.travelDistance = d.base.lazyTravelDistance * math.Pow(1+float64(d.base.repeatCount)/2.5, 1/2.5)
+		d.travelTime = math.Max(d.base.lazyTravelTime/rate, minDeltaTime)
+	}
+	if d.base.kind == kindSpinner || d.last.kind == kindSpinner {
+		return
+	}
+
+	scaling := normalisedRadius / d.radius
+	if d.radius < 30 {
+		scaling *= 1 + math.Min(30-d.radius, 5)/50
+	}
+	lastCursor := d.endCursorPosition(d.last)
+	d.lazyJumpDistance = length(sub(scale(d.base.stackedPos(), scaling), scale(lastCursor, scaling)))
+	d.minimumJumpTime = d.strainTime
+	d.minimumJumpDistance = d.lazyJumpDistance
+
+	if d.last.kind == kindSlider {
+		lastTravelTime := math.Max(d.last.lazyTravelTime/rate, minDeltaTime)
+		d.minimumJumpTime = math.Max(d.strainTime-lastTravelTime, minDeltaTime)
+		// the player either follows the slider to its lazy end or leaves from its tail, whichever is shorter
+		tail := d.last.tail()
+		tailJumpDistance := distance(add(tail.pos, d.last.offset()), d.base.stackedPos()) * scaling
+		d.minimumJumpDistance = math.Max(0, math.Min(d.lazyJumpDistance-(maximumSliderRadius-assumedSliderRadius), tailJumpDistance-maximumSliderRadius))
+	}
+
+	if d.lastLast != nil && d.lastLast.kind != kindSpinner {
+		lastLastCursor := d.endCursorPosition(d.lastLast)
+		v1 := sub(lastLastCursor, d.last.stackedPos())
+		v2 := sub(d.base.stackedPos(), lastCursor)
+		dot := v1.X*v2.X + v1.Y*v2.Y
+		det := v1.X*v2.Y - v1.Y*v2.X
+		d.angle = math.Abs(math.Atan2(det, dot))
+		d.hasAngle = true
+	}
+}
+
+// endCursorPosition returns where the cursor is once an object has been played
+func (d *diffObject) endCursorPosition(o *object) beatmap.Vector {
+	if o.kind == kindSlider {
+		d.computeSliderCursor(o)
+		return o.lazyEnd
+	}
+	return o.stackedPos()
+}
+
+// computeSliderCursor follows a slider with the least movement that keeps the cursor within the follow circle
+func (d *diffObject) computeSliderCursor(s *object) {
+	if s.lazyDone {
+		return
+	}
+	s.lazyDone = true
+
+	s.lazyTravelTime = s.nested[len(s.nested)-1].time - s.start
+
+	endProgress := 0.0
+	if s.timing.SpanDuration > 0 {
+		endProgress = s.lazyTravelTime / s.timing.SpanDuration
+	}
+	if math.Mod(endProgress, 2) >= 1 {
+		endProgress = 1 - math.Mod(endProgress, 1)
+	} else {
+		endProgress = math.Mod(endProgress, 1)
+	}
+	offset := s.offset()
+	s.lazyEnd = add(s.timing.Path.PositionAt(endProgress), offset)
+
+	cursor := s.stackedPos()
+	scaling := normalisedRadius / d.radius
+	for i := 1; i < len(s.nested); i++ {
+		n := s.nested[i]
+		movement := sub(add(n.pos, offset), cursor)
+		movementLength := scaling * length(movement)
+		required := float64(assumedSliderRadius)
+		if i == len(s.nested)-1 {
+			// the end can be reached lazily or at the tail, whichever is less movement
+			lazyMovement := sub(s.lazyEnd, cursor)
+			if length(lazyMovement) < length(movement) {
+				movement = lazyMovement
+			}
+			movementLength = scaling * length(movement)
+		} else if n.repeat {
+			required = normalisedRadius
+		}
+		if movementLength > required {
+			cursor = add(cursor, scale(movement, (movementLength-required)/movementLength))
+			movementLength *= (movementLength - required) / movementLength
+			s.lazyTravelDistance += movementLength
+		}
+		if i == len(s.nested)-1 {
+			s.lazyEnd = cursor
+		}
+	}
+}
+
+// doubletapness returns how easily d and the object after it can be hit with a single tap, from 0 to 1
+func (d *diffObject) doubletapness(next *diffObject) float64 {
+	if next == nil {
+		return 0
+	}
+	currDelta := math.Max(1, d.deltaTime)
+	nextDelta := math.Max(1, next.deltaTime)
+	speedRatio := currDelta / math.Max(currDelta, math.Abs(nextDelta-currDelta))
+	windowRatio := math.Pow(math.Min(1, currDelta/d.hitWindowGreat), 2)
+	return 1 - math.Pow(speedRatio, 1-windowRatio)
+}
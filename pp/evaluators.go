@@ -0,0 +1,251 @@
+package pp
+
+import "math"
+
+const (
+	wideAngleMultiplier      = 1.5
+	acuteAngleMultiplier     = 1.95
+	sliderMultiplier         = 1.35
+	velocityChangeMultiplier = 0.75
+
+	singleSpacingThreshold = 125
+	// minSpeedBonus is the strain time below which speed is rewarded, about 200 BPM streams
+	minSpeedBonus        = 75
+	speedBalancingFactor = 40
+
+	historyTimeMax    = 5000
+	historyObjectsMax = 32
+	rhythmMultiplier  = 0.75
+
+	maxOpacityBonus            = 0.4
+	hiddenBonus                = 0.2
+	minVelocity                = 0.5
+	flashlightSliderMultiplier = 1.3
+	minAngleMultiplier         = 0.2
+)
+
+// aimDifficulty evaluates the aim needed to hit d from the velocity and angles of the jumps to it,
+// optionally counting the distance travelled by sliders
+func aimDifficulty(d *diffObject, withSliders bool) float64 {
+	if d.base.kind == kindSpinner || d.index <= 1 || d.last.kind == kindSpinner {
+		return 0
+	}
+	last, lastLast := d.previous(0), d.previous(1)
+
+	currVelocity := d.lazyJumpDistance / d.strainTime
+	if last.base.kind == kindSlider && withSliders {
+		// a slider's travel carries into the jump after it
+		travelVelocity := last.travelDistance / last.travelTime
+		movementVelocity := d.minimumJumpDistance / d.minimumJumpTime
+		currVelocity = math.Max(currVelocity, movementVelocity+travelVelocity)
+	}
+	prevVelocity := last.lazyJumpDistance / last.strainTime
+	if lastLast.base.kind == kindSlider && withSliders {
+		travelVelocity := lastLast.travelDistance / lastLast.travelTime
+		movementVelocity := last.minimumJumpDistance / last.minimumJumpTime
+		prevVelocity = math.Max(prevVelocity, movementVelocity+travelVelocity)
+	}
+
+	var wideAngleBonus, acuteAngleBonus, sliderBonus, velocityChangeBonus float64
+	strain := currVelocity
+
+	// angles are only rewarded when the rhythm stays the same
+	if math.Max(d.strainTime, last.strainTime) < 1.25*math.Min(d.strainTime, last.strainTime) &&
+		d.hasAngle && last.hasAngle && lastLast.hasAngle {
+		angleBonus := math.Min(currVelocity, prevVelocity)
+		wideAngleBonus = wideAngleBonusOf(d.angle)
+		acuteAngleBonus = acuteAngleBonusOf(d.angle)
+		if d.strainTime > 100 {
+			// only faster than 300 BPM 1/2 is rewarded for acute angles
+			acuteAngleBonus = 0
+		} else {
+			acuteAngleBonus *= acuteAngleBonusOf(last.angle) *
+				math.Min(angleBonus, 125/d.strainTime) *
+				math.Pow(math.Sin(math.Pi/2*math.Min(1, (100-d.strainTime)/25)), 2) *
+				math.Pow(math.Sin(math.Pi/2*(clamp(d.lazyJumpDistance, 50, 100)-50)/50), 2)
+		}
+		// repeated angles are penalised
+		wideAngleBonus *= angleBonus * (1 - math.Min(wideAngleBonus, math.Pow(wideAngleBonusOf(last.angle), 3)))
+		acuteAngleBonus *= 0.5 + 0.5*(1-math.Min(acuteAngleBonus, math.Pow(acuteAngleBonusOf(lastLast.angle), 3)))
+	}
+
+	if math.Max(prevVelocity, currVelocity) != 0 {
+		// changes are measured with the average velocity over whole objects
+		prevVelocity = (last.lazyJumpDistance + lastLast.travelDistance) / last.strainTime
+		currVelocity = (d.lazyJumpDistance + last.travelDistance) / d.strainTime
+		distRatio := math.Pow(math.Sin(math.Pi/2*math.Abs(prevVelocity-currVelocity)/math.Max(prevVelocity, currVelocity)), 2)
+		overlapVelocityBuff := math.Min(125/math.Min(d.strainTime, last.strainTime), math.Abs(prevVelocity-currVelocity))
+		velocityChangeBonus = overlapVelocityBuff * distRatio
+		velocityChangeBonus *= math.Pow(math.Min(d.strainTime, last.strainTime)/math.Max(d.strainTime, last.strainTime), 2)
+	}
+
+	if last.base.kind == kindSlider {
+		sliderBonus = last.travelDistance / last.travelTime
+	}
+
+	strain += math.Max(acuteAngleBonus*acuteAngleMultiplier, wideAngleBonus*wideAngleMultiplier+velocityChangeBonus*velocityChangeMultiplier)
+	if withSliders {
+		strain += sliderBonus * sliderMultiplier
+	}
+	return strain
+}
+
+func wideAngleBonusOf(angle float64) float64 {
+	return math.Pow(math.Sin(3.0/4*(math.Min(5.0/6*math.Pi, math.Max(math.Pi/6, angle))-math.Pi/6)), 2)
+}
+
+func acuteAngleBonusOf(angle float64) float64 {
+	return 1 - wideAngleBonusOf(angle)
+}
+
+// speedDifficulty evaluates the tapping speed needed to hit d, nerfing objects that can be doubletapped
+func speedDifficulty(d *diffObject) float64 {
+	if d.base.kind == kindSpinner {
+		return 0
+	}
+	strainTime := d.strainTime
+	doubletapness := 1 - d.doubletapness(d.next(0))
+
+	// times are capped to the 300 hit window, which 260 BPM streams at OD8 are barely nerfed by
+	strainTime /= clamp(strainTime/d.hitWindowGreat/0.93, 0.92, 1)
+
+	speedBonus := 1.0
+	if strainTime < minSpeedBonus {
+		speedBonus = 1 + 0.75*math.Pow((minSpeedBonus-strainTime)/speedBalancingFactor, 2)
+	}
+	travelDistance := 0.0
+	if prev := d.previous(0); prev != nil {
+		travelDistance = prev.travelDistance
+	}
+	dist := math.Min(singleSpacingThreshold, travelDistance+d.minimumJumpDistance)
+	return (speedBonus + speedBonus*math.Pow(dist/singleSpacingThreshold, 3.5)) * doubletapness / strainTime
+}
+
+// rhythmDifficulty returns the multiplier for the complexity of the rhythm leading up to d, 1 or more
+func rhythmDifficulty(d *diffObject) float64 {
+	if d.base.kind == kindSpinner {
+		return 0
+	}
+	previousIslandSize := 0
+	complexity := 0.0
+	islandSize := 1
+	startRatio := 0.0
+	firstDeltaSwitch := false
+
+	historicalNoteCount := min(d.index, historyObjectsMax)
+	rhythmStart := 0
+	for rhythmStart < historicalNoteCount-2 && d.startTime-d.previous(rhythmStart).startTime < historyTimeMax {
+		rhythmStart++
+	}
+
+	for i := rhythmStart; i > 0; i-- {
+		curr, prev, last := d.previous(i-1), d.previous(i), d.previous(i+1)
+
+		// notes further back, in time or in count, matter less
+		decay := (historyTimeMax - (d.startTime - curr.startTime)) / historyTimeMax
+		decay = math.Min(float64(historicalNoteCount-i)/float64(historicalNoteCount), decay)
+
+		currDelta, prevDelta, lastDelta := curr.strainTime, prev.strainTime, last.strainTime
+		currRatio := 1 + 6*math.Min(0.5, math.Pow(math.Sin(math.Pi/(math.Min(prevDelta, currDelta)/math.Max(prevDelta, currDelta))), 2))
+
+		// changes within the hit window are barely noticeable
+		windowPenalty := 1.0
+		if curr.hitWindowGreat > 0 {
+			windowPenalty = math.Min(1, math.Max(0, math.Abs(prevDelta-currDelta)-curr.hitWindowGreat*0.3)/(curr.hitWindowGreat*0.3))
+		} else if prevDelta == currDelta {
+			windowPenalty = 0
+		}
+		effectiveRatio := windowPenalty * currRatio
+
+		if firstDeltaSwitch {
+			if !(prevDelta > 1.25*currDelta || prevDelta*1.25 < currDelta) {
+				if islandSize < 7 {
+					islandSize++
+				}
+			} else {
+				if curr.base.kind == kindSlider {
+					effectiveRatio *= 0.125
+				}
+				if prev.base.kind == kindSlider {
+					effectiveRatio *= 0.25
+				}
+				if previousIslandSize == islandSize {
+					effectiveRatio *= 0.25
+				}
+				if previousIslandSize%2 == islandSize%2 {
+					effectiveRatio *= 0.5
+				}
+				if lastDelta > prevDelta+10 && prevDelta > currDelta+10 {
+					effectiveRatio *= 0.125
+				}
+				complexity += math.Sqrt(effectiveRatio*startRatio) * decay * math.Sqrt(float64(4+islandSize)) / 2 * math.Sqrt(float64(4+previousIslandSize)) / 2
+
+				startRatio = effectiveRatio
+				previousIslandSize = islandSize
+				if prevDelta*1.25 < currDelta {
+					// slowing down ends the island
+					firstDeltaSwitch = false
+				}
+				islandSize = 1
+			}
+		} else if prevDelta > 1.25*currDelta {
+			// speeding up starts an island
+			firstDeltaSwitch = true
+			startRatio = effectiveRatio
+			islandSize = 1
+		}
+	}
+	return math.Sqrt(4+complexity*rhythmMultiplier) / 2
+}
+
+// flashlightDifficulty evaluates the memorisation needed to hit d with Flashlight from the objects before it
+func flashlightDifficulty(d *diffObject, hidden bool) float64 {
+	if d.base.kind == kindSpinner {
+		return 0
+	}
+	scaling := 52 / d.radius
+	smallDistNerf := 1.0
+	cumulativeStrainTime := 0.0
+	result := 0.0
+	lastObj := d
+	angleRepeatCount := 0.0
+
+	for i := 0; i < min(d.index, 10); i++ {
+		curr := d.previous(i)
+		cumulativeStrainTime += lastObj.strainTime
+		if curr.base.kind != kindSpinner {
+			jumpDistance := distance(d.base.stackedPos(), curr.base.stackedEndPos())
+			// objects within the flashlight radius are easy to see
+			if i == 0 {
+				smallDistNerf = math.Min(1, jumpDistance/75)
+			}
+			// only the first object of a stack counts
+			stackNerf := math.Min(1, curr.lazyJumpDistance/scaling/25)
+			opacityBonus := 1 + maxOpacityBonus*(1-d.base.opacityAt(curr.base.start, hidden))
+			result += stackNerf * opacityBonus * scaling * jumpDistance / cumulativeStrainTime
+
+			if curr.hasAngle && d.hasAngle && math.Abs(curr.angle-d.angle) < 0.02 {
+				angleRepeatCount += math.Max(1-0.1*float64(i), 0)
+			}
+		}
+		lastObj = curr
+	}
+	result = math.Pow(smallDistNerf*result, 2)
+	if hidden {
+		result *= 1 + hiddenBonus
+	}
+	// repeated angles are easier to remember
+	result *= minAngleMultiplier + (1-minAngleMultiplier)/(angleRepeatCount+1)
+
+	sliderBonus := 0.0
+	if d.base.kind == kindSlider {
+		pixelTravelDistance := d.base.lazyTravelDistance / scaling
+		sliderBonus = math.Pow(math.Max(0, pixelTravelDistance/d.travelTime-minVelocity), 0.5)
+		// longer sliders need more memorisation, repeats less
+		sliderBonus *= pixelTravelDistance
+		if d.base.repeatCount > 0 {
+			sliderBonus /= float64(d.base.repeatCount + 1)
+		}
+	}
+	return result + sliderBonus*flashlightSliderMultiplier
+}
@@ -0,0 +1,262 @@
+package pp
+
+import (
+	"math"
+	"sort"
+
+	"github.com/pixelrazor/osu"
+	"github.com/pixelrazor/osu/beatmap"
+)
+
+const (
+	playfieldHeight = 384
+	objectRadius    = 64
+	// stackDistance is how close in osu! pixels objects must be to stack
+	stackDistance = 3
+	// hiddenFadeIn is the part of the preempt objects fade in during with Hidden
+	hiddenFadeIn = 0.4
+)
+
+type objectKind int
+
+const (
+	kindCircle objectKind = iota
+	kindSlider
+	kindSpinner
+)
+
+// object is a hit object as it is played: flipped by HardRock and stacked
+type object struct {
+	kind       objectKind
+	start, end float64
+	pos        beatmap.Vector
+	// endPos is where a slider ends after its repeats, pathEnd where its path ends
+	endPos      beatmap.Vector
+	pathEnd     beatmap.Vector
+	stackHeight int
+	// stackScale turns a stack height into an offset
+	stackScale float64
+	preempt    float64
+	fadeIn     float64
+
+	// slider values
+	timing      *beatmap.SliderTiming
+	nested      []nestedObject
+	repeatCount int
+	// lazy cursor movement through the slider, computed on first use
+	lazyDone           bool
+	lazyEnd            beatmap.Vector
+	lazyTravelDistance float64
+	lazyTravelTime     float64
+}
+
+// nestedObject is a judged point of a slider: its head, a tick, a repeat or its tail.
+// The tail is judged at the slider's legacy last tick, shortly before its end
+type nestedObject struct {
+	time   float64
+	pos    beatmap.Vector
+	repeat bool
+	tail   bool
+}
+
+func (o *object) offset() beatmap.Vector {
+	f := float64(o.stackHeight) * o.stackScale * -6.4
+	return beatmap.Vector{X: f, Y: f}
+}
+
+func (o *object) stackedPos() beatmap.Vector {
+	return add(o.pos, o.offset())
+}
+
+func (o *object) stackedEndPos() beatmap.Vector {
+	return add(o.endPos, o.offset())
+}
+
+// tail returns the tail of a slider
+func (o *object) tail() nestedObject {
+	for i := len(o.nested) - 1; i > 0; i-- {
+		if o.nested[i].tail {
+			return o.nested[i]
+		}
+	}
+	return o.nested[len(o.nested)-1]
+}
+
+// opacityAt returns how visible the object is at time, without a rate applied
+func (o *object) opacityAt(time float64, hidden bool) float64 {
+	if time > o.start {
+		return 0
+	}
+	fadeInStart := o.start - o.preempt
+	fadeIn := clamp((time-fadeInStart)/o.fadeIn, 0, 1)
+	if !hidden {
+		return fadeIn
+	}
+	fadeOutStart := fadeInStart + o.fadeIn
+	fadeOutDuration := o.preempt * 0.3
+	return math.Min(fadeIn, 1-clamp((time-fadeOutStart)/fadeOutDuration, 0, 1))
+}
+
+// playable returns the objects of a beatmap as they are played with mods, where d holds the settings with mods at normal speed
+func playable(b *beatmap.Beatmap, mods osu.Mods, d osu.Difficulty) []*object {
+	scale := (1 - 0.7*(d.CS-5)/5) / 2
+	fadeIn := 400 * math.Min(1, d.Preempt/450)
+	if mods.Has(osu.Hidden) {
+		fadeIn = d.Preempt * hiddenFadeIn
+	}
+	objects := make([]*object, 0, len(b.HitObjects))
+	for i := range b.HitObjects {
+		h := b.HitObjects[i]
+		if mods.Has(osu.HardRock) {
+			h.Y = playfieldHeight - h.Y
+			if h.Slider != nil {
+				s := *h.Slider
+				s.CurvePoints = make([]beatmap.Point, len(h.Slider.CurvePoints))
+				for j, p := range h.Slider.CurvePoints {
+					s.CurvePoints[j] = beatmap.Point{X: p.X, Y: playfieldHeight - p.Y}
+				}
+				h.Slider = &s
+			}
+		}
+		o := &object{
+			start:      float64(h.Time),
+			end:        float64(h.Time),
+			pos:        beatmap.Vector{X: float64(h.X), Y: float64(h.Y)},
+			stackScale: scale,
+			preempt:    d.Preempt,
+			fadeIn:     fadeIn,
+		}
+		o.endPos, o.pathEnd = o.pos, o.pos
+		switch {
+		case h.IsSlider() && h.Slider != nil:
+			st := b.SliderTiming(&h)
+			o.kind = kindSlider
+			o.timing = st
+			o.end = st.EndTime
+			o.endPos = st.EndPosition
+			o.pathEnd = st.Path.PositionAt(1)
+			o.repeatCount = max(h.Slider.Slides, 1) - 1
+			for _, e := range st.Events {
+				switch e.Type {
+				case beatmap.SliderTail:
+				case beatmap.SliderLastTick:
+					o.nested = append(o.nested, nestedObject{time: e.Time, pos: st.EndPosition, tail: true})
+				default:
+					o.nested = append(o.nested, nestedObject{time: e.Time, pos: e.Position, repeat: e.Type == beatmap.SliderRepeat})
+				}
+			}
+			// a tick can come after the last tick
+			sort.SliceStable(o.nested, func(i, j int) bool { return o.nested[i].time < o.nested[j].time })
+		case h.IsSpinner():
+			o.kind = kindSpinner
+			o.end = float64(h.EndTime)
+		}
+		objects = append(objects, o)
+	}
+	if b.Version >= 6 {
+		stack(objects, b.General.StackLeniency)
+	} else {
+		stackOld(objects, b.General.StackLeniency)
+	}
+	return objects
+}
+
+// stack sets the stack heights of objects the way osu! does for beatmaps of version 6 and later
+func stack(objects []*object, leniency float64) {
+	for i := len(objects) - 1; i > 0; i-- {
+		objI := objects[i]
+		if objI.stackHeight != 0 || objI.kind == kindSpinner {
+			continue
+		}
+		threshold := objI.preempt * leniency
+		switch objI.kind {
+		case kindCircle:
+			for n := i - 1; n >= 0; n-- {
+				objN := objects[n]
+				if objN.kind == kindSpinner {
+					continue
+				}
+				if objI.start-objN.end > threshold {
+					break
+				}
+				// circles under the end of a slider stack down and to the right of it
+				if objN.kind == kindSlider && distance(objN.endPos, objI.pos) < stackDistance {
+					offset := objI.stackHeight - objN.stackHeight + 1
+					for j := n + 1; j <= i; j++ {
+						if distance(objN.endPos, objects[j].pos) < stackDistance {
+							objects[j].stackHeight -= offset
+						}
+					}
+					break
+				}
+				if distance(objN.pos, objI.pos) < stackDistance {
+					objN.stackHeight = objI.stackHeight + 1
+					objI = objN
+				}
+			}
+		case kindSlider:
+			for n := i - 1; n >= 0; n-- {
+				objN := objects[n]
+				if objN.kind == kindSpinner {
+					continue
+				}
+				if objI.start-objN.start > threshold {
+					break
+				}
+				if distance(objN.endPos, objI.pos) < stackDistance {
+					objN.stackHeight = objI.stackHeight + 1
+					objI = objN
+				}
+			}
+		}
+	}
+}
+
+// stackOld sets the stack heights of objects the way osu! does for beatmaps older than version 6
+func stackOld(objects []*object, leniency float64) {
+	for i, cur := range objects {
+		if cur.stackHeight != 0 && cur.kind != kindSlider {
+			continue
+		}
+		startTime := cur.end
+		sliderStack := 0
+		for j := i + 1; j < len(objects); j++ {
+			if objects[j].start-cur.preempt*leniency > startTime {
+				break
+			}
+			if distance(objects[j].pos, cur.pos) < stackDistance {
+				cur.stackHeight++
+				startTime = objects[j].start
+			} else if distance(objects[j].pos, cur.pathEnd) < stackDistance {
+				// objects at the end of a slider stack down and to the right
+				sliderStack++
+				objects[j].stackHeight -= sliderStack
+				startTime = objects[j].start
+			}
+		}
+	}
+}
+
+func add(a, b beatmap.Vector) beatmap.Vector {
+	return beatmap.Vector{X: a.X + b.X, Y: a.Y + b.Y}
+}
+
+func sub(a, b beatmap.Vector) beatmap.Vector {
+	return beatmap.Vector{X: a.X - b.X, Y: a.Y - b.Y}
+}
+
+func scale(v beatmap.Vector, f float64) beatmap.Vector {
+	return beatmap.Vector{X: v.X * f, Y: v.Y * f}
+}
+
+func length(v beatmap.Vector) float64 {
+	return math.Hypot(v.X, v.Y)
+}
+
+func distance(a, b beatmap.Vector) float64 {
+	return length(sub(a, b))
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(v, hi))
+}
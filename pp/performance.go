@@ -0,0 +1,282 @@
+package pp
+
+import (
+	"errors"
+	"math"
+
+	"github.com/pixelrazor/osu"
+)
+
+// Score holds what performance is calculated from: the mods, highest combo and hits of an osu!standard play
+type Score struct {
+	Mods     osu.Mods
+	MaxCombo int
+	Hits     osu.Hits
+}
+
+// FromScore returns the performance inputs of a score from the API
+func FromScore(s *osu.Score) Score {
+	return Score{Mods: s.EnabledMods, MaxCombo: int(s.Maxcombo), Hits: s.Hits()}
+}
+
+// FromBestScore returns the performance inputs of a user's best score
+func FromBestScore(s *osu.BestScore) Score {
+	return Score{Mods: s.EnabledMods, MaxCombo: int(s.Maxcombo), Hits: s.Hits()}
+}
+
+// FromRecentScore returns the performance inputs of a user's recent score. Failed plays are rated on the hits they got
+func FromRecentScore(s *osu.RecentScore) Score {
+	return Score{Mods: s.EnabledMods, MaxCombo: int(s.Maxcombo), Hits: s.Hits()}
+}
+
+// FromMatchScore returns the performance inputs of a player's score in a multiplayer game, which must be of osu!standard.
+// The player plays with the game's mods along with their own
+func FromMatchScore(game *osu.MatchGame, s *osu.MatchScore) (Score, error) {
+	if game.PlayMode != osu.ModeOsu {
+		return Score{}, errors.New("pp: only osu!standard games are supported, not " + game.PlayMode.String())
+	}
+	return Score{Mods: game.Mods | s.EnabledMods, MaxCombo: int(s.Maxcombo), Hits: s.Hits()}, nil
+}
+
+// FromReplay returns the performance inputs of a replay, which must be of osu!standard
+func FromReplay(rf *osu.ReplayFile) (Score, error) {
+	if rf.Mode != osu.ModeOsu {
+		return Score{}, errors.New("pp: only osu!standard replays are supported, not " + rf.Mode.String())
+	}
+	return Score{Mods: rf.EnabledMods, MaxCombo: int(rf.Maxcombo), Hits: rf.Hits()}, nil
+}
+
+// Performance is the pp of a play and the components it is made of
+type Performance struct {
+	Total      float64
+	Aim        float64
+	Speed      float64
+	Accuracy   float64
+	Flashlight float64
+	// EffectiveMissCount is the number of misses plus the slider breaks guessed from the combo
+	EffectiveMissCount float64
+}
+
+// Result holds the performance of a play, along with what it would be worth as a full combo with the same 100s and 50s,
+// and with only 300s
+type Result struct {
+	Performance
+	IfFC Performance
+	IfSS Performance
+}
+
+// Calculate returns the performance of a score on a beatmap with the given difficulty, which should be calculated with
+// the score's mods
+func Calculate(attrs *Attributes, s Score) *Result {
+	objects := int64(attrs.HitCircleCount + attrs.SliderCount + attrs.SpinnerCount)
+	fc := s
+	fc.MaxCombo = attrs.MaxCombo
+	fc.Hits.Countmiss = 0
+	fc.Hits.Count300 = max(objects-fc.Hits.Count100-fc.Hits.Count50, 0)
+	ss := fc
+	ss.Hits = osu.Hits{Count300: objects}
+	return &Result{
+		Performance: performance(attrs, s),
+		IfFC:        performance(attrs, fc),
+		IfSS:        performance(attrs, ss),
+	}
+}
+
+func performance(attrs *Attributes, s Score) Performance {
+	var p Performance
+	h := s.Hits
+	totalHits := float64(h.TotalHits(osu.ModeOsu))
+	if totalHits == 0 {
+		return p
+	}
+	c := calculation{
+		attrs:     attrs,
+		mods:      s.Mods,
+		accuracy:  h.Accuracy(osu.ModeOsu),
+		combo:     float64(s.MaxCombo),
+		great:     float64(h.Count300),
+		ok:        float64(h.Count100),
+		meh:       float64(h.Count50),
+		miss:      float64(h.Countmiss),
+		totalHits: totalHits,
+	}
+	c.effectiveMissCount = c.calculateEffectiveMissCount()
+
+	multiplier := performanceBaseMultiplier
+	if s.Mods.Has(osu.NoFail) {
+		multiplier *= math.Max(0.9, 1-0.02*c.effectiveMissCount)
+	}
+	if s.Mods.Has(osu.SpunOut) {
+		multiplier *= 1 - math.Pow(float64(attrs.SpinnerCount)/totalHits, 0.85)
+	}
+	if s.Mods.Has(osu.Relax) {
+		// 100s and 50s count as breaks, but there can't be more breaks than objects
+		c.effectiveMissCount = math.Min(c.effectiveMissCount+c.ok+c.meh, totalHits)
+		multiplier *= 0.6
+	}
+
+	p.Aim = c.aim()
+	p.Speed = c.speed()
+	p.Accuracy = c.accuracyValue()
+	p.Flashlight = c.flashlight()
+	p.Total = math.Pow(
+		math.Pow(p.Aim, 1.1)+math.Pow(p.Speed, 1.1)+math.Pow(p.Accuracy, 1.1)+math.Pow(p.Flashlight, 1.1),
+		1/1.1) * multiplier
+	p.EffectiveMissCount = c.effectiveMissCount
+	return p
+}
+
+// calculation holds the values of a score that the performance components are computed from
+type calculation struct {
+	attrs                *Attributes
+	mods                 osu.Mods
+	accuracy             float64
+	combo                float64
+	great, ok, meh, miss float64
+	totalHits            float64
+	effectiveMissCount   float64
+}
+
+// calculateEffectiveMissCount guesses the number of misses and slider breaks from the combo
+func (c *calculation) calculateEffectiveMissCount() float64 {
+	comboBasedMissCount := 0.0
+	if c.attrs.SliderCount > 0 {
+		fullComboThreshold := float64(c.attrs.MaxCombo) - 0.1*float64(c.attrs.SliderCount)
+		if c.combo < fullComboThreshold {
+			comboBasedMissCount = fullComboThreshold / math.Max(1, c.combo)
+		}
+	}
+	// there can't be more breaks than non-300 hits
+	comboBasedMissCount = math.Min(comboBasedMissCount, c.ok+c.meh+c.miss)
+	return math.Max(c.miss, comboBasedMissCount)
+}
+
+func (c *calculation) lengthBonus() float64 {
+	bonus := 0.95 + 0.4*math.Min(1, c.totalHits/2000)
+	if c.totalHits > 2000 {
+		bonus += math.Log10(c.totalHits/2000) * 0.5
+	}
+	return bonus
+}
+
+func (c *calculation) comboScaling() float64 {
+	if c.attrs.MaxCombo <= 0 {
+		return 1
+	}
+	return math.Min(math.Pow(c.combo, 0.8)/math.Pow(float64(c.attrs.MaxCombo), 0.8), 1)
+}
+
+func (c *calculation) aim() float64 {
+	value := skillPerformance(c.attrs.AimDifficulty)
+	lengthBonus := c.lengthBonus()
+	value *= lengthBonus
+
+	// any miss costs at least 3%
+	if c.effectiveMissCount > 0 {
+		value *= 0.97 * math.Pow(1-math.Pow(c.effectiveMissCount/c.totalHits, 0.775), c.effectiveMissCount)
+	}
+	value *= c.comboScaling()
+
+	approachRateFactor := 0.0
+	if c.attrs.ApproachRate > 10.33 {
+		approachRateFactor = 0.3 * (c.attrs.ApproachRate - 10.33)
+	} else if c.attrs.ApproachRate < 8 {
+		approachRateFactor = 0.05 * (8 - c.attrs.ApproachRate)
+	}
+	value *= 1 + approachRateFactor*lengthBonus
+	if c.mods.Has(osu.Hidden) {
+		value *= 1 + 0.04*(12-c.attrs.ApproachRate)
+	}
+
+	// 15% of sliders are assumed to be difficult, and dropped slider ends are taken from them
+	if c.attrs.SliderCount > 0 {
+		difficultSliders := float64(c.attrs.SliderCount) * 0.15
+		sliderEndsDropped := clamp(math.Min(c.ok+c.meh+c.miss, float64(c.attrs.MaxCombo)-c.combo), 0, difficultSliders)
+		sliderNerfFactor := (1-c.attrs.SliderFactor)*math.Pow(1-sliderEndsDropped/difficultSliders, 3) + c.attrs.SliderFactor
+		value *= sliderNerfFactor
+	}
+	value *= c.accuracy
+	value *= 0.98 + math.Pow(c.attrs.OverallDifficulty, 2)/2500
+	return value
+}
+
+func (c *calculation) speed() float64 {
+	value := skillPerformance(c.attrs.SpeedDifficulty)
+	lengthBonus := c.lengthBonus()
+	value *= lengthBonus
+
+	if c.effectiveMissCount > 0 {
+		value *= 0.97 * math.Pow(1-math.Pow(c.effectiveMissCount/c.totalHits, 0.775), math.Pow(c.effectiveMissCount, 0.875))
+	}
+	value *= c.comboScaling()
+
+	approachRateFactor := 0.0
+	if c.attrs.ApproachRate > 10.33 {
+		approachRateFactor = 0.3 * (c.attrs.ApproachRate - 10.33)
+	}
+	value *= 1 + approachRateFactor*lengthBonus
+	if c.mods.Has(osu.Hidden) {
+		value *= 1 + 0.04*(12-c.attrs.ApproachRate)
+	}
+
+	// accuracy on the objects that count towards speed assumes the worst: that the 100s and 50s are among them
+	relevantTotalDiff := c.totalHits - c.attrs.SpeedNoteCount
+	relevantGreat := math.Max(0, c.great-relevantTotalDiff)
+	relevantOk := math.Max(0, c.ok-math.Max(0, relevantTotalDiff-c.great))
+	relevantMeh := math.Max(0, c.meh-math.Max(0, relevantTotalDiff-c.great-c.ok))
+	relevantAccuracy := 0.0
+	if c.attrs.SpeedNoteCount != 0 {
+		relevantAccuracy = (relevantGreat*6 + relevantOk*2 + relevantMeh) / (c.attrs.SpeedNoteCount * 6)
+	}
+	value *= (0.95 + math.Pow(c.attrs.OverallDifficulty, 2)/750) *
+		math.Pow((c.accuracy+relevantAccuracy)/2, (14.5-math.Max(c.attrs.OverallDifficulty, 8))/2)
+
+	// 50s suggest doubletapping
+	if c.meh >= c.totalHits/500 {
+		value *= math.Pow(0.99, c.meh-c.totalHits/500)
+	}
+	return value
+}
+
+func (c *calculation) accuracyValue() float64 {
+	// only circles are judged purely on timing
+	circles := float64(c.attrs.HitCircleCount)
+	betterAccuracy := 0.0
+	if circles > 0 {
+		betterAccuracy = ((c.great-(c.totalHits-circles))*6 + c.ok*2 + c.meh) / (circles * 6)
+	}
+	betterAccuracy = math.Max(0, betterAccuracy)
+
+	value := math.Pow(1.52163, c.attrs.OverallDifficulty) * math.Pow(betterAccuracy, 24) * 2.83
+	// keeping accuracy up is harder over more circles
+	value *= math.Min(1.15, math.Pow(circles/1000, 0.3))
+	if c.mods.Has(osu.Hidden) {
+		value *= 1.08
+	}
+	if c.mods.Has(osu.Flashlight) {
+		value *= 1.02
+	}
+	return value
+}
+
+func (c *calculation) flashlight() float64 {
+	if !c.mods.Has(osu.Flashlight) {
+		return 0
+	}
+	value := flashlightPerformance(c.attrs.FlashlightDifficulty)
+
+	if c.effectiveMissCount > 0 {
+		value *= 0.97 * math.Pow(1-math.Pow(c.effectiveMissCount/c.totalHits, 0.775), math.Pow(c.effectiveMissCount, 0.875))
+	}
+	value *= c.comboScaling()
+
+	// short maps spend more of their time with the larger flashlight radius of low combo
+	lengthFactor := 0.7 + 0.1*math.Min(1, c.totalHits/200)
+	if c.totalHits > 200 {
+		lengthFactor += 0.2 * math.Min(1, (c.totalHits-200)/200)
+	}
+	value *= lengthFactor
+	value *= 0.5 + c.accuracy/2
+	value *= 0.98 + math.Pow(c.attrs.OverallDifficulty, 2)/2500
+	return value
+}
@@ -0,0 +1,66 @@
+package pp
+
+import (
+	"testing"
+
+	"github.com/pixelrazor/osu"
+)
+
+func TestCalculate(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		score Score
+		want  Performance
+		// fc and ss are the total pp of IfFC and IfSS
+		fc, ss float64
+	}{
+		{"SS", "jumps.osu", Score{0, 130, osu.Hits{Count300: 114}},
+			Performance{77.115788, 21.309166, 12.221489, 40.391544, 0, 0}, 77.115788, 77.115788},
+		{"HDDT misses", "jumps.osu", Score{osu.Mods(osu.Hidden | osu.DoubleTime), 80, osu.Hits{Count300: 103, Count100: 8, Count50: 1, Countmiss: 2}},
+			Performance{62.799129, 31.775852, 17.406253, 11.146040, 0, 2}, 111.049331, 196.994310},
+		{"HR slider breaks", "sliders.osu", Score{osu.Mods(osu.HardRock), 150, osu.Hits{Count300: 138, Count100: 5, Countmiss: 1}},
+			Performance{122.161484, 41.137619, 33.454095, 43.748513, 0, 1.321333}, 169.585946, 219.142896},
+		{"HDFL", "sliders.osu", Score{osu.Mods(osu.Hidden | osu.Flashlight), 199, osu.Hits{Count300: 141, Count100: 3}},
+			Performance{135.718957, 52.832813, 48.180417, 22.678631, 9.191601, 0}, 135.718957, 148.917456},
+		{"NFSO", "old.osu", Score{osu.Mods(osu.NoFail | osu.SpunOut), 30, osu.Hits{Count300: 30, Count100: 6, Count50: 2, Countmiss: 2}},
+			Performance{8.794709, 7.068726, 1.257978, 0.022364, 0, 2}, 21.754759, 34.808964},
+		// with Relax 100s and 50s count as misses
+		{"RX", "old.osu", Score{osu.Mods(osu.Relax), 50, osu.Hits{Count300: 38, Count100: 2}},
+			Performance{11.962370, 14.908994, 0.000007, 3.324466, 0, 2}, 11.962370, 18.121716},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attrs, err := Difficulty(readBeatmap(t, tt.file), tt.score.Mods)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := Calculate(attrs, tt.score)
+			p := got.Performance
+			if !near(p.Total, tt.want.Total) || !near(p.Aim, tt.want.Aim) || !near(p.Speed, tt.want.Speed) || !near(p.Accuracy, tt.want.Accuracy) ||
+				!near(p.Flashlight, tt.want.Flashlight) || !near(p.EffectiveMissCount, tt.want.EffectiveMissCount) {
+				t.Errorf("got %+v, want %+v", p, tt.want)
+			}
+			if !near(got.IfFC.Total, tt.fc) || !near(got.IfSS.Total, tt.ss) {
+				t.Errorf("got if FC %f and if SS %f, want %f and %f", got.IfFC.Total, got.IfSS.Total, tt.fc, tt.ss)
+			}
+		})
+	}
+}
+
+func TestFromMatchScore(t *testing.T) {
+	game := &osu.MatchGame{PlayMode: osu.ModeOsu, Mods: osu.Mods(osu.Hidden)}
+	s := &osu.MatchScore{Maxcombo: 100, Count300: 90, Count100: 5, Countmiss: 1, EnabledMods: osu.Mods(osu.HardRock)}
+	got, err := FromMatchScore(game, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Score{Mods: osu.Mods(osu.Hidden | osu.HardRock), MaxCombo: 100, Hits: osu.Hits{Count300: 90, Count100: 5, Countmiss: 1}}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	game.PlayMode = osu.ModeMania
+	if _, err := FromMatchScore(game, s); err == nil {
+		t.Error("no error for a mania game")
+	}
+}
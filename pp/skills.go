@@ -0,0 +1,146 @@
+package pp
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// sectionLength is the length in milliseconds of the sections strain peaks are taken from
+	sectionLength = 400
+	decayWeight   = 0.9
+	// the hardest sections are reduced to lessen the effect of short spikes of difficulty
+	reducedSectionCount   = 10
+	reducedStrainBaseline = 0.75
+	difficultyMultiplier  = 1.06
+
+	aimSkillMultiplier        = 23.55
+	aimStrainDecayBase        = 0.15
+	speedSkillMultiplier      = 1375
+	speedStrainDecayBase      = 0.3
+	speedReducedSectionCount  = 5
+	speedDifficultyMultiplier = 1.04
+	flashlightSkillMultiplier = 0.052
+	flashlightStrainDecayBase = 0.15
+)
+
+// skill accumulates the strain of objects, keeping the highest strain of each section
+type skill struct {
+	// strainAt adds an object to the skill and returns the strain after it
+	strainAt func(d *diffObject) float64
+	// initialStrain returns the strain at the start of a section, decayed from the object before d
+	initialStrain func(time float64, d *diffObject) float64
+
+	sectionEnd    float64
+	sectionPeak   float64
+	peaks         []float64
+	objectStrains []float64
+}
+
+func (s *skill) process(d *diffObject) {
+	if d.index == 0 {
+		s.sectionEnd = math.Ceil(d.startTime/sectionLength) * sectionLength
+	}
+	for d.startTime > s.sectionEnd {
+		s.peaks = append(s.peaks, s.sectionPeak)
+		s.sectionPeak = s.initialStrain(s.sectionEnd, d)
+		s.sectionEnd += sectionLength
+	}
+	strain := s.strainAt(d)
+	s.sectionPeak = math.Max(strain, s.sectionPeak)
+	s.objectStrains = append(s.objectStrains, strain)
+}
+
+// strainPeaks returns the highest strain of each section
+func (s *skill) strainPeaks() []float64 {
+	return append(append([]float64(nil), s.peaks...), s.sectionPeak)
+}
+
+// difficulty returns the weighted sum of the strain peaks, hardest first, after reducing the hardest reduced of them
+func (s *skill) difficulty(reduced int, multiplier float64) float64 {
+	var strains []float64
+	for _, p := range s.strainPeaks() {
+		if p > 0 {
+			strains = append(strains, p)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(strains)))
+	for i := 0; i < min(len(strains), reduced); i++ {
+		scale := math.Log10(lerp(1, 10, clamp(float64(i)/float64(reduced), 0, 1)))
+		strains[i] *= lerp(reducedStrainBaseline, 1, scale)
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(strains)))
+	difficulty, weight := 0.0, 1.0
+	for _, strain := range strains {
+		difficulty += strain * weight
+		weight *= decayWeight
+	}
+	return difficulty * multiplier
+}
+
+// relevantCount weighs each object's strain against the highest, counting how many objects are near the hardest
+func (s *skill) relevantCount() float64 {
+	maxStrain := 0.0
+	for _, strain := range s.objectStrains {
+		maxStrain = math.Max(maxStrain, strain)
+	}
+	if maxStrain == 0 {
+		return 0
+	}
+	count := 0.0
+	for _, strain := range s.objectStrains {
+		count += 1 / (1 + math.Exp(-(strain/maxStrain*12 - 6)))
+	}
+	return count
+}
+
+func strainDecay(base, ms float64) float64 {
+	return math.Pow(base, ms/1000)
+}
+
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
+
+func newAim(withSliders bool) *skill {
+	current := 0.0
+	return &skill{
+		strainAt: func(d *diffObject) float64 {
+			current *= strainDecay(aimStrainDecayBase, d.deltaTime)
+			current += aimDifficulty(d, withSliders) * aimSkillMultiplier
+			return current
+		},
+		initialStrain: func(time float64, d *diffObject) float64 {
+			return current * strainDecay(aimStrainDecayBase, time-d.previous(0).startTime)
+		},
+	}
+}
+
+func newSpeed() *skill {
+	current, rhythm := 0.0, 0.0
+	return &skill{
+		strainAt: func(d *diffObject) float64 {
+			current *= strainDecay(speedStrainDecayBase, d.strainTime)
+			current += speedDifficulty(d) * speedSkillMultiplier
+			rhythm = rhythmDifficulty(d)
+			return current * rhythm
+		},
+		initialStrain: func(time float64, d *diffObject) float64 {
+			return current * rhythm * strainDecay(speedStrainDecayBase, time-d.previous(0).startTime)
+		},
+	}
+}
+
+func newFlashlight(hidden bool) *skill {
+	current := 0.0
+	return &skill{
+		strainAt: func(d *diffObject) float64 {
+			current *= strainDecay(flashlightStrainDecayBase, d.deltaTime)
+			current += flashlightDifficulty(d, hidden) * flashlightSkillMultiplier
+			return current
+		},
+		initialStrain: func(time float64, d *diffObject) float64 {
+			return current * strainDecay(flashlightStrainDecayBase, time-d.previous(0).startTime)
+		},
+	}
+}
@@ -0,0 +1,32 @@
+package osu
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// redirect sends every request to a test server, whatever host it was made for
+type redirect struct {
+	target *url.URL
+	next   http.RoundTripper
+}
+
+func (r redirect) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = r.target.Scheme, r.target.Host
+	return r.next.RoundTrip(req)
+}
+
+// serve starts a test server running handler and returns a transport that sends the clients' requests to it
+func serve(t *testing.T, handler http.HandlerFunc) http.RoundTripper {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	target, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return redirect{target, srv.Client().Transport}
+}
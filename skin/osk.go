@@ -0,0 +1,160 @@
+package skin
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/pixelrazor/osu/internal/ziparchive"
+)
+
+// ErrNotFound is returned when a file or element is not in an archive
+var ErrNotFound = errors.New("skin: file not found")
+
+// imageExtensions are the image formats osu! loads skin elements from, in order of preference
+var imageExtensions = []string{".png", ".jpg"}
+
+// Archive is an open .osk skin archive
+type Archive struct {
+	// Skin is the archive's skin.ini, or the defaults of a skin without one
+	Skin *Skin
+	z    *ziparchive.Archive
+}
+
+// Element is the image file found for a skin element
+type Element struct {
+	// File is the name of the image in the archive
+	File string
+	// HD reports whether the image is an @2x image, which is drawn at half its size
+	HD bool
+}
+
+// Open opens the .osk file at path. The archive must be closed after use
+func Open(path string) (*Archive, error) {
+	z, err := ziparchive.Open(path, ErrNotFound)
+	if err != nil {
+		return nil, errors.New("skin: " + err.Error())
+	}
+	a, err := newArchive(z)
+	if err != nil {
+		z.Close()
+		return nil, err
+	}
+	return a, nil
+}
+
+// NewReader reads an .osk archive of the given size from r
+func NewReader(r io.ReaderAt, size int64) (*Archive, error) {
+	z, err := ziparchive.NewReader(r, size, ErrNotFound)
+	if err != nil {
+		return nil, errors.New("skin: " + err.Error())
+	}
+	return newArchive(z)
+}
+
+func newArchive(z *ziparchive.Archive) (*Archive, error) {
+	a := &Archive{z: z}
+	data, err := a.ReadFile("skin.ini")
+	switch {
+	case err == ErrNotFound:
+		a.Skin = New()
+	case err != nil:
+		return nil, errors.New("skin: skin.ini: " + err.Error())
+	default:
+		if a.Skin, err = Parse(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// Close closes an archive opened with Open
+func (a *Archive) Close() error {
+	return a.z.Close()
+}
+
+// Files returns the names of the files in the archive
+func (a *Archive) Files() []string {
+	return a.z.Files()
+}
+
+// Open opens a file in the archive. Names are matched the way osu! does, ignoring case and the direction of slashes
+func (a *Archive) Open(name string) (io.ReadCloser, error) {
+	return a.z.Open(name)
+}
+
+// ReadFile returns the contents of a file in the archive
+func (a *Archive) ReadFile(name string) ([]byte, error) {
+	return a.z.ReadFile(name)
+}
+
+// Element finds the image of a skin element by name, e.g. "hitcircle" or "Mania/key1", with or without an extension.
+// When hd is set the @2x image is preferred, falling back to the standard image, and otherwise the other way around.
+// ErrNotFound means the skin doesn't have the element and osu! would use its default skin's
+func (a *Archive) Element(name string, hd bool) (Element, error) {
+	base := name
+	for _, ext := range imageExtensions {
+		if strings.EqualFold(path.Ext(name), ext) {
+			base = strings.TrimSuffix(name, path.Ext(name))
+		}
+	}
+	var candidates []Element
+	for _, ext := range imageExtensions {
+		sd, hd2x := Element{File: base + ext}, Element{File: base + "@2x" + ext, HD: true}
+		if hd {
+			candidates = append(candidates, hd2x, sd)
+		} else {
+			candidates = append(candidates, sd, hd2x)
+		}
+	}
+	for _, e := range candidates {
+		if file, ok := a.z.Lookup(e.File); ok {
+			e.File = file
+			return e, nil
+		}
+	}
+	return Element{}, ErrNotFound
+}
+
+// ReadElement returns the image of a skin element, see Element
+func (a *Archive) ReadElement(name string, hd bool) ([]byte, Element, error) {
+	e, err := a.Element(name, hd)
+	if err != nil {
+		return nil, e, err
+	}
+	data, err := a.ReadFile(e.File)
+	return data, e, err
+}
+
+// Animation finds the frames of an animated element, named like "hit300-0", "hit300-1" and so on.
+// An element without frames is returned as a single frame, and each frame falls back from @2x separately
+func (a *Archive) Animation(name string, hd bool) ([]Element, error) {
+	var frames []Element
+	for i := 0; ; i++ {
+		e, err := a.Element(name+"-"+strconv.Itoa(i), hd)
+		if err != nil {
+			break
+		}
+		frames = append(frames, e)
+	}
+	if len(frames) > 0 {
+		return frames, nil
+	}
+	e, err := a.Element(name, hd)
+	if err != nil {
+		return nil, err
+	}
+	return []Element{e}, nil
+}
+
+// ManiaElement finds the image of a mania image key for a key count, such as "KeyImage0D" or "StageHint", see Mania.Image
+func (a *Archive) ManiaElement(keys int, key string, hd bool) (Element, error) {
+	name := a.Skin.ManiaKeys(keys).Image(key)
+	if name == "" {
+		return Element{}, ErrNotFound
+	}
+	return a.Element(name, hd)
+}
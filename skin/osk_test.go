@@ -0,0 +1,157 @@
+package skin
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testArchive writes an archive holding the given files, each containing its own name unless files maps it to contents
+func testArchive(t *testing.T, names []string, files map[string][]byte) *Archive {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, ok := files[name]
+		if !ok {
+			data = []byte(name)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	a, err := NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestArchive(t *testing.T) {
+	ini, err := os.ReadFile(filepath.Join("testdata", "skin.ini"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := testArchive(t, []string{
+		"Skin.ini",
+		"hitcircle.png",
+		"hitcircle@2x.png",
+		"cursor.png",
+		"approachcircle@2x.png",
+		"hit300-0@2x.png",
+		"hit300-1.png",
+		"hit300-3.png",
+		"Mania/K0.png",
+		"mania-key2@2x.png",
+		"default-1@2x.jpg",
+	}, map[string][]byte{"Skin.ini": ini})
+	if a.Skin.General.Name != "Test Skin" {
+		t.Errorf("got skin %+v", a.Skin.General)
+	}
+
+	tests := []struct {
+		name string
+		hd   bool
+		want Element
+	}{
+		{"hitcircle", true, Element{"hitcircle@2x.png", true}},
+		{"HitCircle.png", false, Element{"hitcircle.png", false}},
+		// each preference falls back to the other
+		{"cursor", true, Element{"cursor.png", false}},
+		{"approachcircle", false, Element{"approachcircle@2x.png", true}},
+		{"default-1.png", false, Element{"default-1@2x.jpg", true}},
+		{`mania\k0`, false, Element{"Mania/K0.png", false}},
+	}
+	for _, tt := range tests {
+		got, err := a.Element(tt.name, tt.hd)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: got %+v, %v, want %+v", tt.name, got, err, tt.want)
+		}
+	}
+	if _, err := a.Element("missing", true); err != ErrNotFound {
+		t.Errorf("got %v for a missing element", err)
+	}
+	data, e, err := a.ReadElement("cursor", false)
+	if err != nil || string(data) != "cursor.png" || e.File != "cursor.png" {
+		t.Errorf("ReadElement: got %q, %+v, %v", data, e, err)
+	}
+
+	// frames stop at the first missing one
+	frames, err := a.Animation("hit300", true)
+	if err != nil || len(frames) != 2 || frames[0] != (Element{"hit300-0@2x.png", true}) || frames[1] != (Element{"hit300-1.png", false}) {
+		t.Errorf("got frames %+v, %v", frames, err)
+	}
+	if frames, err := a.Animation("cursor", true); err != nil || len(frames) != 1 {
+		t.Errorf("got frames %+v, %v", frames, err)
+	}
+	if _, err := a.Animation("missing", true); err != ErrNotFound {
+		t.Errorf("got %v for a missing animation", err)
+	}
+
+	maniaTests := []struct {
+		keys int
+		key  string
+		want Element
+		err  error
+	}{
+		// the skin sets 4K's first key and leaves the rest to the default names
+		{4, "KeyImage0", Element{"Mania/K0.png", false}, nil},
+		{4, "KeyImage1", Element{"mania-key2@2x.png", true}, nil},
+		{4, "KeyImage0D", Element{}, ErrNotFound},
+		{7, "KeyImage0", Element{}, ErrNotFound},
+		{4, "KeyImage4", Element{}, ErrNotFound},
+	}
+	for _, tt := range maniaTests {
+		got, err := a.ManiaElement(tt.keys, tt.key, false)
+		if got != tt.want || err != tt.err {
+			t.Errorf("%dK %s: got %+v, %v, want %+v, %v", tt.keys, tt.key, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestArchiveWithoutSkinINI(t *testing.T) {
+	a := testArchive(t, []string{"hitcircle.png"}, nil)
+	if a.Skin.VersionNumber() != LatestVersion || a.Skin.ManiaKeys(4).NoteBodyStyle != 2 {
+		t.Errorf("got %+v", a.Skin.General)
+	}
+	if files := a.Files(); len(files) != 1 || files[0] != "hitcircle.png" {
+		t.Errorf("got files %q", files)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("skin.ini")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("[General]\nName: Opened\nVersion: latest\n"))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "skin.osk")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	a, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	if a.Skin.General.Name != "Opened" || a.Skin.VersionNumber() != LatestVersion {
+		t.Errorf("got %+v", a.Skin.General)
+	}
+	if _, err := Open(filepath.Join(t.TempDir(), "missing.osk")); err == nil {
+		t.Error("no error opening a missing file")
+	}
+}
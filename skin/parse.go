@@ -0,0 +1,320 @@
+package skin
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseFile parses the skin.ini file at path
+func ParseFile(path string) (*Skin, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.New("skin: " + err.Error())
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse parses a skin.ini file. A skin.ini without a version is version 1.0.
+// Like osu!, values that can't be read are ignored and keep their defaults, as are unknown sections and keys
+func Parse(r io.Reader) (*Skin, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.New("skin: " + err.Error())
+	}
+	s := New()
+	s.General.Version = "1.0"
+	combo := make(map[int]Colour)
+	fruit, afterImage := false, false
+	var mania *Mania
+	section := ""
+	for i, line := range strings.Split(string(data), "\n") {
+		if i == 0 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if c := strings.Index(line, "//"); c >= 0 {
+			line = line[:c]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = line[1 : len(line)-1]
+			mania = nil
+			continue
+		}
+		sep := strings.IndexByte(line, ':')
+		if sep < 0 {
+			continue
+		}
+		key, value := strings.TrimSpace(line[:sep]), strings.TrimSpace(line[sep+1:])
+		switch {
+		case section == "Colours" && strings.HasPrefix(key, "Combo"):
+			n, err := strconv.Atoi(key[len("Combo"):])
+			c, cerr := parseColour(value)
+			if err == nil && cerr == nil && n >= 1 && n <= 8 {
+				combo[n] = c
+			}
+			continue
+		case section == "CatchTheBeat" && key == "HyperDashFruit":
+			fruit = true
+		case section == "CatchTheBeat" && key == "HyperDashAfterImage":
+			afterImage = true
+		case section == "Mania" && key == "Keys":
+			keys, err := strconv.Atoi(value)
+			if err != nil || keys < 1 {
+				continue
+			}
+			mania = NewMania(keys, s.VersionNumber())
+			s.Mania = append(s.Mania, mania)
+			continue
+		case section == "Mania":
+			if mania != nil {
+				mania.set(key, value)
+			}
+			continue
+		}
+		if f, ok := findField(s.fields(section), key); ok {
+			f.set(value)
+		}
+	}
+	if len(combo) > 0 {
+		numbers := make([]int, 0, len(combo))
+		for n := range combo {
+			numbers = append(numbers, n)
+		}
+		sort.Ints(numbers)
+		s.Colours.Combo = s.Colours.Combo[:0]
+		for _, n := range numbers {
+			s.Colours.Combo = append(s.Colours.Combo, combo[n])
+		}
+	}
+	if !fruit {
+		s.CatchTheBeat.HyperDashFruit = s.CatchTheBeat.HyperDash
+	}
+	if !afterImage {
+		s.CatchTheBeat.HyperDashAfterImage = s.CatchTheBeat.HyperDash
+	}
+	return s, nil
+}
+
+// field binds a key of a section to the struct field holding its value
+type field struct {
+	key string
+	ptr interface{}
+}
+
+func findField(fields []field, key string) (field, bool) {
+	for _, f := range fields {
+		if f.key == key {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// fields returns the known keys of a section other than [Mania]
+func (s *Skin) fields(section string) []field {
+	switch section {
+	case "General":
+		g := &s.General
+		return []field{
+			{"Name", &g.Name},
+			{"Author", &g.Author},
+			{"Version", &g.Version},
+			{"AnimationFramerate", &g.AnimationFramerate},
+			{"AllowSliderBallTint", &g.AllowSliderBallTint},
+			{"ComboBurstRandom", &g.ComboBurstRandom},
+			{"CursorCentre", &g.CursorCentre},
+			{"CursorExpand", &g.CursorExpand},
+			{"CursorRotate", &g.CursorRotate},
+			{"CursorTrailRotate", &g.CursorTrailRotate},
+			{"CustomComboBurstSounds", &g.CustomComboBurstSounds},
+			{"HitCircleOverlayAboveNumber", &g.HitCircleOverlayAboveNumber},
+			// misspelled by older skins
+			{"HitCircleOverlayAboveNumer", &g.HitCircleOverlayAboveNumber},
+			{"LayeredHitSounds", &g.LayeredHitSounds},
+			{"SliderBallFlip", &g.SliderBallFlip},
+			{"SpinnerFadePlayfield", &g.SpinnerFadePlayfield},
+			{"SpinnerFrequencyModulate", &g.SpinnerFrequencyModulate},
+			{"SpinnerNoBlink", &g.SpinnerNoBlink},
+		}
+	case "Colours":
+		c := &s.Colours
+		return []field{
+			{"InputOverlayText", &c.InputOverlayText},
+			{"MenuGlow", &c.MenuGlow},
+			{"SliderBall", &c.SliderBall},
+			{"SliderBorder", &c.SliderBorder},
+			{"SliderTrackOverride", &c.SliderTrackOverride},
+			{"SongSelectActiveText", &c.SongSelectActiveText},
+			{"SongSelectInactiveText", &c.SongSelectInactiveText},
+			{"SpinnerBackground", &c.SpinnerBackground},
+			{"StarBreakAdditive", &c.StarBreakAdditive},
+		}
+	case "Fonts":
+		f := &s.Fonts
+		return []field{
+			{"HitCirclePrefix", &f.HitCirclePrefix},
+			{"HitCircleOverlap", &f.HitCircleOverlap},
+			{"ScorePrefix", &f.ScorePrefix},
+			{"ScoreOverlap", &f.ScoreOverlap},
+			{"ComboPrefix", &f.ComboPrefix},
+			{"ComboOverlap", &f.ComboOverlap},
+		}
+	case "CatchTheBeat":
+		c := &s.CatchTheBeat
+		return []field{
+			{"HyperDash", &c.HyperDash},
+			{"HyperDashFruit", &c.HyperDashFruit},
+			{"HyperDashAfterImage", &c.HyperDashAfterImage},
+		}
+	}
+	return nil
+}
+
+// fields returns the known keys of a [Mania] section that aren't numbered by column
+func (m *Mania) fields() []field {
+	return []field{
+		{"ColumnStart", &m.ColumnStart},
+		{"ColumnRight", &m.ColumnRight},
+		{"ColumnSpacing", &m.ColumnSpacing},
+		{"ColumnWidth", &m.ColumnWidth},
+		{"ColumnLineWidth", &m.ColumnLineWidth},
+		{"BarlineHeight", &m.BarlineHeight},
+		{"LightingNWidth", &m.LightingNWidth},
+		{"LightingLWidth", &m.LightingLWidth},
+		{"WidthForNoteHeightScale", &m.WidthForNoteHeightScale},
+		{"HitPosition", &m.HitPosition},
+		{"LightPosition", &m.LightPosition},
+		{"ScorePosition", &m.ScorePosition},
+		{"ComboPosition", &m.ComboPosition},
+		{"JudgementLine", &m.JudgementLine},
+		{"LightFramePerSecond", &m.LightFramePerSecond},
+		{"SpecialStyle", &m.SpecialStyle},
+		{"ComboBurstStyle", &m.ComboBurstStyle},
+		{"SplitStages", &m.SplitStages},
+		{"StageSeparation", &m.StageSeparation},
+		{"SeparateScore", &m.SeparateScore},
+		{"KeysUnderNotes", &m.KeysUnderNotes},
+		{"UpsideDown", &m.UpsideDown},
+		{"KeyFlipWhenUpsideDown", &m.KeyFlipWhenUpsideDown},
+		{"NoteFlipWhenUpsideDown", &m.NoteFlipWhenUpsideDown},
+		{"NoteBodyStyle", &m.NoteBodyStyle},
+		{"ColourColumnLine", &m.ColourColumnLine},
+		{"ColourBarline", &m.ColourBarline},
+		{"ColourJudgementLine", &m.ColourJudgementLine},
+		{"ColourKeyWarning", &m.ColourKeyWarning},
+		{"ColourHold", &m.ColourHold},
+		{"ColourBreak", &m.ColourBreak},
+	}
+}
+
+func (m *Mania) set(key, value string) {
+	if f, ok := findField(m.fields(), key); ok {
+		f.set(value)
+		return
+	}
+	for _, prefix := range []struct {
+		name    string
+		colours []Colour
+	}{{"ColourLight", m.LightColours}, {"Colour", m.Colours}} {
+		if !strings.HasPrefix(key, prefix.name) {
+			continue
+		}
+		n, err := strconv.Atoi(key[len(prefix.name):])
+		if err != nil {
+			return
+		}
+		if c, err := parseColour(value); err == nil && n >= 1 && n <= len(prefix.colours) {
+			prefix.colours[n-1] = c
+		}
+		return
+	}
+	if m.Image(key) != "" {
+		m.Images[key] = value
+	}
+}
+
+// set parses value into the field, leaving it unchanged when value can't be read
+func (f field) set(value string) {
+	switch p := f.ptr.(type) {
+	case *string:
+		*p = value
+	case *int:
+		if n, err := parseInt(value); err == nil {
+			*p = n
+		}
+	case *float64:
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			*p = n
+		}
+	case *bool:
+		if n, err := parseInt(value); err == nil {
+			*p = n != 0
+		}
+	case *[]int:
+		var list []int
+		for _, s := range strings.Split(value, ",") {
+			if n, err := parseInt(s); err == nil {
+				list = append(list, n)
+			}
+		}
+		*p = list
+	case *[]float64:
+		// per column lists keep their length, filling only the columns given
+		for i, s := range strings.Split(value, ",") {
+			if i >= len(*p) {
+				break
+			}
+			if n, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+				(*p)[i] = n
+			}
+		}
+	case *Colour:
+		if c, err := parseColour(value); err == nil {
+			*p = c
+		}
+	case **Colour:
+		if c, err := parseColour(value); err == nil {
+			*p = &c
+		}
+	}
+}
+
+// parseColour parses "r,g,b" or "r,g,b,a", clamping each component to 0 - 255
+func parseColour(s string) (Colour, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) < 3 {
+		return Colour{}, errors.New("colour needs 3 components")
+	}
+	c := [4]uint8{3: 255}
+	for i := 0; i < len(parts) && i < 4; i++ {
+		n, err := parseInt(parts[i])
+		if err != nil {
+			return Colour{}, err
+		}
+		c[i] = uint8(max(0, min(n, 255)))
+	}
+	return Colour{c[0], c[1], c[2], c[3]}, nil
+}
+
+// parseInt parses an integer, truncating values written with a fractional part
+func parseInt(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	n, err := strconv.Atoi(s)
+	if err == nil {
+		return n, nil
+	}
+	f, ferr := strconv.ParseFloat(s, 64)
+	if ferr != nil {
+		return 0, err
+	}
+	return int(f), nil
+}
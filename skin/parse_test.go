@@ -0,0 +1,121 @@
+package skin
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func parseFixture(t *testing.T, name string) *Skin {
+	t.Helper()
+	s, err := ParseFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestParse(t *testing.T) {
+	s := parseFixture(t, "skin.ini")
+	g := s.General
+	if g.Name != "Test Skin" || g.Author != "mapper" || g.Version != "2.5" || g.AnimationFramerate != 30 {
+		t.Errorf("got %+v", g)
+	}
+	// the misspelled key is read, and unset keys keep the defaults
+	if g.CursorExpand || g.HitCircleOverlayAboveNumber || g.SliderBallFlip || !g.CursorRotate || !g.LayeredHitSounds {
+		t.Errorf("got flags %+v", g)
+	}
+	if !reflect.DeepEqual(g.CustomComboBurstSounds, []int{50, 75, 100}) {
+		t.Errorf("got combo burst sounds %v", g.CustomComboBurstSounds)
+	}
+
+	c := s.Colours
+	// combo colours are ordered by number, skipping unused numbers and ignoring those past 8
+	if !reflect.DeepEqual(c.Combo, []Colour{{4, 5, 6, 128}, {1, 2, 3, 255}}) {
+		t.Errorf("got combo colours %v", c.Combo)
+	}
+	if c.SliderTrackOverride == nil || *c.SliderTrackOverride != (Colour{9, 9, 9, 255}) {
+		t.Errorf("got slider track %v", c.SliderTrackOverride)
+	}
+	// components are clamped, and colours that can't be read keep the default
+	if c.SliderBorder != (Colour{255, 0, 10, 255}) || c.SliderBall != New().Colours.SliderBall {
+		t.Errorf("got slider border %v and ball %v", c.SliderBorder, c.SliderBall)
+	}
+	if s.Fonts.HitCirclePrefix != `Fonts\default` || s.Fonts.HitCircleOverlap != 3 || s.Fonts.ScorePrefix != "score" {
+		t.Errorf("got %+v", s.Fonts)
+	}
+	// the fruit colour follows HyperDash when unset
+	if ctb := s.CatchTheBeat; ctb.HyperDash != (Colour{0, 255, 0, 255}) || ctb.HyperDashFruit != ctb.HyperDash || ctb.HyperDashAfterImage != (Colour{0, 0, 255, 255}) {
+		t.Errorf("got %+v", ctb)
+	}
+
+	// a section with an invalid key count is ignored
+	if len(s.Mania) != 2 {
+		t.Fatalf("got %d mania sections", len(s.Mania))
+	}
+	m := s.ManiaKeys(4)
+	if m != s.Mania[0] {
+		t.Error("ManiaKeys didn't return the skin's section")
+	}
+	// per column lists keep their length
+	if !reflect.DeepEqual(m.ColumnWidth, []float64{40, 41, 30, 30}) || !reflect.DeepEqual(m.ColumnSpacing, []float64{5, 0, 7}) {
+		t.Errorf("got widths %v and spacing %v", m.ColumnWidth, m.ColumnSpacing)
+	}
+	if m.Colours[0] != (Colour{10, 10, 10, 255}) || m.Colours[1] != (Colour{0, 0, 0, 255}) || m.LightColours[1] != (Colour{1, 1, 1, 255}) || len(m.Colours) != 4 {
+		t.Errorf("got colours %v and %v", m.Colours, m.LightColours)
+	}
+	if m.HitPosition != 420 || m.JudgementLine || m.NoteBodyStyle != 2 {
+		t.Errorf("got %+v", m)
+	}
+	// image keys of columns the key count doesn't have are ignored
+	if !reflect.DeepEqual(m.Images, map[string]string{"KeyImage0": `Mania\k0`}) {
+		t.Errorf("got images %v", m.Images)
+	}
+	if s.ManiaKeys(7).NoteBodyStyle != 1 {
+		t.Errorf("got note body style %d for 7K", s.ManiaKeys(7).NoteBodyStyle)
+	}
+}
+
+func TestParseManiaVersion(t *testing.T) {
+	tests := []struct {
+		name          string
+		version       float64
+		noteBodyStyle int
+	}{
+		// a skin.ini without a version is version 1.0
+		{"old.ini", 1, 0},
+		{"v2.4.ini", 2.4, 0},
+		{"skin.ini", 2.5, 2},
+	}
+	for _, tt := range tests {
+		s := parseFixture(t, tt.name)
+		if v := s.VersionNumber(); v != tt.version {
+			t.Errorf("%s: got version %v, want %v", tt.name, v, tt.version)
+		}
+		// both the skin's sections and the defaults of key counts it doesn't configure follow its version
+		for _, keys := range []int{4, 5} {
+			if m := s.ManiaKeys(keys); m.NoteBodyStyle != tt.noteBodyStyle || m.Keys != keys {
+				t.Errorf("%s: got note body style %d for %dK, want %d", tt.name, m.NoteBodyStyle, keys, tt.noteBodyStyle)
+			}
+		}
+	}
+	if m := parseFixture(t, "old.ini").ManiaKeys(4); m.ColumnStart != 200 || m.HitPosition != 402 {
+		t.Errorf("got %+v", m)
+	}
+}
+
+func TestParseEmpty(t *testing.T) {
+	s, err := Parse(strings.NewReader("\ufeff"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := New()
+	want.General.Version = "1.0"
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("got %+v", s)
+	}
+	if _, err := ParseFile(filepath.Join("testdata", "missing.ini")); err == nil {
+		t.Error("no error parsing a missing file")
+	}
+}
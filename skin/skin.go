@@ -0,0 +1,325 @@
+// Package skin reads osu! skins: skin.ini configuration files and .osk skin archives
+package skin
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LatestVersion is the skin version osu! uses for skins without a skin.ini
+const LatestVersion = 2.7
+
+// Skin holds the configuration of a skin.ini file
+type Skin struct {
+	General      General
+	Colours      Colours
+	Fonts        Fonts
+	CatchTheBeat CatchTheBeat
+	// Mania holds the configuration of each key count the skin has a [Mania] section for
+	Mania []*Mania
+}
+
+// General holds the [General] section
+type General struct {
+	Name   string
+	Author string
+	// Version is the skin version, e.g. "2.5" or "latest", which decides how osu! draws some elements
+	Version string
+	// AnimationFramerate is the frame rate of animated elements, or -1 to play each animation in a fixed time
+	AnimationFramerate          int
+	AllowSliderBallTint         bool
+	ComboBurstRandom            bool
+	CursorCentre                bool
+	CursorExpand                bool
+	CursorRotate                bool
+	CursorTrailRotate           bool
+	CustomComboBurstSounds      []int
+	HitCircleOverlayAboveNumber bool
+	LayeredHitSounds            bool
+	SliderBallFlip              bool
+	SpinnerFadePlayfield        bool
+	SpinnerFrequencyModulate    bool
+	SpinnerNoBlink              bool
+}
+
+// Colour is an RGBA colour
+type Colour struct {
+	R, G, B, A uint8
+}
+
+// String returns the colour as skin.ini writes it, "r,g,b" or "r,g,b,a" when it isn't opaque
+func (c Colour) String() string {
+	s := strconv.Itoa(int(c.R)) + "," + strconv.Itoa(int(c.G)) + "," + strconv.Itoa(int(c.B))
+	if c.A != 255 {
+		s += "," + strconv.Itoa(int(c.A))
+	}
+	return s
+}
+
+// Colours holds the [Colours] section
+type Colours struct {
+	// Combo holds the combo colours Combo1 - Combo8 in order
+	Combo                  []Colour
+	InputOverlayText       Colour
+	MenuGlow               Colour
+	SliderBall             Colour
+	SliderBorder           Colour
+	SliderTrackOverride    *Colour
+	SongSelectActiveText   Colour
+	SongSelectInactiveText Colour
+	SpinnerBackground      Colour
+	StarBreakAdditive      Colour
+}
+
+// Fonts holds the [Fonts] section. Prefixes are the start of the file names of digit images, e.g. "default" for default-0.png
+type Fonts struct {
+	HitCirclePrefix  string
+	HitCircleOverlap int
+	ScorePrefix      string
+	ScoreOverlap     int
+	ComboPrefix      string
+	ComboOverlap     int
+}
+
+// CatchTheBeat holds the [CatchTheBeat] section
+type CatchTheBeat struct {
+	HyperDash Colour
+	// HyperDashFruit and HyperDashAfterImage are the same as HyperDash unless the skin sets them
+	HyperDashFruit      Colour
+	HyperDashAfterImage Colour
+}
+
+// Mania holds a [Mania] section, which configures one key count. Per column values are indexed from 0
+type Mania struct {
+	Keys        int
+	ColumnStart float64
+	ColumnRight float64
+	// ColumnSpacing holds the gaps between columns, one fewer than the key count
+	ColumnSpacing []float64
+	ColumnWidth   []float64
+	// ColumnLineWidth holds the widths of the lines around columns, one more than the key count
+	ColumnLineWidth []float64
+	BarlineHeight   float64
+	// LightingNWidth and LightingLWidth are 0 for columns the skin leaves to osu!
+	LightingNWidth []float64
+	LightingLWidth []float64
+	// WidthForNoteHeightScale is 0 when notes are scaled by the narrowest column
+	WidthForNoteHeightScale float64
+	HitPosition             int
+	LightPosition           int
+	ScorePosition           int
+	ComboPosition           int
+	JudgementLine           bool
+	LightFramePerSecond     int
+	// SpecialStyle is 0 for none, 1 for a special left column and 2 for a special right column
+	SpecialStyle    int
+	ComboBurstStyle int
+	SplitStages     bool
+	StageSeparation float64
+	SeparateScore   bool
+	KeysUnderNotes  bool
+	UpsideDown      bool
+	// KeyFlipWhenUpsideDown and NoteFlipWhenUpsideDown flip images vertically when UpsideDown is set
+	KeyFlipWhenUpsideDown  bool
+	NoteFlipWhenUpsideDown bool
+	// NoteBodyStyle is how hold note bodies fill their length: 0 stretches, 1 repeats from the top and 2 repeats from the bottom
+	NoteBodyStyle int
+	// Colours and LightColours hold the ColourN and ColourLightN keys, which skin.ini numbers from 1
+	Colours             []Colour
+	LightColours        []Colour
+	ColourColumnLine    Colour
+	ColourBarline       Colour
+	ColourJudgementLine Colour
+	ColourKeyWarning    Colour
+	ColourHold          Colour
+	ColourBreak         Colour
+	// Images holds the image keys the skin sets, such as "KeyImage0D", "NoteImage1H" or "StageHint", see Image
+	Images map[string]string
+}
+
+// New returns the configuration osu! uses for a skin without a skin.ini
+func New() *Skin {
+	white := Colour{255, 255, 255, 255}
+	hyperDash := Colour{255, 0, 0, 255}
+	return &Skin{
+		General: General{
+			Version:                     "latest",
+			AnimationFramerate:          -1,
+			CursorCentre:                true,
+			CursorExpand:                true,
+			CursorRotate:                true,
+			CursorTrailRotate:           true,
+			HitCircleOverlayAboveNumber: true,
+			LayeredHitSounds:            true,
+			SliderBallFlip:              true,
+			SpinnerFrequencyModulate:    true,
+		},
+		Colours: Colours{
+			Combo:                  DefaultComboColours(),
+			InputOverlayText:       Colour{0, 0, 0, 255},
+			MenuGlow:               Colour{0, 78, 155, 255},
+			SliderBall:             Colour{2, 170, 255, 255},
+			SliderBorder:           white,
+			SongSelectActiveText:   Colour{0, 0, 0, 255},
+			SongSelectInactiveText: white,
+			SpinnerBackground:      Colour{100, 100, 100, 255},
+			StarBreakAdditive:      Colour{255, 182, 193, 255},
+		},
+		Fonts: Fonts{
+			HitCirclePrefix:  "default",
+			HitCircleOverlap: -2,
+			ScorePrefix:      "score",
+			ComboPrefix:      "score",
+		},
+		CatchTheBeat: CatchTheBeat{
+			HyperDash:           hyperDash,
+			HyperDashFruit:      hyperDash,
+			HyperDashAfterImage: hyperDash,
+		},
+	}
+}
+
+// DefaultComboColours returns the combo colours of skins that don't set any
+func DefaultComboColours() []Colour {
+	return []Colour{{255, 192, 0, 255}, {0, 202, 0, 255}, {18, 124, 255, 255}, {242, 24, 57, 255}}
+}
+
+// VersionNumber returns the skin version as a number, LatestVersion for "latest" and 1.0 when it can't be read
+func (s *Skin) VersionNumber() float64 {
+	if strings.EqualFold(s.General.Version, "latest") {
+		return LatestVersion
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(s.General.Version), 64)
+	if err != nil {
+		return 1
+	}
+	return v
+}
+
+// ManiaKeys returns the configuration of a key count, or the default configuration when the skin doesn't have one
+func (s *Skin) ManiaKeys(keys int) *Mania {
+	for _, m := range s.Mania {
+		if m.Keys == keys {
+			return m
+		}
+	}
+	return NewMania(keys, s.VersionNumber())
+}
+
+// NewMania returns the default configuration of a key count for a skin version.
+// Hold note bodies stretch in skins older than 2.5 and repeat from the bottom since
+func NewMania(keys int, version float64) *Mania {
+	m := &Mania{
+		Keys:                   keys,
+		ColumnStart:            136,
+		ColumnRight:            19,
+		ColumnSpacing:          repeat(0, keys-1),
+		ColumnWidth:            repeat(30, keys),
+		ColumnLineWidth:        repeat(2, keys+1),
+		BarlineHeight:          1.2,
+		LightingNWidth:         repeat(0, keys),
+		LightingLWidth:         repeat(0, keys),
+		HitPosition:            402,
+		LightPosition:          413,
+		ScorePosition:          300,
+		ComboPosition:          111,
+		JudgementLine:          true,
+		LightFramePerSecond:    60,
+		ComboBurstStyle:        1,
+		StageSeparation:        40,
+		SeparateScore:          true,
+		KeyFlipWhenUpsideDown:  true,
+		NoteFlipWhenUpsideDown: true,
+		NoteBodyStyle:          2,
+		ColourColumnLine:       Colour{255, 255, 255, 255},
+		ColourBarline:          Colour{255, 255, 255, 255},
+		ColourJudgementLine:    Colour{255, 255, 255, 255},
+		ColourKeyWarning:       Colour{0, 0, 0, 255},
+		ColourHold:             Colour{255, 191, 51, 255},
+		ColourBreak:            Colour{255, 0, 0, 255},
+		Images:                 make(map[string]string),
+	}
+	if version < 2.5 {
+		m.NoteBodyStyle = 0
+	}
+	for i := 0; i < keys; i++ {
+		m.Colours = append(m.Colours, Colour{0, 0, 0, 255})
+		m.LightColours = append(m.LightColours, Colour{55, 255, 255, 255})
+	}
+	return m
+}
+
+func repeat(v float64, n int) []float64 {
+	if n < 0 {
+		n = 0
+	}
+	s := make([]float64, n)
+	for i := range s {
+		s[i] = v
+	}
+	return s
+}
+
+// ColumnType returns the suffix of the default images of a column: "1" and "2" alternate from the edges of the stage
+// towards its centre, and "S" is the centre column of odd key counts or the column chosen by SpecialStyle
+func (m *Mania) ColumnType(column int) string {
+	switch {
+	case m.Keys%2 == 1 && column == m.Keys/2,
+		m.Keys%2 == 0 && m.SpecialStyle == 1 && column == 0,
+		m.Keys%2 == 0 && m.SpecialStyle == 2 && column == m.Keys-1:
+		return "S"
+	case min(column, m.Keys-1-column)%2 == 0:
+		return "1"
+	}
+	return "2"
+}
+
+var (
+	columnImageRegex = regexp.MustCompile(`^(KeyImage|NoteImage)([0-9]+)(D|H|L|T)?$`)
+	maniaImages      = map[string]string{
+		"StageLeft":    "mania-stage-left",
+		"StageRight":   "mania-stage-right",
+		"StageBottom":  "mania-stage-bottom",
+		"StageHint":    "mania-stage-hint",
+		"StageLight":   "mania-stage-light",
+		"LightingN":    "lightingN",
+		"LightingL":    "lightingL",
+		"WarningArrow": "mania-warningarrow",
+		"Hit0":         "mania-hit0",
+		"Hit50":        "mania-hit50",
+		"Hit100":       "mania-hit100",
+		"Hit200":       "mania-hit200",
+		"Hit300":       "mania-hit300",
+		"Hit300g":      "mania-hit300g",
+	}
+)
+
+// Image returns the element name of a mania image key, such as "KeyImage0D", "NoteImage1H" or "StageHint":
+// the file the skin sets, or the name osu! looks for otherwise. Names are without an extension
+func (m *Mania) Image(key string) string {
+	if name, ok := m.Images[key]; ok && name != "" {
+		return name
+	}
+	if name, ok := maniaImages[key]; ok {
+		return name
+	}
+	match := columnImageRegex.FindStringSubmatch(key)
+	if match == nil {
+		return ""
+	}
+	column, _ := strconv.Atoi(match[2])
+	if column >= m.Keys {
+		return ""
+	}
+	if match[1] == "KeyImage" {
+		if match[3] != "" && match[3] != "D" {
+			return ""
+		}
+		return "mania-key" + m.ColumnType(column) + match[3]
+	}
+	if match[3] == "D" {
+		return ""
+	}
+	return "mania-note" + m.ColumnType(column) + match[3]
+}
@@ -0,0 +1,103 @@
+package skin
+
+import "testing"
+
+func TestVersionNumber(t *testing.T) {
+	tests := []struct {
+		version string
+		want    float64
+	}{
+		{"latest", LatestVersion},
+		{"Latest", LatestVersion},
+		{"2.5", 2.5},
+		{" 1 ", 1},
+		{"", 1},
+		{"new", 1},
+	}
+	for _, tt := range tests {
+		s := &Skin{General: General{Version: tt.version}}
+		if got := s.VersionNumber(); got != tt.want {
+			t.Errorf("%q: got %v, want %v", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestNewMania(t *testing.T) {
+	m := NewMania(7, 2.5)
+	if m.Keys != 7 || len(m.ColumnWidth) != 7 || len(m.ColumnSpacing) != 6 || len(m.ColumnLineWidth) != 8 || len(m.Colours) != 7 || len(m.LightColours) != 7 {
+		t.Errorf("got %+v", m)
+	}
+	if m.NoteBodyStyle != 2 || NewMania(7, 2.4).NoteBodyStyle != 0 {
+		t.Error("got the wrong note body style")
+	}
+	if m := NewMania(0, 2.5); len(m.ColumnSpacing) != 0 || len(m.ColumnLineWidth) != 1 {
+		t.Errorf("got %+v for no keys", m)
+	}
+	// a skin without a skin.ini uses the latest defaults
+	if New().ManiaKeys(4).NoteBodyStyle != 2 {
+		t.Error("got the wrong note body style without a skin.ini")
+	}
+}
+
+func TestColumnType(t *testing.T) {
+	tests := []struct {
+		keys, specialStyle int
+		want               string
+	}{
+		{4, 0, "1221"},
+		{4, 1, "S221"},
+		{4, 2, "122S"},
+		{5, 0, "12S21"},
+		{7, 0, "121S121"},
+		{7, 1, "121S121"},
+		{8, 0, "12122121"},
+	}
+	for _, tt := range tests {
+		m := NewMania(tt.keys, LatestVersion)
+		m.SpecialStyle = tt.specialStyle
+		var got string
+		for i := 0; i < tt.keys; i++ {
+			got += m.ColumnType(i)
+		}
+		if got != tt.want {
+			t.Errorf("%dK with style %d: got %s, want %s", tt.keys, tt.specialStyle, got, tt.want)
+		}
+	}
+}
+
+func TestManiaImage(t *testing.T) {
+	m := NewMania(4, LatestVersion)
+	m.Images["KeyImage0"] = `Mania\k0`
+	m.Images["StageHint"] = ""
+	tests := []struct {
+		key, want string
+	}{
+		{"KeyImage0", `Mania\k0`},
+		{"KeyImage0D", "mania-key1D"},
+		{"KeyImage1", "mania-key2"},
+		{"NoteImage3T", "mania-note1T"},
+		{"NoteImage2H", "mania-note2H"},
+		{"NoteImage1L", "mania-note2L"},
+		// an empty value uses the default
+		{"StageHint", "mania-stage-hint"},
+		{"Hit300g", "mania-hit300g"},
+		{"KeyImage4", ""},
+		{"KeyImage0H", ""},
+		{"NoteImage0D", ""},
+		{"Unknown", ""},
+	}
+	for _, tt := range tests {
+		if got := m.Image(tt.key); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestColourString(t *testing.T) {
+	if s := (Colour{1, 2, 3, 255}).String(); s != "1,2,3" {
+		t.Errorf("got %s", s)
+	}
+	if s := (Colour{1, 2, 3, 128}).String(); s != "1,2,3,128" {
+		t.Errorf("got %s", s)
+	}
+}
@@ -0,0 +1,179 @@
+package storyboard
+
+import "math"
+
+// Easing is the curve a command follows between its start and end values
+type Easing int
+
+// All easings. EasingOut and EasingIn are the quadratic easings older storyboards use
+const (
+	EasingLinear Easing = iota
+	EasingOut
+	EasingIn
+	EasingQuadIn
+	EasingQuadOut
+	EasingQuadInOut
+	EasingCubicIn
+	EasingCubicOut
+	EasingCubicInOut
+	EasingQuartIn
+	EasingQuartOut
+	EasingQuartInOut
+	EasingQuintIn
+	EasingQuintOut
+	EasingQuintInOut
+	EasingSineIn
+	EasingSineOut
+	EasingSineInOut
+	EasingExpoIn
+	EasingExpoOut
+	EasingExpoInOut
+	EasingCircIn
+	EasingCircOut
+	EasingCircInOut
+	EasingElasticIn
+	EasingElasticOut
+	EasingElasticHalfOut
+	EasingElasticQuarterOut
+	EasingElasticInOut
+	EasingBackIn
+	EasingBackOut
+	EasingBackInOut
+	EasingBounceIn
+	EasingBounceOut
+	EasingBounceInOut
+)
+
+const (
+	elasticConst  = 2 * math.Pi / 0.3
+	elasticConst2 = 0.3 / 4
+	backConst     = 1.70158
+	backConst2    = backConst * 1.525
+)
+
+// Apply maps the progress t, from 0 to 1, through the easing. Unknown easings are linear
+func (e Easing) Apply(t float64) float64 {
+	switch e {
+	case EasingIn, EasingQuadIn:
+		return t * t
+	case EasingOut, EasingQuadOut:
+		return t * (2 - t)
+	case EasingQuadInOut:
+		if t < 0.5 {
+			return 2 * t * t
+		}
+		return 1 - 2*(t-1)*(t-1)
+	case EasingCubicIn:
+		return t * t * t
+	case EasingCubicOut:
+		return math.Pow(t-1, 3) + 1
+	case EasingCubicInOut:
+		if t < 0.5 {
+			return 4 * t * t * t
+		}
+		return 4*math.Pow(t-1, 3) + 1
+	case EasingQuartIn:
+		return math.Pow(t, 4)
+	case EasingQuartOut:
+		return 1 - math.Pow(t-1, 4)
+	case EasingQuartInOut:
+		if t < 0.5 {
+			return 8 * math.Pow(t, 4)
+		}
+		return 1 - 8*math.Pow(t-1, 4)
+	case EasingQuintIn:
+		return math.Pow(t, 5)
+	case EasingQuintOut:
+		return math.Pow(t-1, 5) + 1
+	case EasingQuintInOut:
+		if t < 0.5 {
+			return 16 * math.Pow(t, 5)
+		}
+		return 16*math.Pow(t-1, 5) + 1
+	case EasingSineIn:
+		return 1 - math.Cos(t*math.Pi/2)
+	case EasingSineOut:
+		return math.Sin(t * math.Pi / 2)
+	case EasingSineInOut:
+		return 0.5 - 0.5*math.Cos(math.Pi*t)
+	case EasingExpoIn:
+		if t == 0 {
+			return 0
+		}
+		return math.Pow(2, 10*(t-1))
+	case EasingExpoOut:
+		if t == 1 {
+			return 1
+		}
+		return 1 - math.Pow(2, -10*t)
+	case EasingExpoInOut:
+		switch {
+		case t == 0, t == 1:
+			return t
+		case t < 0.5:
+			return 0.5 * math.Pow(2, 20*t-10)
+		}
+		return 1 - 0.5*math.Pow(2, -20*t+10)
+	case EasingCircIn:
+		return 1 - math.Sqrt(1-t*t)
+	case EasingCircOut:
+		return math.Sqrt(1 - (t-1)*(t-1))
+	case EasingCircInOut:
+		if t < 0.5 {
+			return 0.5 - 0.5*math.Sqrt(1-4*t*t)
+		}
+		return 0.5 + 0.5*math.Sqrt(1-4*(t-1)*(t-1))
+	case EasingElasticIn:
+		return -math.Pow(2, -10+10*t) * math.Sin((1-elasticConst2-t)*elasticConst)
+	case EasingElasticOut:
+		return math.Pow(2, -10*t)*math.Sin((t-elasticConst2)*elasticConst) + 1
+	case EasingElasticHalfOut:
+		return math.Pow(2, -10*t)*math.Sin((0.5*t-elasticConst2)*elasticConst) + 1
+	case EasingElasticQuarterOut:
+		return math.Pow(2, -10*t)*math.Sin((0.25*t-elasticConst2)*elasticConst) + 1
+	case EasingElasticInOut:
+		if t < 0.5 {
+			t *= 2
+			return -0.5 * math.Pow(2, -10+10*t) * math.Sin((1-elasticConst2*1.5-t)*elasticConst/1.5)
+		}
+		t = t*2 - 1
+		return 0.5*math.Pow(2, -10*t)*math.Sin((t-elasticConst2*1.5)*elasticConst/1.5) + 1
+	case EasingBackIn:
+		return t * t * ((backConst+1)*t - backConst)
+	case EasingBackOut:
+		t--
+		return t*t*((backConst+1)*t+backConst) + 1
+	case EasingBackInOut:
+		if t < 0.5 {
+			t *= 2
+			return 0.5 * t * t * ((backConst2+1)*t - backConst2)
+		}
+		t = t*2 - 2
+		return 0.5 * (t*t*((backConst2+1)*t+backConst2) + 2)
+	case EasingBounceIn:
+		return 1 - bounceOut(1-t)
+	case EasingBounceOut:
+		return bounceOut(t)
+	case EasingBounceInOut:
+		if t < 0.5 {
+			return 0.5 - 0.5*bounceOut(1-2*t)
+		}
+		return 0.5 + 0.5*bounceOut(2*t-1)
+	}
+	return t
+}
+
+func bounceOut(t float64) float64 {
+	switch {
+	case t < 1/2.75:
+		return 7.5625 * t * t
+	case t < 2/2.75:
+		t -= 1.5 / 2.75
+		return 7.5625*t*t + 0.75
+	case t < 2.5/2.75:
+		t -= 2.25 / 2.75
+		return 7.5625*t*t + 0.9375
+	}
+	t -= 2.625 / 2.75
+	return 7.5625*t*t + 0.984375
+}
@@ -0,0 +1,228 @@
+package storyboard
+
+import (
+	"math"
+	"sort"
+)
+
+// Colour is an RGB colour with components from 0 - 255
+type Colour struct {
+	R, G, B float64
+}
+
+// State is the state of a sprite at a point in time
+type State struct {
+	Sprite *Sprite
+	// Visible reports whether the sprite is drawn: it is within its lifetime and not fully transparent
+	Visible bool
+	// X and Y are the position of the sprite's origin in storyboard pixels
+	X, Y float64
+	// ScaleX and ScaleY combine the scale and vector scale commands
+	ScaleX, ScaleY float64
+	// Rotation is in radians, clockwise
+	Rotation float64
+	Colour   Colour
+	// Opacity is from 0 - 1
+	Opacity float64
+	FlipH   bool
+	FlipV   bool
+	// Additive reports whether the sprite is drawn with additive blending
+	Additive bool
+	// Frame is the animation frame shown, see Sprite.FramePath
+	Frame int
+}
+
+// StateAt returns the state of every sprite at time t in milliseconds, in the order they are drawn.
+// Triggers are not evaluated since they depend on gameplay, and sprites on the fail and pass layers are
+// evaluated as if they were shown
+func (sb *Storyboard) StateAt(t float64) []State {
+	states := make([]State, len(sb.Sprites))
+	for i, s := range sb.Sprites {
+		states[i] = s.StateAt(t)
+	}
+	sort.SliceStable(states, func(i, j int) bool { return states[i].Sprite.Layer < states[j].Sprite.Layer })
+	return states
+}
+
+// Lifetime returns the time from the start of the sprite's first command to the end of its last, counting loops.
+// ok is false when the sprite has no commands, in which case it is never shown
+func (s *Sprite) Lifetime() (start, end int, ok bool) {
+	for _, c := range s.Commands {
+		if !ok {
+			start, end, ok = c.StartTime, c.EndTime, true
+		}
+		start = min(start, c.StartTime)
+		end = max(end, c.EndTime)
+	}
+	for _, l := range s.Loops {
+		first, duration, has := l.span()
+		if !has {
+			continue
+		}
+		loopStart, loopEnd := l.StartTime+first, l.StartTime+first+l.count()*duration
+		if !ok {
+			start, end, ok = loopStart, loopEnd, true
+		}
+		start = min(start, loopStart)
+		end = max(end, loopEnd)
+	}
+	return start, end, ok
+}
+
+// StateAt returns the state of the sprite at time t in milliseconds. Before a property's first command
+// the property has that command's start value, and after a command ends it keeps the command's end value
+func (s *Sprite) StateAt(t float64) State {
+	var x, y, scale, vx, vy, rotation, r, g, b, fade track
+	var params []*Command
+	for _, c := range s.timeline(t) {
+		switch c.Type {
+		case CommandMove:
+			x = append(x, trackCommand{c, 0})
+			y = append(y, trackCommand{c, 1})
+		case CommandMoveX:
+			x = append(x, trackCommand{c, 0})
+		case CommandMoveY:
+			y = append(y, trackCommand{c, 0})
+		case CommandScale:
+			scale = append(scale, trackCommand{c, 0})
+		case CommandVector:
+			vx = append(vx, trackCommand{c, 0})
+			vy = append(vy, trackCommand{c, 1})
+		case CommandRotate:
+			rotation = append(rotation, trackCommand{c, 0})
+		case CommandColour:
+			r = append(r, trackCommand{c, 0})
+			g = append(g, trackCommand{c, 1})
+			b = append(b, trackCommand{c, 2})
+		case CommandFade:
+			fade = append(fade, trackCommand{c, 0})
+		case CommandParameter:
+			params = append(params, c)
+		}
+	}
+	st := State{
+		Sprite:   s,
+		X:        x.at(t, s.X),
+		Y:        y.at(t, s.Y),
+		ScaleX:   scale.at(t, 1) * vx.at(t, 1),
+		ScaleY:   scale.at(t, 1) * vy.at(t, 1),
+		Rotation: rotation.at(t, 0),
+		Colour:   Colour{r.at(t, 255), g.at(t, 255), b.at(t, 255)},
+		Opacity:  fade.at(t, 1),
+	}
+	for _, c := range params {
+		if t < float64(c.StartTime) || c.EndTime > c.StartTime && t >= float64(c.EndTime) {
+			continue
+		}
+		switch c.Parameter {
+		case ParameterFlipH:
+			st.FlipH = true
+		case ParameterFlipV:
+			st.FlipV = true
+		case ParameterAdditive:
+			st.Additive = true
+		}
+	}
+	start, end, ok := s.Lifetime()
+	st.Visible = ok && t >= float64(start) && t < float64(end) && st.Opacity > 0
+	if a := s.Animation; a != nil && a.FrameCount > 0 && a.FrameDelay > 0 && t > float64(start) {
+		st.Frame = int((t - float64(start)) / a.FrameDelay)
+		if a.LoopType == LoopOnce {
+			st.Frame = min(st.Frame, a.FrameCount-1)
+		} else {
+			st.Frame %= a.FrameCount
+		}
+	}
+	return st
+}
+
+// timeline returns the commands that can affect the sprite at time t, with absolute times and ordered by start time:
+// the sprite's commands and those of the loop iterations that are playing or last played at t
+func (s *Sprite) timeline(t float64) []*Command {
+	cmds := append([]*Command(nil), s.Commands...)
+	for _, l := range s.Loops {
+		cmds = append(cmds, l.commandsAt(t)...)
+	}
+	sort.SliceStable(cmds, func(i, j int) bool { return cmds[i].StartTime < cmds[j].StartTime })
+	return cmds
+}
+
+// span returns the start of the loop's first command relative to the loop and the duration of an iteration,
+// which lasts from the earliest command start to the latest command end. ok is false when the loop has no commands
+func (l *Loop) span() (first, duration int, ok bool) {
+	if len(l.Commands) == 0 {
+		return 0, 0, false
+	}
+	first, last := l.Commands[0].StartTime, l.Commands[0].EndTime
+	for _, c := range l.Commands[1:] {
+		first = min(first, c.StartTime)
+		last = max(last, c.EndTime)
+	}
+	return first, max(last-first, 0), true
+}
+
+// count returns the number of iterations the loop plays, which is at least one
+func (l *Loop) count() int {
+	return max(l.Count, 1)
+}
+
+// commandsAt returns the commands of the iteration playing at time t and of the one before it with absolute times.
+// Earlier iterations are left out since every property they change is changed again by the previous iteration,
+// which keeps evaluating a loop independent of its count
+func (l *Loop) commandsAt(t float64) []*Command {
+	first, duration, ok := l.span()
+	if !ok {
+		return nil
+	}
+	i := 0
+	if duration > 0 {
+		elapsed := (t - float64(l.StartTime+first)) / float64(duration)
+		i = int(math.Max(0, math.Min(math.Floor(elapsed), float64(l.count()-1))))
+	}
+	var cmds []*Command
+	for it := max(i-1, 0); it <= i; it++ {
+		offset := l.StartTime + it*duration
+		for _, c := range l.Commands {
+			abs := *c
+			abs.StartTime += offset
+			abs.EndTime += offset
+			cmds = append(cmds, &abs)
+		}
+	}
+	return cmds
+}
+
+// track holds the commands that change one value, ordered by start time, with the index of the value in each command
+type track []trackCommand
+
+type trackCommand struct {
+	*Command
+	index int
+}
+
+// at returns the value at time t, using the command that started last. def is used when there are no commands
+func (tr track) at(t float64, def float64) float64 {
+	if len(tr) == 0 {
+		return def
+	}
+	cur := tr[0]
+	if t < float64(cur.StartTime) {
+		return cur.Start[cur.index]
+	}
+	for _, c := range tr[1:] {
+		if float64(c.StartTime) > t {
+			break
+		}
+		cur = c
+	}
+	return cur.valueAt(t, cur.index)
+}
+
+// valueAt interpolates the i'th value of the command at time t
+func (c *Command) valueAt(t float64, i int) float64 {
+	if c.EndTime <= c.StartTime || t >= float64(c.EndTime) {
+		return c.End[i]
+	}
+	progress := (t - float64(c.StartTime)) / float64(c.EndTime-c.StartTime)
+	return c.Start[i] + (c.End[i]-c.Start[i])*c.Easing.Apply(progress)
+}
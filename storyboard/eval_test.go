@@ -0,0 +1,238 @@
+package storyboard
+
+import (
+	"math"
+	"testing"
+)
+
+// testSprite parses the lines of a single sprite
+func testSprite(t *testing.T, lines ...string) *Sprite {
+	t.Helper()
+	sb, err := ParseLines(lines, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sb.Sprites) != 1 {
+		t.Fatalf("got %d sprites", len(sb.Sprites))
+	}
+	return sb.Sprites[0]
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-3
+}
+
+func TestStateAt(t *testing.T) {
+	s := testSprite(t,
+		`Sprite,Foreground,Centre,"a.png",320,240`,
+		` F,0,1000,2000,0,1`,
+		` M,0,1000,2000,0,0,100,200`,
+	)
+	tests := []struct {
+		t       float64
+		opacity float64
+		x, y    float64
+		visible bool
+	}{
+		// before the first command its start values apply
+		{500, 0, 0, 0, false},
+		{1000, 0, 0, 0, false},
+		{1500, 0.5, 50, 100, true},
+		// after the last command its end values stay
+		{2000, 1, 100, 200, false},
+		{2500, 1, 100, 200, false},
+	}
+	for _, tt := range tests {
+		st := s.StateAt(tt.t)
+		if !near(st.Opacity, tt.opacity) || !near(st.X, tt.x) || !near(st.Y, tt.y) || st.Visible != tt.visible {
+			t.Errorf("%v: got %+v", tt.t, st)
+		}
+		if st.Sprite != s || st.ScaleX != 1 || st.ScaleY != 1 || st.Rotation != 0 || st.Colour != (Colour{255, 255, 255}) {
+			t.Errorf("%v: got defaults %+v", tt.t, st)
+		}
+	}
+	if start, end, ok := s.Lifetime(); start != 1000 || end != 2000 || !ok {
+		t.Errorf("got lifetime %d - %d, %v", start, end, ok)
+	}
+
+	// without a move command the sprite stays where it was placed
+	s = testSprite(t,
+		`Sprite,Foreground,Centre,"a.png",320,240`,
+		` F,1,0,1000,0,1`,
+		` S,0,0,1000,1,3`,
+		` V,0,0,1000,2,1,2,1`,
+		` R,0,0,1000,0,3.14`,
+		` C,0,0,1000,0,0,0,255,100,50`,
+		` F,0,800,1000,0.25,0`,
+	)
+	st := s.StateAt(500)
+	if st.X != 320 || st.Y != 240 || !near(st.ScaleX, 4) || !near(st.ScaleY, 2) || !near(st.Rotation, 1.57) || st.Colour != (Colour{127.5, 50, 25}) {
+		t.Errorf("got %+v", st)
+	}
+	// the fade is eased out
+	if !near(st.Opacity, 0.75) || !st.Visible {
+		t.Errorf("got opacity %v", st.Opacity)
+	}
+	// the command that started last applies
+	if st := s.StateAt(900); !near(st.Opacity, 0.125) {
+		t.Errorf("got opacity %v for overlapping commands", st.Opacity)
+	}
+}
+
+func TestStateAtParameters(t *testing.T) {
+	s := testSprite(t,
+		`Sprite,Foreground,Centre,"a.png",0,0`,
+		` F,0,0,3000,1`,
+		` P,0,1000,1500,H`,
+		` P,0,1200,1400,V`,
+		// a parameter without a duration lasts forever
+		` P,0,2000,,A`,
+	)
+	tests := []struct {
+		t                      float64
+		flipH, flipV, additive bool
+	}{
+		{500, false, false, false},
+		{1000, true, false, false},
+		{1300, true, true, false},
+		{1500, false, false, false},
+		{2500, false, false, true},
+		{5000, false, false, true},
+	}
+	for _, tt := range tests {
+		st := s.StateAt(tt.t)
+		if st.FlipH != tt.flipH || st.FlipV != tt.flipV || st.Additive != tt.additive {
+			t.Errorf("%v: got %+v", tt.t, st)
+		}
+	}
+}
+
+func TestStateAtLoop(t *testing.T) {
+	s := testSprite(t,
+		`Sprite,Foreground,Centre,"a.png",0,0`,
+		` L,1000,3`,
+		`  F,0,0,500,1,0`,
+		`  MX,0,500,1000,0,100`,
+	)
+	if start, end, ok := s.Lifetime(); start != 1000 || end != 4000 || !ok {
+		t.Errorf("got lifetime %d - %d, %v", start, end, ok)
+	}
+	tests := []struct {
+		t          float64
+		opacity, x float64
+	}{
+		{500, 1, 0},
+		{1250, 0.5, 0},
+		{1750, 0, 50},
+		// the second iteration keeps the position the first ended with until it moves
+		{2250, 0.5, 100},
+		{3750, 0, 50},
+		{5000, 0, 100},
+	}
+	for _, tt := range tests {
+		st := s.StateAt(tt.t)
+		if !near(st.Opacity, tt.opacity) || !near(st.X, tt.x) {
+			t.Errorf("%v: got opacity %v and x %v, want %v and %v", tt.t, st.Opacity, st.X, tt.opacity, tt.x)
+		}
+	}
+
+	// a loop with a huge count is evaluated without playing every iteration
+	s = testSprite(t,
+		`Sprite,Foreground,Centre,"a.png",0,0`,
+		` L,0,2000000000`,
+		`  F,0,0,1000,0,1`,
+	)
+	if start, end, ok := s.Lifetime(); start != 0 || end != 2000000000000 || !ok {
+		t.Errorf("got lifetime %d - %d, %v", start, end, ok)
+	}
+	if st := s.StateAt(1e12 + 500); !near(st.Opacity, 0.5) || !st.Visible {
+		t.Errorf("got %+v", st)
+	}
+}
+
+func TestStateAtTrigger(t *testing.T) {
+	// triggers depend on gameplay, so a sprite with only triggers is never shown
+	s := testSprite(t,
+		`Sprite,Foreground,Centre,"a.png",0,0`,
+		` T,HitSoundClap`,
+		`  F,0,0,1000,1`,
+	)
+	if _, _, ok := s.Lifetime(); ok {
+		t.Error("got a lifetime from a trigger")
+	}
+	if st := s.StateAt(500); st.Visible || st.Opacity != 1 {
+		t.Errorf("got %+v", st)
+	}
+}
+
+func TestStateAtAnimation(t *testing.T) {
+	tests := []struct {
+		loop  string
+		t     float64
+		frame int
+	}{
+		{"LoopForever", 500, 0},
+		{"LoopForever", 1250, 2},
+		{"LoopForever", 1350, 0},
+		{"LoopOnce", 1350, 2},
+		{"LoopOnce", 4000, 2},
+	}
+	for _, tt := range tests {
+		s := testSprite(t, `Animation,Foreground,Centre,"a.png",0,0,3,100,`+tt.loop, ` F,0,1000,5000,1`)
+		if st := s.StateAt(tt.t); st.Frame != tt.frame {
+			t.Errorf("%s at %v: got frame %d, want %d", tt.loop, tt.t, st.Frame, tt.frame)
+		}
+	}
+}
+
+func TestStoryboardStateAt(t *testing.T) {
+	sb, err := ParseLines([]string{
+		`Sprite,Foreground,Centre,"front.png",0,0`,
+		`Sprite,Background,Centre,"back.png",0,0`,
+		`Sprite,Foreground,Centre,"front2.png",0,0`,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	states := sb.StateAt(0)
+	var got []string
+	for _, st := range states {
+		got = append(got, st.Sprite.Filepath)
+	}
+	// sprites are drawn by layer, then in the order they appear
+	if len(got) != 3 || got[0] != "back.png" || got[1] != "front.png" || got[2] != "front2.png" {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestEasing(t *testing.T) {
+	for e := EasingLinear; e <= EasingBounceInOut; e++ {
+		if got := e.Apply(0); !near(got, 0) {
+			t.Errorf("%d: got %v at 0", e, got)
+		}
+		if got := e.Apply(1); !near(got, 1) {
+			t.Errorf("%d: got %v at 1", e, got)
+		}
+	}
+	tests := []struct {
+		easing Easing
+		want   float64
+	}{
+		{EasingLinear, 0.5},
+		{EasingOut, 0.75},
+		{EasingIn, 0.25},
+		{EasingQuadInOut, 0.5},
+		{EasingCubicIn, 0.125},
+		{EasingCubicOut, 0.875},
+		{EasingSineIn, 1 - math.Sqrt2/2},
+		{EasingSineOut, math.Sqrt2 / 2},
+		{EasingExpoIn, 0.03125},
+		{EasingBounceOut, 0.765625},
+		{Easing(99), 0.5},
+	}
+	for _, tt := range tests {
+		if got := tt.easing.Apply(0.5); !near(got, tt.want) {
+			t.Errorf("%d: got %v at 0.5, want %v", tt.easing, got, tt.want)
+		}
+	}
+}
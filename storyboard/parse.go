@@ -0,0 +1,422 @@
+package storyboard
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pixelrazor/osu/beatmap"
+)
+
+// ParseError reports a malformed storyboard line
+type ParseError struct {
+	// Line is the 1-based line number
+	Line int
+	Text string
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("storyboard: line %d: %v: %q", e.Line, e.Err, e.Text)
+}
+
+// Unwrap returns the underlying error
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseFile parses the .osb or .osu file at path
+func ParseFile(path string) (*Storyboard, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.New("storyboard: " + err.Error())
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse parses the [Variables] and [Events] sections of a .osb or .osu file
+func Parse(r io.Reader) (*Storyboard, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.New("storyboard: " + err.Error())
+	}
+	vars := make(map[string]string)
+	var events []string
+	var lineNos []int
+	section := ""
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if i == 0 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			section = trimmed[1 : len(trimmed)-1]
+			continue
+		}
+		switch section {
+		case "Variables":
+			if eq := strings.IndexByte(line, '='); eq > 0 {
+				vars[strings.TrimSpace(line[:eq])] = strings.TrimSpace(line[eq+1:])
+			}
+		case "Events":
+			events = append(events, line)
+			lineNos = append(lineNos, i+1)
+		}
+	}
+	sb, err := parseEvents(events, vars)
+	if pe, ok := err.(*ParseError); ok {
+		pe.Line = lineNos[pe.Line-1]
+	}
+	return sb, err
+}
+
+// ParseLines parses storyboard event lines, substituting the given variables.
+// Line numbers in errors count from the first line given
+func ParseLines(lines []string, vars map[string]string) (*Storyboard, error) {
+	if vars == nil {
+		vars = make(map[string]string)
+	}
+	return parseEvents(lines, vars)
+}
+
+// FromBeatmap parses the storyboard in a beatmap's [Events] section
+func FromBeatmap(b *beatmap.Beatmap) (*Storyboard, error) {
+	return ParseLines(b.Events.Storyboard, nil)
+}
+
+// Merge adds the objects of other after those of sb, as osu! does with a beatmap's own storyboard
+// and the .osb file shared by its beatmapset
+func (sb *Storyboard) Merge(other *Storyboard) {
+	if sb.Variables == nil {
+		sb.Variables = make(map[string]string)
+	}
+	for name, value := range other.Variables {
+		if _, ok := sb.Variables[name]; !ok {
+			sb.Variables[name] = value
+		}
+	}
+	sb.Sprites = append(sb.Sprites, other.Sprites...)
+	sb.Samples = append(sb.Samples, other.Samples...)
+}
+
+func parseEvents(lines []string, vars map[string]string) (*Storyboard, error) {
+	sb := &Storyboard{Variables: vars}
+	// longer names are replaced first so that "$ab" isn't replaced as "$a" followed by "b"
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	var sprite *Sprite
+	var group *[]*Command
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "//") {
+			continue
+		}
+		fail := func(err error) error {
+			return &ParseError{Line: i + 1, Text: line, Err: err}
+		}
+		if strings.IndexByte(line, '$') >= 0 {
+			for _, name := range names {
+				line = strings.ReplaceAll(line, name, vars[name])
+			}
+		}
+		depth := 0
+		for depth < len(line) && (line[depth] == ' ' || line[depth] == '_') {
+			depth++
+		}
+		fields := splitEvent(strings.TrimRight(line[depth:], " \t\r"))
+		switch {
+		case depth == 0:
+			sprite, group = nil, nil
+			switch fields[0] {
+			case "Sprite", "4", "Animation", "6":
+				s, err := parseSprite(fields)
+				if err != nil {
+					return nil, fail(err)
+				}
+				sb.Sprites = append(sb.Sprites, s)
+				sprite = s
+			case "Sample", "5":
+				s, err := parseSample(fields)
+				if err != nil {
+					return nil, fail(err)
+				}
+				sb.Samples = append(sb.Samples, s)
+			}
+			// backgrounds, videos and breaks belong to the beatmap
+		case sprite == nil:
+			return nil, fail(errors.New("command outside of a sprite"))
+		case depth == 1 && fields[0] == "L":
+			l, err := parseLoop(fields)
+			if err != nil {
+				return nil, fail(err)
+			}
+			sprite.Loops = append(sprite.Loops, l)
+			group = &l.Commands
+		case depth == 1 && fields[0] == "T":
+			t, err := parseTrigger(fields)
+			if err != nil {
+				return nil, fail(err)
+			}
+			sprite.Triggers = append(sprite.Triggers, t)
+			group = &t.Commands
+		case depth == 1:
+			cmds, err := parseCommand(fields)
+			if err != nil {
+				return nil, fail(err)
+			}
+			sprite.Commands = append(sprite.Commands, cmds...)
+			group = nil
+		case group == nil:
+			return nil, fail(errors.New("nested command outside of a loop or trigger"))
+		default:
+			cmds, err := parseCommand(fields)
+			if err != nil {
+				return nil, fail(err)
+			}
+			*group = append(*group, cmds...)
+		}
+	}
+	return sb, nil
+}
+
+func parseSprite(fields []string) (*Sprite, error) {
+	animation := fields[0] == "Animation" || fields[0] == "6"
+	if len(fields) < 6 || animation && len(fields) < 8 {
+		return nil, errors.New("too few values")
+	}
+	s := &Sprite{Filepath: unquote(fields[3])}
+	var err error
+	if s.Layer, err = parseLayer(fields[1]); err != nil {
+		return nil, err
+	}
+	if s.Origin, err = parseOrigin(fields[2]); err != nil {
+		return nil, err
+	}
+	if s.X, err = parseFloat(fields[4]); err != nil {
+		return nil, err
+	}
+	if s.Y, err = parseFloat(fields[5]); err != nil {
+		return nil, err
+	}
+	if !animation {
+		return s, nil
+	}
+	s.Animation = &Animation{}
+	if s.Animation.FrameCount, err = parseInt(fields[6]); err != nil {
+		return nil, err
+	}
+	if s.Animation.FrameDelay, err = parseFloat(fields[7]); err != nil {
+		return nil, err
+	}
+	if len(fields) > 8 {
+		switch strings.TrimSpace(fields[8]) {
+		case "LoopOnce", "1":
+			s.Animation.LoopType = LoopOnce
+		}
+	}
+	return s, nil
+}
+
+func parseSample(fields []string) (*Sample, error) {
+	if len(fields) < 4 {
+		return nil, errors.New("too few values")
+	}
+	s := &Sample{Filepath: unquote(fields[3]), Volume: 100}
+	var err error
+	if s.Time, err = parseInt(fields[1]); err != nil {
+		return nil, err
+	}
+	if s.Layer, err = parseLayer(fields[2]); err != nil {
+		return nil, err
+	}
+	if len(fields) > 4 {
+		if s.Volume, err = parseInt(fields[4]); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func parseLayer(s string) (Layer, error) {
+	s = strings.TrimSpace(s)
+	for i, name := range layerNames {
+		if s == name {
+			return Layer(i), nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n >= len(layerNames) {
+		return 0, errors.New("unknown layer " + s)
+	}
+	return Layer(n), nil
+}
+
+func parseOrigin(s string) (Origin, error) {
+	s = strings.TrimSpace(s)
+	for i, name := range originNames {
+		if s == name {
+			return Origin(i), nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n >= len(originNames) {
+		return 0, errors.New("unknown origin " + s)
+	}
+	return Origin(n), nil
+}
+
+func parseLoop(fields []string) (*Loop, error) {
+	if len(fields) < 3 {
+		return nil, errors.New("loop needs a start time and count")
+	}
+	l := &Loop{}
+	var err error
+	if l.StartTime, err = parseInt(fields[1]); err != nil {
+		return nil, err
+	}
+	if l.Count, err = parseInt(fields[2]); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// parseTrigger parses a trigger. Without a start and end time the trigger can fire at any time
+func parseTrigger(fields []string) (*Trigger, error) {
+	if len(fields) < 2 {
+		return nil, errors.New("trigger needs a name")
+	}
+	t := &Trigger{Name: strings.TrimSpace(fields[1]), StartTime: math.MinInt32, EndTime: math.MaxInt32}
+	var err error
+	if len(fields) > 2 && strings.TrimSpace(fields[2]) != "" {
+		if t.StartTime, err = parseInt(fields[2]); err != nil {
+			return nil, err
+		}
+	}
+	if len(fields) > 3 && strings.TrimSpace(fields[3]) != "" {
+		if t.EndTime, err = parseInt(fields[3]); err != nil {
+			return nil, err
+		}
+	}
+	if len(fields) > 4 {
+		if t.GroupNumber, err = parseInt(fields[4]); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// parseCommand parses a command line, splitting commands with more than two sets of values.
+// An empty end time means the same as the start time and a single set of values means the value doesn't change
+func parseCommand(fields []string) ([]*Command, error) {
+	if len(fields) < 5 {
+		return nil, errors.New("command needs an easing, start time, end time and values")
+	}
+	c := Command{Type: CommandType(fields[0])}
+	var err error
+	if c.Easing, err = parseEasing(fields[1]); err != nil {
+		return nil, err
+	}
+	if c.StartTime, err = parseInt(fields[2]); err != nil {
+		return nil, err
+	}
+	c.EndTime = c.StartTime
+	if strings.TrimSpace(fields[3]) != "" {
+		if c.EndTime, err = parseInt(fields[3]); err != nil {
+			return nil, err
+		}
+	}
+	if c.Type == CommandParameter {
+		p := strings.TrimSpace(fields[4])
+		if p != "H" && p != "V" && p != "A" {
+			return nil, errors.New("unknown parameter " + p)
+		}
+		c.Parameter = Parameter(p[0])
+		return []*Command{&c}, nil
+	}
+	n := c.Type.values()
+	if n == 0 {
+		return nil, errors.New("unknown command " + fields[0])
+	}
+	params := fields[4:]
+	if len(params) < n {
+		return nil, fmt.Errorf("%s command needs %d values", c.Type, n)
+	}
+	values := make([][]float64, len(params)/n)
+	for i := range values {
+		values[i] = make([]float64, n)
+		for j := range values[i] {
+			if values[i][j], err = parseFloat(params[i*n+j]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(values) == 1 {
+		c.Start, c.End = values[0], values[0]
+		return []*Command{&c}, nil
+	}
+	duration := c.EndTime - c.StartTime
+	cmds := make([]*Command, len(values)-1)
+	for i := range cmds {
+		chained := c
+		chained.StartTime += i * duration
+		chained.EndTime += i * duration
+		chained.Start, chained.End = values[i], values[i+1]
+		cmds[i] = &chained
+	}
+	return cmds, nil
+}
+
+func parseEasing(s string) (Easing, error) {
+	n, err := parseInt(s)
+	return Easing(n), err
+}
+
+// splitEvent splits an event line on commas outside of quotes
+func splitEvent(line string) []string {
+	var fields []string
+	quoted := false
+	start := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				fields = append(fields, line[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(fields, line[start:])
+}
+
+func unquote(s string) string {
+	return strings.Trim(strings.TrimSpace(s), `"`)
+}
+
+// parseInt parses an integer, truncating values written with a fractional part
+func parseInt(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	n, err := strconv.Atoi(s)
+	if err == nil {
+		return n, nil
+	}
+	f, ferr := strconv.ParseFloat(s, 64)
+	if ferr != nil {
+		return 0, err
+	}
+	return int(f), nil
+}
+
+func parseFloat(s string) (float64, error) {
+	return strconv.ParseFloat(strings.TrimSpace(s), 64)
+}
@@ -0,0 +1,201 @@
+package storyboard
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pixelrazor/osu/beatmap"
+)
+
+func TestParseVariables(t *testing.T) {
+	osb := "[Variables]\n$a=1\n $ab = 2 \n$pos=320,240\n[Events]\nSprite,Foreground,Centre,\"sb\\$a.png\",$ab,$a\nSprite,Background,TopLeft,\"b.png\",$pos\n F,0,0,$ab000,$a\n"
+	sb, err := Parse(strings.NewReader(osb))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sb.Variables, map[string]string{"$a": "1", "$ab": "2", "$pos": "320,240"}) {
+		t.Errorf("got variables %v", sb.Variables)
+	}
+	// longer names are replaced first, and values can hold several fields
+	if s := sb.Sprites[0]; s.X != 2 || s.Y != 1 || s.Filepath != `sb\1.png` {
+		t.Errorf("got sprite %+v", s)
+	}
+	if s := sb.Sprites[1]; s.X != 320 || s.Y != 240 || s.Commands[0].EndTime != 2000 || s.Commands[0].Start[0] != 1 {
+		t.Errorf("got sprite %+v with command %+v", s, s.Commands[0])
+	}
+}
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		line string
+		want []*Command
+		err  bool
+	}{
+		{line: "F,0,1000,2000,0,1", want: []*Command{{Type: CommandFade, StartTime: 1000, EndTime: 2000, Start: []float64{0}, End: []float64{1}}}},
+		// an empty end time is the start time, and a single set of values doesn't change
+		{line: "F,0,1000,,0.5", want: []*Command{{Type: CommandFade, StartTime: 1000, EndTime: 1000, Start: []float64{0.5}, End: []float64{0.5}}}},
+		{line: "S,3,0,100.7,2", want: []*Command{{Type: CommandScale, Easing: EasingQuadIn, StartTime: 0, EndTime: 100, Start: []float64{2}, End: []float64{2}}}},
+		// more sets of values chain commands as long as the first
+		{line: "F,1,1000,2000,0,1,0", want: []*Command{
+			{Type: CommandFade, Easing: EasingOut, StartTime: 1000, EndTime: 2000, Start: []float64{0}, End: []float64{1}},
+			{Type: CommandFade, Easing: EasingOut, StartTime: 2000, EndTime: 3000, Start: []float64{1}, End: []float64{0}},
+		}},
+		{line: "M,0,0,100,0,0,10,10,20,20", want: []*Command{
+			{Type: CommandMove, StartTime: 0, EndTime: 100, Start: []float64{0, 0}, End: []float64{10, 10}},
+			{Type: CommandMove, StartTime: 100, EndTime: 200, Start: []float64{10, 10}, End: []float64{20, 20}},
+		}},
+		// values that don't fill a set are ignored
+		{line: "V,0,0,100,1,1,2", want: []*Command{{Type: CommandVector, StartTime: 0, EndTime: 100, Start: []float64{1, 1}, End: []float64{1, 1}}}},
+		{line: "C,0,0,100,255,0,0,0,0,255", want: []*Command{{Type: CommandColour, StartTime: 0, EndTime: 100, Start: []float64{255, 0, 0}, End: []float64{0, 0, 255}}}},
+		{line: "P,0,0,0,A", want: []*Command{{Type: CommandParameter, Parameter: ParameterAdditive}}},
+		{line: "P,0,100,200,H", want: []*Command{{Type: CommandParameter, StartTime: 100, EndTime: 200, Parameter: ParameterFlipH}}},
+		{line: "F,0,0,1", err: true},
+		{line: "X,0,0,1,1", err: true},
+		{line: "P,0,0,1,Z", err: true},
+		{line: "M,0,0,1,5", err: true},
+		{line: "F,x,0,1,1", err: true},
+		{line: "F,0,x,1,1", err: true},
+		{line: "F,0,0,x,1", err: true},
+		{line: "F,0,0,1,1,x", err: true},
+	}
+	for _, tt := range tests {
+		got, err := parseCommand(splitEvent(tt.line))
+		switch {
+		case tt.err && err == nil:
+			t.Errorf("%q: got %+v, want an error", tt.line, got)
+		case !tt.err && err != nil:
+			t.Errorf("%q: %v", tt.line, err)
+		case !tt.err && !reflect.DeepEqual(got, tt.want):
+			t.Errorf("%q: got %+v, want %+v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestParseObjects(t *testing.T) {
+	lines := []string{
+		`Sprite,Foreground,Centre,"sb\star.png",320,240`,
+		` F,0,0,1000,0,1`,
+		` L,3000,2`,
+		`  MX,0,0,500,0,100`,
+		`__F,0,0,500,1`,
+		` T,HitSoundClap,0,10000,1`,
+		`  F,0,0,100,1,0`,
+		` T,Failing`,
+		`  C,0,0,,255,0,0`,
+		` S,0,0,,2`,
+		`Animation,4,7,"a.png",0,0,3,100,LoopOnce`,
+		`6,Background,TopLeft,"b.png",1,2,4,50.5`,
+		`Sample,500,0,"s.wav",70`,
+		`5,0,Pass,"t.wav"`,
+		// backgrounds, videos and breaks belong to the beatmap
+		`0,0,"bg.jpg",0,0`,
+		`Video,0,"v.mp4"`,
+		`2,1000,2000`,
+	}
+	sb, err := ParseLines(lines, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sb.Sprites) != 3 || len(sb.Samples) != 2 {
+		t.Fatalf("got %d sprites and %d samples", len(sb.Sprites), len(sb.Samples))
+	}
+	s := sb.Sprites[0]
+	if s.Layer != LayerForeground || s.Origin != OriginCentre || s.Animation != nil {
+		t.Errorf("got sprite %+v", s)
+	}
+	// commands after a loop or trigger belong to the sprite again
+	if len(s.Commands) != 2 || s.Commands[1].Type != CommandScale {
+		t.Errorf("got commands %+v", s.Commands)
+	}
+	if len(s.Loops) != 1 || s.Loops[0].StartTime != 3000 || s.Loops[0].Count != 2 || len(s.Loops[0].Commands) != 2 {
+		t.Errorf("got loops %+v", s.Loops)
+	}
+	if len(s.Triggers) != 2 {
+		t.Fatalf("got triggers %+v", s.Triggers)
+	}
+	if tr := s.Triggers[0]; tr.Name != "HitSoundClap" || tr.StartTime != 0 || tr.EndTime != 10000 || tr.GroupNumber != 1 || len(tr.Commands) != 1 {
+		t.Errorf("got trigger %+v", tr)
+	}
+	// a trigger without times can fire at any time
+	if tr := s.Triggers[1]; tr.Name != "Failing" || tr.StartTime != math.MinInt32 || tr.EndTime != math.MaxInt32 || len(tr.Commands) != 1 {
+		t.Errorf("got trigger %+v", tr)
+	}
+	a := sb.Sprites[1]
+	if a.Layer != LayerOverlay || a.Origin != OriginCentreRight || *a.Animation != (Animation{FrameCount: 3, FrameDelay: 100, LoopType: LoopOnce}) {
+		t.Errorf("got animation %+v, %+v", a, a.Animation)
+	}
+	if a := sb.Sprites[2]; a.Layer != LayerBackground || a.X != 1 || a.Y != 2 || *a.Animation != (Animation{FrameCount: 4, FrameDelay: 50.5}) {
+		t.Errorf("got animation %+v, %+v", a, a.Animation)
+	}
+	if *sb.Samples[0] != (Sample{500, LayerBackground, "s.wav", 70}) || *sb.Samples[1] != (Sample{0, LayerPass, "t.wav", 100}) {
+		t.Errorf("got samples %+v, %+v", sb.Samples[0], sb.Samples[1])
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		line int
+	}{
+		{"command", "osu file format v14\n\n[Variables]\n$x=1\n\n[Events]\n//comment\nSprite,Foreground,Centre,\"a.png\",$x,0\n F,0,x,1,1\n", 9},
+		{"CRLF", "\ufeff[Events]\r\nSprite,Foreground,Centre,\"a.png\",0,0\r\n\r\n M,0,0,1,5\r\n", 4},
+		{"sprite", "[Events]\nSprite,Foreground,Middle,\"a.png\",0,0\n", 2},
+		{"animation", "[Events]\nAnimation,Foreground,Centre,\"a.png\",0,0,3\n", 2},
+		{"sample", "[Events]\nSample,x,0,\"a.wav\"\n", 2},
+		{"loop", "[Events]\nSprite,Foreground,Centre,\"a.png\",0,0\n L,0\n", 3},
+		{"trigger", "[Events]\nSprite,Foreground,Centre,\"a.png\",0,0\n T,Failing,x\n", 3},
+		{"outside of a sprite", "[Events]\n F,0,0,1,1\n", 2},
+		{"nested outside of a loop", "[Events]\nSprite,Foreground,Centre,\"a.png\",0,0\n F,0,0,1,1\n  F,0,0,1,1\n", 4},
+		{"nested after a sample", "[Events]\nSprite,Foreground,Centre,\"a.png\",0,0\nSample,0,0,\"a.wav\"\n F,0,0,1,1\n", 4},
+	}
+	for _, tt := range tests {
+		_, err := Parse(strings.NewReader(tt.file))
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("%s: got %v, want a ParseError", tt.name, err)
+			continue
+		}
+		if pe.Line != tt.line {
+			t.Errorf("%s: got line %d, want %d", tt.name, pe.Line, tt.line)
+		}
+	}
+	// ParseLines counts from the first line given
+	_, err := ParseLines([]string{"Sprite,Foreground,Centre,\"a.png\",0,0", " F,0,x,1,1"}, nil)
+	if pe, ok := err.(*ParseError); !ok || pe.Line != 2 {
+		t.Errorf("ParseLines: got %v", err)
+	}
+}
+
+func TestFromBeatmap(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "beatmap", "testdata", "v14.osu"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := beatmap.Parse(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sb, err := FromBeatmap(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sb.Sprites) != 1 || sb.Sprites[0].Filepath != `sb\bg.png` || len(sb.Sprites[0].Commands) != 2 {
+		t.Fatalf("got %+v", sb.Sprites)
+	}
+
+	other, err := ParseLines([]string{`Sprite,Overlay,Centre,"o.png",0,0`, `Sample,0,0,"s.wav"`}, map[string]string{"$a": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sb.Merge(other)
+	if len(sb.Sprites) != 2 || sb.Sprites[1].Layer != LayerOverlay || len(sb.Samples) != 1 || sb.Variables["$a"] != "1" {
+		t.Errorf("got merged %+v", sb)
+	}
+}
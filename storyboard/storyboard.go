@@ -0,0 +1,214 @@
+// Package storyboard reads osu! storyboards, from .osb files and the [Events] section of .osu files,
+// and evaluates the state of their sprites over time
+package storyboard
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Storyboard holds the objects of a storyboard
+type Storyboard struct {
+	// Variables holds the values of the [Variables] section by name, including the leading '$'
+	Variables map[string]string
+	// Sprites holds the sprites and animations in the order they are drawn within their layer
+	Sprites []*Sprite
+	Samples []*Sample
+}
+
+// Layer is the layer a storyboard object is drawn on
+type Layer int
+
+// All layers, from back to front. LayerFail and LayerPass are only shown when the player is failing or passing
+const (
+	LayerBackground Layer = iota
+	LayerFail
+	LayerPass
+	LayerForeground
+	LayerOverlay
+)
+
+var layerNames = []string{"Background", "Fail", "Pass", "Foreground", "Overlay"}
+
+// String returns the name of the layer as storyboards write it
+func (l Layer) String() string {
+	if l >= 0 && int(l) < len(layerNames) {
+		return layerNames[l]
+	}
+	return strconv.Itoa(int(l))
+}
+
+// Origin is the point of a sprite's image that is placed at its position
+type Origin int
+
+// All origins. OriginCustom behaves like OriginTopLeft
+const (
+	OriginTopLeft Origin = iota
+	OriginCentre
+	OriginCentreLeft
+	OriginTopRight
+	OriginBottomCentre
+	OriginTopCentre
+	OriginCustom
+	OriginCentreRight
+	OriginBottomLeft
+	OriginBottomRight
+)
+
+var originNames = []string{"TopLeft", "Centre", "CentreLeft", "TopRight", "BottomCentre", "TopCentre", "Custom", "CentreRight", "BottomLeft", "BottomRight"}
+
+// String returns the name of the origin as storyboards write it
+func (o Origin) String() string {
+	if o >= 0 && int(o) < len(originNames) {
+		return originNames[o]
+	}
+	return strconv.Itoa(int(o))
+}
+
+// Anchor returns the position of the origin within an image, from (0, 0) at the top left to (1, 1) at the bottom right
+func (o Origin) Anchor() (x, y float64) {
+	switch o {
+	case OriginCentre:
+		return 0.5, 0.5
+	case OriginCentreLeft:
+		return 0, 0.5
+	case OriginTopRight:
+		return 1, 0
+	case OriginBottomCentre:
+		return 0.5, 1
+	case OriginTopCentre:
+		return 0.5, 0
+	case OriginCentreRight:
+		return 1, 0.5
+	case OriginBottomLeft:
+		return 0, 1
+	case OriginBottomRight:
+		return 1, 1
+	}
+	return 0, 0
+}
+
+// LoopType is how an animation repeats
+type LoopType int
+
+// All loop types
+const (
+	LoopForever LoopType = iota
+	LoopOnce
+)
+
+// Sprite is a storyboard image, or an animation when Animation is set
+type Sprite struct {
+	Layer    Layer
+	Origin   Origin
+	Filepath string
+	// X and Y are the initial position in storyboard pixels, 640x480 with (0, 0) at the top left
+	X, Y      float64
+	Animation *Animation
+	Commands  []*Command
+	Loops     []*Loop
+	Triggers  []*Trigger
+}
+
+// Animation holds the settings of an animated sprite
+type Animation struct {
+	FrameCount int
+	// FrameDelay is the time each frame is shown in milliseconds
+	FrameDelay float64
+	LoopType   LoopType
+}
+
+// FramePath returns the file of an animation frame, which osu! finds by adding the frame number before the extension
+func (s *Sprite) FramePath(frame int) string {
+	if s.Animation == nil {
+		return s.Filepath
+	}
+	dot := strings.LastIndexByte(s.Filepath, '.')
+	if dot < 0 {
+		return s.Filepath + strconv.Itoa(frame)
+	}
+	return s.Filepath[:dot] + strconv.Itoa(frame) + s.Filepath[dot:]
+}
+
+// Sample is a sound played by the storyboard
+type Sample struct {
+	// Time is in milliseconds
+	Time     int
+	Layer    Layer
+	Filepath string
+	// Volume is from 0 - 100
+	Volume int
+}
+
+// CommandType is the property a command changes
+type CommandType string
+
+// All command types
+const (
+	CommandFade      CommandType = "F"
+	CommandMove      CommandType = "M"
+	CommandMoveX     CommandType = "MX"
+	CommandMoveY     CommandType = "MY"
+	CommandScale     CommandType = "S"
+	CommandVector    CommandType = "V"
+	CommandRotate    CommandType = "R"
+	CommandColour    CommandType = "C"
+	CommandParameter CommandType = "P"
+)
+
+// values returns how many values each of the command's start and end has
+func (c CommandType) values() int {
+	switch c {
+	case CommandMove, CommandVector:
+		return 2
+	case CommandColour:
+		return 3
+	case CommandFade, CommandMoveX, CommandMoveY, CommandScale, CommandRotate:
+		return 1
+	}
+	return 0
+}
+
+// Parameter is the effect of a parameter command
+type Parameter byte
+
+// All parameters
+const (
+	ParameterFlipH    Parameter = 'H'
+	ParameterFlipV    Parameter = 'V'
+	ParameterAdditive Parameter = 'A'
+)
+
+// Command changes a property of a sprite over time. Commands written with more than two sets of values
+// are split into one command per pair of sets, each as long as the first
+type Command struct {
+	Type   CommandType
+	Easing Easing
+	// StartTime and EndTime are in milliseconds, relative to the loop start for commands of loops
+	StartTime int
+	EndTime   int
+	// Start and End hold the values at StartTime and EndTime: the opacity, position, scale, rotation in radians or RGB colour
+	Start []float64
+	End   []float64
+	// Parameter is set for parameter commands, which apply between StartTime and EndTime, or forever when they are equal
+	Parameter Parameter
+}
+
+// Loop repeats its commands, whose times are relative to StartTime
+type Loop struct {
+	StartTime int
+	// Count is the number of times the commands are played
+	Count    int
+	Commands []*Command
+}
+
+// Trigger plays its commands when a gameplay event happens, such as a hitsound being played or the player failing
+type Trigger struct {
+	// Name is the trigger condition, e.g. "HitSoundClap", "Failing" or "Passing"
+	Name      string
+	StartTime int
+	EndTime   int
+	// GroupNumber stops triggers of the same group from running at once
+	GroupNumber int
+	Commands    []*Command
+}
@@ -0,0 +1,171 @@
+package osu
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// CommentableType is the kind of resource a comment thread belongs to
+type CommentableType string
+
+// All kinds of resources that can have comment threads
+const (
+	CommentableBeatmapset CommentableType = "beatmapset"
+	CommentableNewsPost   CommentableType = "news_post"
+	CommentableBuild      CommentableType = "build"
+)
+
+// CommentSort is the order a comment listing is sorted in
+type CommentSort string
+
+// All orders a comment listing can be sorted in
+const (
+	CommentSortNew CommentSort = "new"
+	CommentSortOld CommentSort = "old"
+	CommentSortTop CommentSort = "top"
+)
+
+// TopicSort is the order the posts of a forum topic are listed in
+type TopicSort string
+
+// All orders the posts of a forum topic can be listed in
+const (
+	TopicSortOldest TopicSort = "id_asc"
+	TopicSortNewest TopicSort = "id_desc"
+)
+
+// UserCompact holds the basic information about a user that is included in v2 responses
+type UserCompact struct {
+	ID            UserID     `json:"id"`
+	Username      string     `json:"username"`
+	AvatarURL     string     `json:"avatar_url"`
+	CountryCode   string     `json:"country_code"`
+	DefaultGroup  string     `json:"default_group"`
+	IsActive      bool       `json:"is_active"`
+	IsBot         bool       `json:"is_bot"`
+	IsDeleted     bool       `json:"is_deleted"`
+	IsOnline      bool       `json:"is_online"`
+	IsSupporter   bool       `json:"is_supporter"`
+	LastVisit     *time.Time `json:"last_visit"`
+	PmFriendsOnly bool       `json:"pm_friends_only"`
+	ProfileColour string     `json:"profile_colour"`
+}
+
+// Comment holds a single comment on a beatmapset, news post or changelog build
+type Comment struct {
+	ID              int64           `json:"id"`
+	CommentableID   int64           `json:"commentable_id"`
+	CommentableType CommentableType `json:"commentable_type"`
+	CreatedAt       time.Time       `json:"created_at"`
+	DeletedAt       *time.Time      `json:"deleted_at"`
+	EditedAt        *time.Time      `json:"edited_at"`
+	EditedByID      *int64          `json:"edited_by_id"`
+	LegacyName      *string         `json:"legacy_name"`
+	Message         string          `json:"message"`
+	MessageHTML     string          `json:"message_html"`
+	ParentID        *int64          `json:"parent_id"`
+	Pinned          bool            `json:"pinned"`
+	RepliesCount    int64           `json:"replies_count"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	UserID          UserID          `json:"user_id"`
+	VotesCount      int64           `json:"votes_count"`
+}
+
+// CommentableMeta holds information about the resource a comment thread belongs to
+type CommentableMeta struct {
+	ID    int64           `json:"id"`
+	Title string          `json:"title"`
+	Type  CommentableType `json:"type"`
+	URL   string          `json:"url"`
+}
+
+// CommentCursor marks a position in a comment listing. Pass it to CommentsAfter to fetch the next page
+type CommentCursor map[string]json.RawMessage
+
+// CommentBundle holds a page of comments along with the users and parent comments they reference
+type CommentBundle struct {
+	Comments         []*Comment         `json:"comments"`
+	CommentableMeta  []*CommentableMeta `json:"commentable_meta"`
+	Cursor           CommentCursor      `json:"cursor"`
+	HasMore          bool               `json:"has_more"`
+	HasMoreID        *int64             `json:"has_more_id"`
+	IncludedComments []*Comment         `json:"included_comments"`
+	PinnedComments   []*Comment         `json:"pinned_comments"`
+	Sort             CommentSort        `json:"sort"`
+	TopLevelCount    *int64             `json:"top_level_count"`
+	Total            *int64             `json:"total"`
+	UserFollow       bool               `json:"user_follow"`
+	UserVotes        []int64            `json:"user_votes"`
+	Users            []*UserCompact     `json:"users"`
+}
+
+// ForumPostBody holds the content of a forum post in both of its representations
+type ForumPostBody struct {
+	HTML string `json:"html"`
+	Raw  string `json:"raw"`
+}
+
+// ForumPost holds a single post in a forum topic
+type ForumPost struct {
+	ID         int64         `json:"id"`
+	ForumID    int64         `json:"forum_id"`
+	TopicID    int64         `json:"topic_id"`
+	UserID     UserID        `json:"user_id"`
+	CreatedAt  time.Time     `json:"created_at"`
+	DeletedAt  *time.Time    `json:"deleted_at"`
+	EditedAt   *time.Time    `json:"edited_at"`
+	EditedByID *int64        `json:"edited_by_id"`
+	Body       ForumPostBody `json:"body"`
+}
+
+// ForumTopic holds the information about a forum topic
+type ForumTopic struct {
+	ID          int64      `json:"id"`
+	ForumID     int64      `json:"forum_id"`
+	UserID      UserID     `json:"user_id"`
+	Title       string     `json:"title"`
+	Type        string     `json:"type"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   *time.Time `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at"`
+	FirstPostID int64      `json:"first_post_id"`
+	LastPostID  int64      `json:"last_post_id"`
+	PostCount   int64      `json:"post_count"`
+	IsLocked    bool       `json:"is_locked"`
+}
+
+// ForumTopicPosts holds a forum topic along with a page of its posts
+type ForumTopicPosts struct {
+	Topic  *ForumTopic  `json:"topic"`
+	Posts  []*ForumPost `json:"posts"`
+	Cursor *string      `json:"cursor_string"`
+}
+
+// NewForumTopic holds a newly created forum topic and its opening post
+type NewForumTopic struct {
+	Topic *ForumTopic `json:"topic"`
+	Post  *ForumPost  `json:"post"`
+}
+
+// CommentsOption is used to add optional queries to ClientV2.Comments
+type CommentsOption func(string) string
+
+// ForumTopicOption is used to add optional queries to ClientV2.ForumTopic
+type ForumTopicOption func(string) string
+
+// ClientV2 executes requests to the v2 endpoints using an OAuth access token
+type ClientV2 struct {
+	token string
+	c     http.Client
+}
+
+// NewClientV2 creates a ClientV2 with the given OAuth access token.
+// Creating topics, replying and editing posts require a token with the forum.write scope
+func NewClientV2(token string) *ClientV2 {
+	client := new(ClientV2)
+	client.token = token
+	return client
+}
+
+const apiV2URL = "https://osu.ppy.sh/api/v2/"